@@ -0,0 +1,11 @@
+// Package api holds the service's OpenAPI description, embedded so that
+// the server can publish it.
+package api
+
+import _ "embed"
+
+// Spec is api/openapi.yaml as written. Its servers still point at the
+// local development server.
+//
+//go:embed openapi.yaml
+var Spec []byte
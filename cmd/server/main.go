@@ -1,14 +1,41 @@
 package main
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"log/slog"
+	"net"
+	nethttp "net/http"
+	"os"
+	"task-tracking-service/api"
+	grpcadapter "task-tracking-service/internal/adapters/grpc"
 	"task-tracking-service/internal/adapters/http"
+	"task-tracking-service/internal/adapters/metrics"
 	"task-tracking-service/internal/adapters/storage/factory"
+	"task-tracking-service/internal/auth"
 	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
 	"task-tracking-service/internal/core/services"
+	"task-tracking-service/internal/health"
+	"task-tracking-service/internal/logging"
+	"task-tracking-service/internal/telemetry"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 	// You'll need to import your repository implementation once it's created
 )
 
+const (
+	// readinessTimeout bounds each dependency check made by /readyz
+	readinessTimeout = 2 * time.Second
+	// taskStatsTimeout bounds the task counts made on each metrics scrape
+	taskStatsTimeout = 2 * time.Second
+)
+
 func main() {
 	// Load configuration
 	cfg, err := config.Load()
@@ -16,28 +43,354 @@ func main() {
 		log.Fatalf("Failed to load configuration: %v", err)
 	}
 
+	// Everything logs through one structured logger from here on,
+	// including code still using the standard log package
+	logger, err := logging.New(cfg.Logging, os.Stdout)
+	if err != nil {
+		log.Fatalf("Failed to create logger: %v", err)
+	}
+	slog.SetDefault(logger)
+
+	// Spans from every layer go to the configured exporter
+	shutdownTracing, err := telemetry.SetupTracing(context.Background(), cfg.Tracing)
+	if err != nil {
+		fatal(logger, "Failed to set up tracing", err)
+	}
+	defer shutdownTracing(context.Background())
+
 	// Create repository factory
-	repoFactory := factory.NewRepositoryFactory(cfg)
+	repoFactory := factory.NewRepositoryFactory(cfg, logger)
 	defer repoFactory.Close()
 
 	// Create task repository using the factory
 	taskRepo, err := repoFactory.CreateTaskRepository()
 	if err != nil {
-		log.Fatalf("Failed to create repository: %v", err)
+		fatal(logger, "Failed to create repository", err)
+	}
+
+	// Metrics are collected into a registry of our own so that only what
+	// is registered below is exposed
+	var metricsRegistry *prometheus.Registry
+	if cfg.Features.EnableMetrics {
+		metricsRegistry = prometheus.NewRegistry()
+		metricsRegistry.MustRegister(
+			collectors.NewGoCollector(),
+			collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		)
+		if stats, ok := taskRepo.(ports.TaskStatistics); ok {
+			metricsRegistry.MustRegister(metrics.NewTaskStatsCollector(stats, taskStatsTimeout))
+		}
+		taskRepo, err = metrics.NewTaskRepository(taskRepo, metricsRegistry)
+		if err != nil {
+			fatal(logger, "Failed to instrument repository", err)
+		}
 	}
 
 	// Initialize service with the repository from factory
-	taskService := services.NewTaskService(taskRepo)
+	taskService := services.NewTaskService(taskRepo, logger)
+
+	// Deleted tasks can be restored until they have been in the trash for
+	// the retention period
+	if err := startTrashPurger(taskRepo, cfg.Trash, logger); err != nil {
+		fatal(logger, "Failed to start trash purger", err)
+	}
+
+	// Completed tasks move to the archive once they have been left alone
+	if err := startArchiver(taskRepo, cfg.Archive, logger); err != nil {
+		fatal(logger, "Failed to start archiver", err)
+	}
+
+	// Enforce role-based access control in front of the service
+	bindings, err := cfg.RBAC.RoleBindings()
+	if err != nil {
+		fatal(logger, "Failed to load role bindings", err)
+	}
+	policy := services.NewPolicy(bindings, domain.Role(cfg.RBAC.DefaultRole))
+	authorizedTaskService := services.NewAuthorizedTaskService(taskService, policy, logger)
+	tracedTaskService := services.NewTracedTaskService(authorizedTaskService)
+
+	// Batches run through the same services as single requests so each
+	// operation is traced and authorized like one. Atomic batches need a
+	// transactional repository.
+	transactor, _ := taskRepo.(ports.Transactor)
+	batchService := services.NewBatchService(tracedTaskService, transactor, cfg.Batch.MaxSize)
 
 	// Initialize handlers
-	taskHandler := http.NewTaskHandler(taskService)
+	taskHandler := http.NewTaskHandler(tracedTaskService, logger)
+	authorizationHandler := http.NewAuthorizationHandler(policy)
+	batchHandler := http.NewBatchHandler(batchService, logger)
+	graphQLHandler, err := http.NewGraphQLHandler(tracedTaskService, http.GraphQLLimits{
+		MaxDepth:      cfg.GraphQL.MaxDepth,
+		MaxComplexity: cfg.GraphQL.MaxComplexity,
+	}, logger)
+	if err != nil {
+		fatal(logger, "Failed to build GraphQL schema", err)
+	}
+
+	// Every API route requires an API key or, when configured, a JWT
+	apiKeys, err := cfg.API.Keys()
+	if err != nil {
+		fatal(logger, "Failed to load API keys", err)
+	}
+	authenticator, err := newAuthenticator(cfg, apiKeys)
+	if err != nil {
+		fatal(logger, "Failed to configure authentication", err)
+	}
+
+	// Client IPs come from X-Forwarded-For only behind trusted proxies
+	trustedProxies, err := cfg.Server.TrustedProxyRanges()
+	if err != nil {
+		fatal(logger, "Failed to load trusted proxies", err)
+	}
+
+	routerOptions := []http.RouterOption{
+		http.WithAuthenticator(authenticator),
+		http.WithTrustedProxies(trustedProxies),
+		http.WithAuthorizationHandler(authorizationHandler),
+		http.WithBatchHandler(batchHandler),
+		http.WithGraphQLHandler(graphQLHandler),
+		http.WithTracing(cfg.Tracing.ServiceName),
+		http.WithLogger(logger),
+	}
+	// The gRPC API shares the authentication, limits, metrics and tracing
+	// of the REST API
+	grpcOptions := []grpcadapter.ServerOption{
+		grpcadapter.WithAuthenticator(authenticator),
+		grpcadapter.WithTracing(),
+		grpcadapter.WithLogger(logger),
+	}
+
+	if cfg.RateLimit.Enabled {
+		rateLimitStore, err := repoFactory.CreateRateLimitStore()
+		if err != nil {
+			fatal(logger, "Failed to create rate limit store", err)
+		}
+		rateLimitPolicy, err := newRateLimitPolicy(cfg.RateLimit)
+		if err != nil {
+			fatal(logger, "Failed to load rate limits", err)
+		}
+		routerOptions = append(routerOptions, http.WithRateLimit(rateLimitStore, rateLimitPolicy))
+		grpcOptions = append(grpcOptions, grpcadapter.WithRateLimit(rateLimitStore, rateLimitPolicy))
+	}
+
+	// Let clients retry task creation without creating duplicates
+	idempotencyStore, err := repoFactory.CreateIdempotencyStore()
+	if err != nil {
+		fatal(logger, "Failed to create idempotency store", err)
+	}
+	idempotencyTTL, err := time.ParseDuration(cfg.Idempotency.TTL)
+	if err != nil {
+		fatal(logger, "Invalid idempotency TTL", err)
+	}
+	routerOptions = append(routerOptions, http.WithIdempotency(idempotencyStore, idempotencyTTL))
+
+	// Readiness depends on the storage opened above
+	healthRegistry := health.NewRegistry(readinessTimeout)
+	if err := repoFactory.RegisterHealthChecks(healthRegistry); err != nil {
+		fatal(logger, "Failed to register health checks", err)
+	}
+	routerOptions = append(routerOptions, http.WithHealthRegistry(healthRegistry))
+
+	if metricsRegistry != nil {
+		if err := repoFactory.RegisterMetrics(metricsRegistry); err != nil {
+			fatal(logger, "Failed to register storage metrics", err)
+		}
+		requestMetrics, err := http.NewRequestMetrics(metricsRegistry)
+		if err != nil {
+			fatal(logger, "Failed to register request metrics", err)
+		}
+		routerOptions = append(routerOptions, http.WithRequestMetrics(requestMetrics))
+		grpcMetrics, err := grpcadapter.NewRequestMetrics(metricsRegistry)
+		if err != nil {
+			fatal(logger, "Failed to register gRPC metrics", err)
+		}
+		grpcOptions = append(grpcOptions, grpcadapter.WithRequestMetrics(grpcMetrics))
+
+		// Metrics share the API port unless they have an admin port of
+		// their own, which is not exposed with the API and needs no key
+		metricsHandler := promhttp.HandlerFor(metricsRegistry, promhttp.HandlerOpts{})
+		if cfg.Server.AdminPort != "" {
+			go serveAdmin(cfg.Server.Host+":"+cfg.Server.AdminPort, metricsHandler, logger)
+		} else {
+			routerOptions = append(routerOptions, http.WithMetricsHandler(metricsHandler))
+		}
+	}
+
+	// Publish the API description for this deployment
+	if cfg.Features.EnableSwagger {
+		docsHandler, err := http.NewDocsHandler(api.Spec, cfg.Server.BaseURL)
+		if err != nil {
+			fatal(logger, "Failed to load OpenAPI spec", err)
+		}
+		routerOptions = append(routerOptions, http.WithDocsHandler(docsHandler))
+	}
+
+	// Catch drift between the handlers and the published contract before it
+	// reaches staging
+	if cfg.Environment == "development" {
+		contractValidator, err := http.NewContractValidator(api.Spec, logger)
+		if err != nil {
+			fatal(logger, "Failed to load OpenAPI spec", err)
+		}
+		routerOptions = append(routerOptions, http.WithContractValidation(contractValidator))
+	}
+
+	if err := startGRPCServer(cfg, tracedTaskService, taskService, authorizedTaskService.ScopeFilter, grpcOptions, logger); err != nil {
+		fatal(logger, "Failed to start gRPC server", err)
+	}
 
 	// Setup router
-	router := http.NewRouter(taskHandler)
+	router := http.NewRouter(taskHandler, routerOptions...)
 
-	// Start server
-	log.Printf("Starting server on %s:%s", cfg.Server.Host, cfg.Server.Port)
-	if err := router.Start(cfg.Server.Host + ":" + cfg.Server.Port); err != nil {
-		log.Fatal("Failed to start server:", err)
+	// Start server. Echo's own startup messages would bypass the logger.
+	router.HideBanner = true
+	router.HidePort = true
+	addr := cfg.Server.Host + ":" + cfg.Server.Port
+	logger.Info("starting server", "addr", addr)
+	if err := router.Start(addr); err != nil {
+		fatal(logger, "Failed to start server", err)
 	}
 }
+
+// fatal logs err and exits. As with log.Fatal, deferred calls do not run.
+func fatal(logger *slog.Logger, msg string, err error) {
+	logger.Error(msg, "error", err)
+	os.Exit(1)
+}
+
+// serveAdmin serves the metrics handler at /metrics on addr
+func serveAdmin(addr string, metricsHandler nethttp.Handler, logger *slog.Logger) {
+	mux := nethttp.NewServeMux()
+	mux.Handle("/metrics", metricsHandler)
+
+	logger.Info("starting admin server", "addr", addr)
+	if err := nethttp.ListenAndServe(addr, mux); err != nil {
+		fatal(logger, "Failed to start admin server", err)
+	}
+}
+
+// startGRPCServer serves the gRPC API in the background. An empty port
+// disables it. Watches poll watched, narrowed to each caller by scope,
+// rather than taskService so that each poll does not record a trace of its
+// own.
+func startGRPCServer(cfg *config.Config, taskService, watched ports.TaskService, scope services.TaskScope, opts []grpcadapter.ServerOption, logger *slog.Logger) error {
+	if cfg.GRPC.Port == "" {
+		return nil
+	}
+	watchInterval, err := time.ParseDuration(cfg.GRPC.WatchInterval)
+	if err != nil {
+		return fmt.Errorf("invalid gRPC watch interval: %w", err)
+	}
+	if watchInterval <= 0 {
+		return fmt.Errorf("gRPC watch interval must be positive")
+	}
+
+	addr := cfg.Server.Host + ":" + cfg.GRPC.Port
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return err
+	}
+
+	server := grpcadapter.NewServer(taskService, services.NewTaskWatcher(watched, scope, watchInterval), opts...)
+	logger.Info("starting gRPC server", "addr", addr)
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			fatal(logger, "gRPC server stopped", err)
+		}
+	}()
+	return nil
+}
+
+// newAuthenticator accepts identity provider tokens when a JWKS source is
+// configured, falling back to the static API keys.
+func newAuthenticator(cfg *config.Config, apiKeys []config.APIKey) (auth.Authenticator, error) {
+	apiKeyAuthenticator := auth.NewAPIKeyAuthenticator(apiKeys)
+	if !cfg.Auth.JWTEnabled() {
+		return apiKeyAuthenticator, nil
+	}
+
+	refreshInterval, err := time.ParseDuration(cfg.Auth.JWKSRefreshInterval)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JWKS refresh interval: %w", err)
+	}
+
+	var keys *auth.JWKS
+	if cfg.Auth.JWKSURL != "" {
+		keys = auth.NewRemoteJWKS(cfg.Auth.JWKSURL, refreshInterval, nil)
+	} else {
+		keys = auth.NewFileJWKS(cfg.Auth.JWKSFile, refreshInterval)
+	}
+
+	return auth.NewChain(
+		auth.NewJWTAuthenticator(keys, cfg.Auth.Issuer, cfg.Auth.Audience),
+		apiKeyAuthenticator,
+	), nil
+}
+
+// startTrashPurger purges expired tasks from the trash in the background.
+// A retention of 0 disables it.
+func startTrashPurger(repo ports.TaskRepository, cfg config.TrashConfig, logger *slog.Logger) error {
+	retention, err := time.ParseDuration(cfg.Retention)
+	if err != nil {
+		return fmt.Errorf("invalid trash retention: %w", err)
+	}
+	interval, err := time.ParseDuration(cfg.PurgeInterval)
+	if err != nil {
+		return fmt.Errorf("invalid trash purge interval: %w", err)
+	}
+	if retention <= 0 {
+		return nil
+	}
+	if interval <= 0 {
+		return fmt.Errorf("trash purge interval must be positive")
+	}
+
+	go services.NewTrashPurger(repo, retention, logger).Run(context.Background(), interval)
+	return nil
+}
+
+// startArchiver archives completed tasks in the background. An After of 0
+// disables it.
+func startArchiver(repo ports.TaskRepository, cfg config.ArchiveConfig, logger *slog.Logger) error {
+	after, err := time.ParseDuration(cfg.After)
+	if err != nil {
+		return fmt.Errorf("invalid archive age: %w", err)
+	}
+	interval, err := time.ParseDuration(cfg.Interval)
+	if err != nil {
+		return fmt.Errorf("invalid archive interval: %w", err)
+	}
+	if after <= 0 {
+		return nil
+	}
+	if interval <= 0 {
+		return fmt.Errorf("archive interval must be positive")
+	}
+
+	go services.NewTaskArchiver(repo, after, logger).Run(context.Background(), interval)
+	return nil
+}
+
+// newRateLimitPolicy converts the configured per-minute limits into token buckets
+func newRateLimitPolicy(cfg config.RateLimitConfig) (ports.RateLimitPolicy, error) {
+	overrides, err := cfg.KeyOverrides()
+	if err != nil {
+		return ports.RateLimitPolicy{}, err
+	}
+
+	policy := ports.RateLimitPolicy{
+		Default: ports.RateLimits{
+			Read:  ports.PerMinute(cfg.ReadPerMinute),
+			Write: ports.PerMinute(cfg.WritePerMinute),
+		},
+		PerKey: make(map[string]ports.RateLimits, len(overrides)),
+	}
+	for subject, override := range overrides {
+		policy.PerKey[subject] = ports.RateLimits{
+			Read:  ports.PerMinute(override.ReadPerMinute),
+			Write: ports.PerMinute(override.WritePerMinute),
+		}
+	}
+
+	return policy, nil
+}
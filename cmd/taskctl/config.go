@@ -0,0 +1,215 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/spf13/cobra"
+	"gopkg.in/yaml.v3"
+)
+
+// configFile is the taskctl config file: named profiles, one of which is
+// used when no --profile is given
+type configFile struct {
+	CurrentProfile string             `json:"current_profile,omitempty" yaml:"current_profile,omitempty"`
+	Profiles       map[string]profile `json:"profiles,omitempty" yaml:"profiles,omitempty"`
+}
+
+// profile is a server and the credentials to use with it
+type profile struct {
+	Server string `json:"server,omitempty" yaml:"server,omitempty"`
+	APIKey string `json:"api_key,omitempty" yaml:"api_key,omitempty"`
+	Token  string `json:"token,omitempty" yaml:"token,omitempty"`
+	Tenant string `json:"tenant,omitempty" yaml:"tenant,omitempty"`
+}
+
+// defaultConfigPath is the config file used when neither --config nor
+// TASKCTL_CONFIG names one
+func defaultConfigPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return filepath.Join(".taskctl", "config.yaml")
+	}
+	return filepath.Join(dir, "taskctl", "config.yaml")
+}
+
+// displayConfigPath is how the default config path is shown in help, which
+// should not depend on who generated it
+func displayConfigPath() string {
+	return filepath.Join("<user config dir>", "taskctl", "config.yaml")
+}
+
+// loadConfig reads the config file at path. A missing file is an empty
+// config.
+func loadConfig(path string) (*configFile, error) {
+	cfg := &configFile{Profiles: map[string]profile{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return cfg, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("reading config: %w", err)
+	}
+	if err := yaml.Unmarshal(data, cfg); err != nil {
+		return nil, fmt.Errorf("parsing config %s: %w", path, err)
+	}
+	if cfg.Profiles == nil {
+		cfg.Profiles = map[string]profile{}
+	}
+	return cfg, nil
+}
+
+// saveConfig writes cfg to path. The file holds credentials, so only its
+// owner may read it.
+func saveConfig(path string, cfg *configFile) error {
+	data, err := yaml.Marshal(cfg)
+	if err != nil {
+		return fmt.Errorf("encoding config: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("writing config: %w", err)
+	}
+	return nil
+}
+
+func (a *app) configCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "config",
+		Short: "Manage profiles in the config file",
+	}
+	cmd.AddCommand(a.setProfileCommand(), a.useProfileCommand(), a.viewConfigCommand())
+	return cmd
+}
+
+func (a *app) setProfileCommand() *cobra.Command {
+	var (
+		settings profile
+		use      bool
+	)
+	cmd := &cobra.Command{
+		Use:   "set-profile NAME",
+		Short: "Create a profile or change the settings given for it",
+		Example: `  taskctl config set-profile prod --server https://tasks.example.com --api-key "$KEY" --use
+  taskctl config set-profile prod --tenant acme`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := a.resolveConfigPath()
+			cfg, err := loadConfig(path)
+			if err != nil {
+				return err
+			}
+
+			name := args[0]
+			current := cfg.Profiles[name]
+			flags := cmd.Flags()
+			if flags.Changed("server") {
+				current.Server = settings.Server
+			}
+			if flags.Changed("api-key") {
+				current.APIKey = settings.APIKey
+			}
+			if flags.Changed("token") {
+				current.Token = settings.Token
+			}
+			if flags.Changed("tenant") {
+				current.Tenant = settings.Tenant
+			}
+			cfg.Profiles[name] = current
+			if use || cfg.CurrentProfile == "" {
+				cfg.CurrentProfile = name
+			}
+
+			if err := saveConfig(path, cfg); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stdout, "Profile %q saved to %s\n", name, path)
+			return nil
+		},
+	}
+	// These shadow the persistent flags of the same name, which choose what
+	// this invocation connects to rather than what the profile stores
+	flags := cmd.Flags()
+	flags.StringVar(&settings.Server, "server", "", "base URL of the service")
+	flags.StringVar(&settings.APIKey, "api-key", "", "API key")
+	flags.StringVar(&settings.Token, "token", "", "bearer token")
+	flags.StringVar(&settings.Tenant, "tenant", "", "tenant to act within")
+	flags.BoolVar(&use, "use", false, "make this the current profile")
+	return cmd
+}
+
+func (a *app) useProfileCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "use-profile NAME",
+		Short:             "Use a profile when no --profile is given",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeProfiles,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := a.resolveConfigPath()
+			cfg, err := loadConfig(path)
+			if err != nil {
+				return err
+			}
+			if _, ok := cfg.Profiles[args[0]]; !ok {
+				return fmt.Errorf("profile %q is not in %s", args[0], path)
+			}
+			cfg.CurrentProfile = args[0]
+			if err := saveConfig(path, cfg); err != nil {
+				return err
+			}
+			fmt.Fprintf(a.stdout, "Using profile %q\n", args[0])
+			return nil
+		},
+	}
+}
+
+func (a *app) viewConfigCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "view",
+		Short: "Show the config file with credentials redacted",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cfg, err := loadConfig(a.resolveConfigPath())
+			if err != nil {
+				return err
+			}
+			for name, settings := range cfg.Profiles {
+				settings.APIKey = redact(settings.APIKey)
+				settings.Token = redact(settings.Token)
+				cfg.Profiles[name] = settings
+			}
+			return a.print(cfg, nil)
+		},
+	}
+}
+
+func redact(secret string) string {
+	if secret == "" {
+		return ""
+	}
+	return "REDACTED"
+}
+
+// completeProfiles completes the names of the profiles in the config file
+func (a *app) completeProfiles(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	cfg, err := loadConfig(a.resolveConfigPath())
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	names := make([]string, 0, len(cfg.Profiles))
+	for name := range cfg.Profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names, cobra.ShellCompDirectiveNoFileComp
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"task-tracking-service/api"
+	httpadapter "task-tracking-service/internal/adapters/http"
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/core/services"
+)
+
+const (
+	// inProcessBatchSize matches the server's default BATCH_MAX_SIZE
+	inProcessBatchSize = 100
+	// inProcessShutdownTimeout bounds how long stopping the server waits
+	// for requests still in flight
+	inProcessShutdownTimeout = 5 * time.Second
+)
+
+// startInProcessServer serves the real router over a loopback listener,
+// backed by an empty in-memory repository and without authentication. It
+// validates requests and responses against the API contract, as a
+// development server does. It returns the server's base URL and a function
+// that stops it.
+func startInProcessServer() (string, func(), error) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+
+	validator, err := httpadapter.NewContractValidator(api.Spec, logger)
+	if err != nil {
+		return "", nil, err
+	}
+
+	repo := memory.NewTaskRepository()
+	taskService := services.NewTaskService(repo, logger)
+	router := httpadapter.NewRouter(httpadapter.NewTaskHandler(taskService, logger),
+		httpadapter.WithLogger(logger),
+		httpadapter.WithContractValidation(validator),
+		httpadapter.WithBatchHandler(httpadapter.NewBatchHandler(services.NewBatchService(taskService, repo, inProcessBatchSize), logger)),
+		httpadapter.WithIdempotency(memory.NewIdempotencyStore(), time.Hour),
+	)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return "", nil, err
+	}
+	server := &http.Server{Handler: router, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			logger.Error("in-process server stopped", "error", err)
+		}
+	}()
+
+	stop := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), inProcessShutdownTimeout)
+		defer cancel()
+		_ = server.Shutdown(ctx)
+	}
+	return "http://" + listener.Addr().String(), stop, nil
+}
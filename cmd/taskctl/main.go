@@ -0,0 +1,24 @@
+package main
+
+import (
+	"io"
+	"os"
+)
+
+// taskctl manages tasks from the terminal through the task API
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+// run executes one taskctl command line and returns its exit code
+func run(args []string, stdout, stderr io.Writer) int {
+	a := newApp(stdout, stderr)
+	defer a.close()
+
+	cmd := a.rootCommand()
+	cmd.SetArgs(args)
+	if err := cmd.Execute(); err != nil {
+		return 1
+	}
+	return 0
+}
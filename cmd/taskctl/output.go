@@ -0,0 +1,84 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+	"time"
+
+	"task-tracking-service/pkg/client"
+
+	"gopkg.in/yaml.v3"
+)
+
+const (
+	outputTable = "table"
+	outputJSON  = "json"
+	outputYAML  = "yaml"
+)
+
+// print writes v in the chosen output format. Values without a table
+// layout are written as YAML when a table is asked for.
+func (a *app) print(v any, table func(w io.Writer)) error {
+	switch {
+	case a.output == outputJSON:
+		enc := json.NewEncoder(a.stdout)
+		enc.SetIndent("", "  ")
+		return enc.Encode(v)
+	case a.output == outputYAML || table == nil:
+		return writeYAML(a.stdout, v)
+	default:
+		w := tabwriter.NewWriter(a.stdout, 0, 0, 2, ' ', 0)
+		table(w)
+		return w.Flush()
+	}
+}
+
+// writeYAML writes v as YAML with the same field names as its JSON. Going
+// through JSON keeps the json tags of the client types authoritative.
+func writeYAML(w io.Writer, v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	var generic any
+	if err := json.Unmarshal(data, &generic); err != nil {
+		return err
+	}
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(generic); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// printTasks writes tasks, one row per task in a table
+func (a *app) printTasks(tasks []*client.Task) error {
+	if tasks == nil {
+		tasks = []*client.Task{}
+	}
+	return a.print(tasks, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tSTATUS\tDUE\tTITLE")
+		for _, task := range tasks {
+			writeTaskRow(w, task)
+		}
+	})
+}
+
+// printTask writes a single task
+func (a *app) printTask(task *client.Task) error {
+	return a.print(task, func(w io.Writer) {
+		fmt.Fprintln(w, "ID\tSTATUS\tDUE\tTITLE")
+		writeTaskRow(w, task)
+	})
+}
+
+func writeTaskRow(w io.Writer, task *client.Task) {
+	status := string(task.Status)
+	if task.ArchivedAt != nil {
+		status += " (archived)"
+	}
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", task.ID, status, task.DueDate.Local().Format(time.DateTime), task.Title)
+}
@@ -0,0 +1,156 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"task-tracking-service/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// defaultServer is used when neither a flag, the environment nor the
+// profile names a server
+const defaultServer = "http://localhost:8080"
+
+// app holds what the commands share: the global flags, and the client
+// built from them the first time a command needs one
+type app struct {
+	stdout io.Writer
+	stderr io.Writer
+
+	configPath string
+	profile    string
+	server     string
+	apiKey     string
+	token      string
+	tenant     string
+	output     string
+	inProcess  bool
+
+	client *client.Client
+	// stopServer stops the in-process server, if one was started
+	stopServer func()
+}
+
+func newApp(stdout, stderr io.Writer) *app {
+	return &app{stdout: stdout, stderr: stderr}
+}
+
+// close releases what the last command left running
+func (a *app) close() {
+	if a.stopServer != nil {
+		a.stopServer()
+	}
+}
+
+func (a *app) rootCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "taskctl",
+		Short: "Manage tasks in the task tracking service",
+		Long: `taskctl manages tasks through the task API.
+
+The server and credentials come from the command line, then the TASKCTL_*
+environment variables, then the current profile of the config file.`,
+		SilenceUsage: true,
+		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+			switch a.output {
+			case outputTable, outputJSON, outputYAML:
+				return nil
+			default:
+				return fmt.Errorf("unknown output format %q: use table, json or yaml", a.output)
+			}
+		},
+	}
+	cmd.SetOut(a.stdout)
+	cmd.SetErr(a.stderr)
+
+	flags := cmd.PersistentFlags()
+	flags.StringVar(&a.configPath, "config", "", "config file (default $TASKCTL_CONFIG or "+displayConfigPath()+")")
+	flags.StringVarP(&a.profile, "profile", "p", "", "profile of the config file to use (default $TASKCTL_PROFILE or the current profile)")
+	flags.StringVar(&a.server, "server", "", "base URL of the service (default $TASKCTL_SERVER or "+defaultServer+")")
+	flags.StringVar(&a.apiKey, "api-key", "", "API key (default $TASKCTL_API_KEY)")
+	flags.StringVar(&a.token, "token", "", "bearer token (default $TASKCTL_TOKEN)")
+	flags.StringVar(&a.tenant, "tenant", "", "tenant to act within (default $TASKCTL_TENANT)")
+	flags.StringVarP(&a.output, "output", "o", outputTable, "output format: table, json or yaml")
+	flags.BoolVar(&a.inProcess, "in-process", false, "run against a fresh in-memory server inside taskctl, for trying out scripts")
+	_ = cmd.RegisterFlagCompletionFunc("output", cobra.FixedCompletions(
+		[]string{outputTable, outputJSON, outputYAML}, cobra.ShellCompDirectiveNoFileComp))
+	_ = cmd.RegisterFlagCompletionFunc("profile", a.completeProfiles)
+
+	cmd.AddCommand(
+		a.listCommand(),
+		a.getCommand(),
+		a.createCommand(),
+		a.updateCommand(),
+		a.deleteCommand(),
+		a.moveCommand(),
+		a.configCommand(),
+	)
+	return cmd
+}
+
+// connect returns the client for the server and credentials chosen by the
+// flags, the environment and the config file, in that order
+func (a *app) connect() (*client.Client, error) {
+	if a.client != nil {
+		return a.client, nil
+	}
+
+	var settings profile
+	if a.inProcess {
+		server, stop, err := startInProcessServer()
+		if err != nil {
+			return nil, fmt.Errorf("starting in-process server: %w", err)
+		}
+		a.stopServer = stop
+		settings.Server = server
+	} else {
+		cfg, err := loadConfig(a.resolveConfigPath())
+		if err != nil {
+			return nil, err
+		}
+		name := firstNonEmpty(a.profile, os.Getenv("TASKCTL_PROFILE"), cfg.CurrentProfile)
+		if name != "" {
+			var ok bool
+			if settings, ok = cfg.Profiles[name]; !ok {
+				return nil, fmt.Errorf("profile %q is not in %s", name, a.resolveConfigPath())
+			}
+		}
+		settings.Server = firstNonEmpty(a.server, os.Getenv("TASKCTL_SERVER"), settings.Server, defaultServer)
+		settings.APIKey = firstNonEmpty(a.apiKey, os.Getenv("TASKCTL_API_KEY"), settings.APIKey)
+		settings.Token = firstNonEmpty(a.token, os.Getenv("TASKCTL_TOKEN"), settings.Token)
+	}
+	settings.Tenant = firstNonEmpty(a.tenant, os.Getenv("TASKCTL_TENANT"), settings.Tenant)
+
+	var opts []client.Option
+	if settings.APIKey != "" {
+		opts = append(opts, client.WithAPIKey(settings.APIKey))
+	}
+	if settings.Token != "" {
+		opts = append(opts, client.WithBearerToken(settings.Token))
+	}
+	if settings.Tenant != "" {
+		opts = append(opts, client.WithTenant(settings.Tenant))
+	}
+	c, err := client.New(settings.Server, opts...)
+	if err != nil {
+		return nil, err
+	}
+	a.client = c
+	return c, nil
+}
+
+func (a *app) resolveConfigPath() string {
+	return firstNonEmpty(a.configPath, os.Getenv("TASKCTL_CONFIG"), defaultConfigPath())
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, value := range values {
+		if value != "" {
+			return value
+		}
+	}
+	return ""
+}
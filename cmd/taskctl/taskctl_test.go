@@ -0,0 +1,296 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"task-tracking-service/pkg/client"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// taskctl runs one command line and returns its exit code,
+// standard output and standard error
+type taskctl func(args ...string) (int, string, string)
+
+// newTaskctl starts an in-process server and points taskctl at it, with a
+// config file of its own
+func newTaskctl(t *testing.T) taskctl {
+	t.Helper()
+	server, stop, err := startInProcessServer()
+	require.NoError(t, err)
+	t.Cleanup(stop)
+	for _, name := range []string{"TASKCTL_PROFILE", "TASKCTL_API_KEY", "TASKCTL_TOKEN", "TASKCTL_TENANT"} {
+		t.Setenv(name, "")
+	}
+	t.Setenv("TASKCTL_SERVER", server)
+	t.Setenv("TASKCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+
+	return func(args ...string) (int, string, string) {
+		var stdout, stderr bytes.Buffer
+		code := run(args, &stdout, &stderr)
+		return code, stdout.String(), stderr.String()
+	}
+}
+
+// createTask creates a task through taskctl and returns it
+func createTask(t *testing.T, ctl taskctl, args ...string) *client.Task {
+	t.Helper()
+	code, stdout, stderr := ctl(append([]string{"create", "-o", "json"}, args...)...)
+	require.Equal(t, 0, code, stderr)
+	var task client.Task
+	require.NoError(t, json.Unmarshal([]byte(stdout), &task))
+	return &task
+}
+
+func TestTaskctl_Tasks(t *testing.T) {
+	ctl := newTaskctl(t)
+
+	t.Run("create and get", func(t *testing.T) {
+		created := createTask(t, ctl, "--title", "Write report", "--description", "Quarterly", "--due", "2030-01-02")
+		assert.Equal(t, "Write report", created.Title)
+		assert.Equal(t, client.StatusPending, created.Status)
+		assert.Equal(t, time.Date(2030, 1, 2, 23, 59, 59, 0, time.UTC), created.DueDate.UTC())
+
+		code, stdout, stderr := ctl("get", created.ID)
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "ID")
+		assert.Contains(t, stdout, created.ID)
+		assert.Contains(t, stdout, "Write report")
+	})
+
+	t.Run("create requires a title", func(t *testing.T) {
+		code, _, stderr := ctl("create", "--due", "1h")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, `"title" not set`)
+	})
+
+	t.Run("update changes only the given fields", func(t *testing.T) {
+		task := createTask(t, ctl, "--title", "Draft", "--description", "Keep me", "--due", "48h")
+
+		code, stdout, stderr := ctl("update", task.ID, "--title", "Final", "-o", "yaml")
+		require.Equal(t, 0, code, stderr)
+		var updated client.Task
+		require.NoError(t, yaml.Unmarshal([]byte(stdout), &updated))
+		assert.Equal(t, "Final", updated.Title)
+		assert.Equal(t, "Keep me", updated.Description)
+
+		code, _, stderr = ctl("update", task.ID, "--title", "Stale", "--if-version", "1")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "412")
+	})
+
+	t.Run("move", func(t *testing.T) {
+		task := createTask(t, ctl, "--title", "Move me", "--due", "1h")
+
+		code, stdout, stderr := ctl("move", task.ID, "in_progress", "-o", "json")
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, `"status": "in_progress"`)
+
+		code, _, stderr = ctl("move", task.ID, "done")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, `unknown status "done"`)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		task := createTask(t, ctl, "--title", "Delete me", "--due", "1h")
+
+		code, stdout, stderr := ctl("delete", task.ID)
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "moved to the trash")
+
+		code, _, stderr = ctl("get", task.ID)
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, "404")
+	})
+}
+
+func TestTaskctl_List(t *testing.T) {
+	ctl := newTaskctl(t)
+	report := createTask(t, ctl, "--title", "Report", "--project", "alpha", "--due", "2030-01-10")
+	review := createTask(t, ctl, "--title", "Review", "--project", "beta", "--due", "2030-01-20")
+	call := createTask(t, ctl, "--title", "Call", "--project", "alpha", "--due", "2030-01-30")
+	code, _, stderr := ctl("move", review.ID, "completed")
+	require.Equal(t, 0, code, stderr)
+
+	listed := func(t *testing.T, args ...string) []string {
+		t.Helper()
+		code, stdout, stderr := ctl(append([]string{"list", "-o", "json", "--page-size", "1"}, args...)...)
+		require.Equal(t, 0, code, stderr)
+		var tasks []*client.Task
+		require.NoError(t, json.Unmarshal([]byte(stdout), &tasks))
+		titles := make([]string, len(tasks))
+		for i, task := range tasks {
+			titles[i] = task.Title
+		}
+		return titles
+	}
+
+	tests := []struct {
+		name string
+		args []string
+		want []string
+	}{
+		{name: "all", want: []string{call.Title, review.Title, report.Title}},
+		{name: "status", args: []string{"--status", "completed"}, want: []string{review.Title}},
+		{name: "several statuses", args: []string{"--status", "pending,completed"}, want: []string{call.Title, review.Title, report.Title}},
+		{name: "project", args: []string{"--project", "alpha"}, want: []string{call.Title, report.Title}},
+		{name: "due window", args: []string{"--due-after", "2030-01-15", "--due-before", "2030-01-25"}, want: []string{review.Title}},
+		{name: "limit", args: []string{"--limit", "2"}, want: []string{call.Title, review.Title}},
+		{name: "nothing", args: []string{"--project", "gamma"}, want: []string{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, listed(t, tt.args...))
+		})
+	}
+
+	t.Run("table", func(t *testing.T) {
+		code, stdout, stderr := ctl("list", "--status", "pending")
+		require.Equal(t, 0, code, stderr)
+		lines := strings.Split(strings.TrimSpace(stdout), "\n")
+		require.Len(t, lines, 3)
+		assert.Regexp(t, `^ID\s+STATUS\s+DUE\s+TITLE$`, lines[0])
+		assert.Contains(t, lines[1], call.ID)
+		assert.Contains(t, lines[2], report.ID)
+	})
+
+	t.Run("unknown output format", func(t *testing.T) {
+		code, _, stderr := ctl("list", "-o", "xml")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, `unknown output format "xml"`)
+	})
+}
+
+func TestTaskctl_Profiles(t *testing.T) {
+	ctl := newTaskctl(t)
+	configPath := os.Getenv("TASKCTL_CONFIG")
+
+	code, _, stderr := ctl("config", "set-profile", "prod", "--server", "https://tasks.example.com", "--api-key", "secret-key")
+	require.Equal(t, 0, code, stderr)
+	code, _, stderr = ctl("config", "set-profile", "staging", "--server", "https://staging.example.com", "--tenant", "acme")
+	require.Equal(t, 0, code, stderr)
+
+	t.Run("the first profile becomes current", func(t *testing.T) {
+		cfg, err := loadConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "prod", cfg.CurrentProfile)
+		assert.Equal(t, profile{Server: "https://tasks.example.com", APIKey: "secret-key"}, cfg.Profiles["prod"])
+		assert.Equal(t, profile{Server: "https://staging.example.com", Tenant: "acme"}, cfg.Profiles["staging"])
+
+		info, err := os.Stat(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+	})
+
+	t.Run("set-profile keeps settings not given", func(t *testing.T) {
+		code, _, stderr := ctl("config", "set-profile", "prod", "--tenant", "acme")
+		require.Equal(t, 0, code, stderr)
+		cfg, err := loadConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, profile{Server: "https://tasks.example.com", APIKey: "secret-key", Tenant: "acme"}, cfg.Profiles["prod"])
+	})
+
+	t.Run("use-profile", func(t *testing.T) {
+		code, _, stderr := ctl("config", "use-profile", "staging")
+		require.Equal(t, 0, code, stderr)
+		cfg, err := loadConfig(configPath)
+		require.NoError(t, err)
+		assert.Equal(t, "staging", cfg.CurrentProfile)
+
+		code, _, stderr = ctl("config", "use-profile", "missing")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, `profile "missing" is not in`)
+	})
+
+	t.Run("view redacts credentials", func(t *testing.T) {
+		code, stdout, stderr := ctl("config", "view")
+		require.Equal(t, 0, code, stderr)
+		assert.NotContains(t, stdout, "secret-key")
+		assert.Contains(t, stdout, "api_key: REDACTED")
+		assert.Contains(t, stdout, "current_profile: staging")
+	})
+
+	t.Run("the environment overrides the profile", func(t *testing.T) {
+		// newTaskctl sets TASKCTL_SERVER, so the profile's unreachable
+		// example.com server is never dialled. The in-process server only
+		// serves the default tenant.
+		t.Setenv("TASKCTL_TENANT", "default")
+		code, _, stderr := ctl("list", "--profile", "prod")
+		assert.Equal(t, 0, code, stderr)
+
+		code, _, stderr = ctl("list", "--profile", "missing")
+		assert.Equal(t, 1, code)
+		assert.Contains(t, stderr, `profile "missing" is not in`)
+	})
+}
+
+func TestTaskctl_Completion(t *testing.T) {
+	ctl := newTaskctl(t)
+	task := createTask(t, ctl, "--title", "Complete me", "--due", "1h")
+
+	t.Run("script", func(t *testing.T) {
+		code, stdout, stderr := ctl("completion", "bash")
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "__start_taskctl")
+	})
+
+	t.Run("task IDs", func(t *testing.T) {
+		code, stdout, stderr := ctl("__complete", "get", "")
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, task.ID+"\tComplete me")
+	})
+
+	t.Run("statuses", func(t *testing.T) {
+		code, stdout, stderr := ctl("__complete", "move", task.ID, "")
+		require.Equal(t, 0, code, stderr)
+		assert.Contains(t, stdout, "in_progress")
+	})
+}
+
+func TestTaskctl_InProcess(t *testing.T) {
+	t.Setenv("TASKCTL_CONFIG", filepath.Join(t.TempDir(), "config.yaml"))
+
+	var stdout, stderr bytes.Buffer
+	code := run([]string{"--in-process", "create", "--title", "Scratch", "--due", "1h", "-o", "json"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	assert.Contains(t, stdout.String(), `"title": "Scratch"`)
+
+	// Every invocation starts from an empty server
+	stdout.Reset()
+	code = run([]string{"--in-process", "list", "-o", "json"}, &stdout, &stderr)
+	require.Equal(t, 0, code, stderr.String())
+	assert.JSONEq(t, `[]`, stdout.String())
+}
+
+func TestParseDue(t *testing.T) {
+	now := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		value   string
+		want    time.Time
+		wantErr bool
+	}{
+		{value: "2025-07-01T09:30:00+02:00", want: time.Date(2025, 7, 1, 7, 30, 0, 0, time.UTC)},
+		{value: "2025-07-01", want: time.Date(2025, 7, 1, 23, 59, 59, 0, time.UTC)},
+		{value: "36h", want: time.Date(2025, 6, 3, 0, 0, 0, 0, time.UTC)},
+		{value: "tomorrow", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.value, func(t *testing.T) {
+			got, err := parseDue(tt.value, now)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.True(t, tt.want.Equal(got), "got %s", got)
+		})
+	}
+}
@@ -0,0 +1,322 @@
+package main
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"task-tracking-service/pkg/client"
+
+	"github.com/spf13/cobra"
+)
+
+// completionLimit bounds how many tasks are listed to complete a task ID
+const completionLimit = 100
+
+var taskStatuses = []string{
+	string(client.StatusPending),
+	string(client.StatusInProgress),
+	string(client.StatusCompleted),
+}
+
+func (a *app) listCommand() *cobra.Command {
+	var (
+		statuses  []string
+		project   string
+		dueBefore string
+		dueAfter  string
+		archived  bool
+		limit     int
+		pageSize  int
+	)
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List tasks, newest first",
+		Example: `  taskctl list --status pending --status in_progress
+  taskctl list --due-before 2025-07-01 -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			for _, status := range statuses {
+				if !slices.Contains(taskStatuses, status) {
+					return fmt.Errorf("unknown status %q: use one of %s", status, strings.Join(taskStatuses, ", "))
+				}
+			}
+			var before, after time.Time
+			var err error
+			if dueBefore != "" {
+				if before, err = parseDue(dueBefore, time.Now()); err != nil {
+					return fmt.Errorf("invalid --due-before: %w", err)
+				}
+			}
+			if dueAfter != "" {
+				if after, err = parseDue(dueAfter, time.Now()); err != nil {
+					return fmt.Errorf("invalid --due-after: %w", err)
+				}
+			}
+
+			c, err := a.connect()
+			if err != nil {
+				return err
+			}
+			opts := client.ListTasksOptions{
+				IncludeArchived: archived,
+				ProjectID:       project,
+				DueBefore:       before,
+				DueAfter:        after,
+				PageSize:        pageSize,
+			}
+			for _, status := range statuses {
+				opts.Statuses = append(opts.Statuses, client.TaskStatus(status))
+			}
+
+			tasks := []*client.Task{}
+			for task, err := range c.ListTasks(cmd.Context(), opts) {
+				if err != nil {
+					return err
+				}
+				tasks = append(tasks, task)
+				if limit > 0 && len(tasks) == limit {
+					break
+				}
+			}
+			return a.printTasks(tasks)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringSliceVar(&statuses, "status", nil, "only list tasks with this status; repeat for several")
+	flags.StringVar(&project, "project", "", "only list tasks of this project")
+	flags.StringVar(&dueBefore, "due-before", "", "only list tasks due before this time")
+	flags.StringVar(&dueAfter, "due-after", "", "only list tasks due after this time")
+	flags.BoolVar(&archived, "archived", false, "also list archived tasks")
+	flags.IntVar(&limit, "limit", 0, "list at most this many tasks (0 lists all)")
+	flags.IntVar(&pageSize, "page-size", 0, "tasks fetched per request (default 100)")
+	_ = cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(taskStatuses, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+func (a *app) getCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:               "get ID",
+		Short:             "Show a task",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.connect()
+			if err != nil {
+				return err
+			}
+			task, err := c.GetTask(cmd.Context(), args[0])
+			if err != nil {
+				return err
+			}
+			return a.printTask(task)
+		},
+	}
+}
+
+func (a *app) createCommand() *cobra.Command {
+	var (
+		req client.CreateTaskRequest
+		due string
+	)
+	cmd := &cobra.Command{
+		Use:   "create",
+		Short: "Create a pending task",
+		Example: `  taskctl create --title "Write report" --due 2025-07-01
+  taskctl create --title "Call back" --due 2h -o json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			dueDate, err := parseDue(due, time.Now())
+			if err != nil {
+				return fmt.Errorf("invalid --due: %w", err)
+			}
+			req.DueDate = dueDate
+
+			c, err := a.connect()
+			if err != nil {
+				return err
+			}
+			task, err := c.CreateTask(cmd.Context(), req)
+			if err != nil {
+				return err
+			}
+			return a.printTask(task)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&req.Title, "title", "", "title of the task")
+	flags.StringVar(&req.Description, "description", "", "description of the task")
+	flags.StringVar(&req.ProjectID, "project", "", "project the task belongs to")
+	flags.StringVar(&due, "due", "", "when the task is due: RFC 3339, YYYY-MM-DD or a duration from now such as 48h")
+	_ = cmd.MarkFlagRequired("title")
+	_ = cmd.MarkFlagRequired("due")
+	return cmd
+}
+
+func (a *app) updateCommand() *cobra.Command {
+	var (
+		title       string
+		description string
+		project     string
+		status      string
+		due         string
+		version     int64
+	)
+	cmd := &cobra.Command{
+		Use:               "update ID",
+		Short:             "Change the fields of a task given as flags",
+		Example:           `  taskctl update "$TASK_ID" --title "Write the final report" --if-version 2`,
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			flags := cmd.Flags()
+			patch := map[string]any{}
+			if flags.Changed("title") {
+				patch["title"] = title
+			}
+			if flags.Changed("description") {
+				patch["description"] = description
+			}
+			if flags.Changed("project") {
+				patch["project_id"] = project
+			}
+			if flags.Changed("status") {
+				if !slices.Contains(taskStatuses, status) {
+					return fmt.Errorf("unknown status %q: use one of %s", status, strings.Join(taskStatuses, ", "))
+				}
+				patch["status"] = status
+			}
+			if flags.Changed("due") {
+				dueDate, err := parseDue(due, time.Now())
+				if err != nil {
+					return fmt.Errorf("invalid --due: %w", err)
+				}
+				patch["due_date"] = dueDate
+			}
+			if len(patch) == 0 {
+				return fmt.Errorf("nothing to update: give at least one of --title, --description, --project, --status or --due")
+			}
+
+			c, err := a.connect()
+			if err != nil {
+				return err
+			}
+			task, err := c.PatchTask(cmd.Context(), args[0], patch, version)
+			if err != nil {
+				return err
+			}
+			return a.printTask(task)
+		},
+	}
+	flags := cmd.Flags()
+	flags.StringVar(&title, "title", "", "new title")
+	flags.StringVar(&description, "description", "", "new description")
+	flags.StringVar(&project, "project", "", "new project")
+	flags.StringVar(&status, "status", "", "new status")
+	flags.StringVar(&due, "due", "", "new due time: RFC 3339, YYYY-MM-DD or a duration from now such as 48h")
+	flags.Int64Var(&version, "if-version", 0, "only update the task if it is still at this version")
+	_ = cmd.RegisterFlagCompletionFunc("status", cobra.FixedCompletions(taskStatuses, cobra.ShellCompDirectiveNoFileComp))
+	return cmd
+}
+
+func (a *app) deleteCommand() *cobra.Command {
+	var version int64
+	cmd := &cobra.Command{
+		Use:               "delete ID",
+		Short:             "Move a task to the trash",
+		Args:              cobra.ExactArgs(1),
+		ValidArgsFunction: a.completeTaskIDs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			c, err := a.connect()
+			if err != nil {
+				return err
+			}
+			if err := c.DeleteTask(cmd.Context(), args[0], version); err != nil {
+				return err
+			}
+			if a.output == outputTable {
+				fmt.Fprintf(a.stdout, "Task %s moved to the trash\n", args[0])
+			}
+			return nil
+		},
+	}
+	cmd.Flags().Int64Var(&version, "if-version", 0, "only delete the task if it is still at this version")
+	return cmd
+}
+
+func (a *app) moveCommand() *cobra.Command {
+	var version int64
+	cmd := &cobra.Command{
+		Use:     "move ID STATUS",
+		Short:   "Change the status of a task",
+		Long:    "Change the status of a task to " + strings.Join(taskStatuses, ", ") + ".",
+		Example: `  taskctl move "$TASK_ID" in_progress`,
+		Args:    cobra.ExactArgs(2),
+		ValidArgsFunction: func(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+			switch len(args) {
+			case 0:
+				return a.completeTaskIDs(cmd, args, toComplete)
+			case 1:
+				return taskStatuses, cobra.ShellCompDirectiveNoFileComp
+			default:
+				return nil, cobra.ShellCompDirectiveNoFileComp
+			}
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			status := args[1]
+			if !slices.Contains(taskStatuses, status) {
+				return fmt.Errorf("unknown status %q: use one of %s", status, strings.Join(taskStatuses, ", "))
+			}
+			c, err := a.connect()
+			if err != nil {
+				return err
+			}
+			task, err := c.PatchTask(cmd.Context(), args[0], map[string]any{"status": status}, version)
+			if err != nil {
+				return err
+			}
+			return a.printTask(task)
+		},
+	}
+	cmd.Flags().Int64Var(&version, "if-version", 0, "only move the task if it is still at this version")
+	return cmd
+}
+
+// completeTaskIDs completes the IDs of the newest tasks, described by
+// their titles
+func (a *app) completeTaskIDs(cmd *cobra.Command, args []string, toComplete string) ([]string, cobra.ShellCompDirective) {
+	if len(args) > 0 {
+		return nil, cobra.ShellCompDirectiveNoFileComp
+	}
+	c, err := a.connect()
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	page, err := c.ListTasksPage(cmd.Context(), client.ListTasksOptions{PageSize: completionLimit}, "")
+	if err != nil {
+		return nil, cobra.ShellCompDirectiveError
+	}
+	var ids []string
+	for _, task := range page.Tasks {
+		if strings.HasPrefix(task.ID, toComplete) {
+			ids = append(ids, task.ID+"\t"+task.Title)
+		}
+	}
+	return ids, cobra.ShellCompDirectiveNoFileComp
+}
+
+// parseDue reads a due time given as an RFC 3339 timestamp, as a date
+// meaning the end of that day in UTC, or as a duration from now
+func parseDue(value string, now time.Time) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t.Add(24*time.Hour - time.Second), nil
+	}
+	if d, err := time.ParseDuration(value); err == nil {
+		return now.Add(d).UTC().Truncate(time.Second), nil
+	}
+	return time.Time{}, fmt.Errorf("%q is not an RFC 3339 time, a YYYY-MM-DD date or a duration", value)
+}
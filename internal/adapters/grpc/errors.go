@@ -0,0 +1,88 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"task-tracking-service/internal/core/domain"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// internalErrorMessage hides the details of unexpected errors from callers
+const internalErrorMessage = "Internal Server Error"
+
+// toStatus is the single place where errors returned by handlers and
+// interceptors become gRPC statuses. Errors from the core are classified
+// by type, as the HTTP error handler does, so that a failure is reported
+// the same way by both APIs:
+//
+//	ValidationError          400 INVALID_ARGUMENT
+//	NotFoundError            404 NOT_FOUND
+//	ConflictError            409 ABORTED
+//	ForbiddenError           403 PERMISSION_DENIED
+//	PreconditionFailedError  412 FAILED_PRECONDITION
+//	anything else            500 INTERNAL
+//
+// Anything unrecognised is logged and reported without its details. The
+// request ID is attached to every error as a RequestInfo detail.
+func toStatus(ctx context.Context, err error, logger *slog.Logger) *status.Status {
+	st := classify(err)
+	if st.Code() == codes.Internal {
+		logger.ErrorContext(ctx, "internal error", "error", err)
+	}
+
+	if id := domain.RequestIDFromContext(ctx); id != "" {
+		if withID, err := st.WithDetails(&errdetails.RequestInfo{RequestId: id}); err == nil {
+			st = withID
+		}
+	}
+	return st
+}
+
+func classify(err error) *status.Status {
+	if st, ok := status.FromError(err); ok {
+		return st
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err)
+	}
+
+	var (
+		validationErr   *customerrors.ValidationError
+		notFoundErr     *customerrors.NotFoundError
+		conflictErr     *customerrors.ConflictError
+		forbiddenErr    *customerrors.ForbiddenError
+		preconditionErr *customerrors.PreconditionFailedError
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		st := status.New(codes.InvalidArgument, validationErr.Error())
+		if len(validationErr.Fields) == 0 {
+			return st
+		}
+		violations := make([]*errdetails.BadRequest_FieldViolation, len(validationErr.Fields))
+		for i, field := range validationErr.Fields {
+			violations[i] = &errdetails.BadRequest_FieldViolation{Field: field.Field, Description: field.Message}
+		}
+		if withDetails, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: violations}); err == nil {
+			return withDetails
+		}
+		return st
+	case errors.As(err, &notFoundErr):
+		return status.New(codes.NotFound, notFoundErr.Error())
+	case errors.As(err, &conflictErr):
+		return status.New(codes.Aborted, conflictErr.Error())
+	case errors.As(err, &forbiddenErr):
+		return status.New(codes.PermissionDenied, forbiddenErr.Error())
+	case errors.As(err, &preconditionErr):
+		return status.New(codes.FailedPrecondition, preconditionErr.Error())
+	default:
+		return status.New(codes.Internal, internalErrorMessage)
+	}
+}
@@ -0,0 +1,96 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"testing"
+
+	"task-tracking-service/internal/core/domain"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestToStatus(t *testing.T) {
+	tests := []struct {
+		name        string
+		err         error
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{
+			name:        "validation error",
+			err:         customerrors.NewValidationError("Request validation failed", customerrors.FieldError{Field: "title", Message: "is required"}),
+			wantCode:    codes.InvalidArgument,
+			wantMessage: "Request validation failed",
+		},
+		{
+			name:        "not found error",
+			err:         fmt.Errorf("loading: %w", customerrors.ErrTaskNotFound),
+			wantCode:    codes.NotFound,
+			wantMessage: "task not found",
+		},
+		{
+			name:        "conflict error",
+			err:         customerrors.ErrTaskArchived,
+			wantCode:    codes.Aborted,
+			wantMessage: "task is archived",
+		},
+		{
+			name:        "forbidden error",
+			err:         customerrors.NewForbiddenError("not allowed"),
+			wantCode:    codes.PermissionDenied,
+			wantMessage: "not allowed",
+		},
+		{
+			name:        "precondition failed error",
+			err:         customerrors.ErrTaskVersionMismatch,
+			wantCode:    codes.FailedPrecondition,
+			wantMessage: "task version does not match",
+		},
+		{
+			name:        "status error passes through",
+			err:         status.Error(codes.Unauthenticated, "Missing credentials"),
+			wantCode:    codes.Unauthenticated,
+			wantMessage: "Missing credentials",
+		},
+		{
+			name:     "cancelled context",
+			err:      fmt.Errorf("listing: %w", context.Canceled),
+			wantCode: codes.Canceled,
+		},
+		{
+			name:        "unexpected error hides its details",
+			err:         errors.New("connection refused"),
+			wantCode:    codes.Internal,
+			wantMessage: internalErrorMessage,
+		},
+	}
+
+	ctx := domain.ContextWithRequestID(context.Background(), "req-1")
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			st := toStatus(ctx, tt.err, slog.Default())
+
+			assert.Equal(t, tt.wantCode, st.Code())
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, st.Message())
+			}
+			assert.Equal(t, "req-1", requestIDOf(st))
+		})
+	}
+}
+
+func requestIDOf(st *status.Status) string {
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RequestInfo); ok {
+			return info.GetRequestId()
+		}
+	}
+	return ""
+}
@@ -0,0 +1,313 @@
+package grpc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/google/uuid"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// Metadata keys are the lowercase forms of the HTTP API's headers
+const (
+	metadataAuthorization = "authorization"
+	metadataAPIKey        = "x-api-key"
+	metadataTenantID      = "x-tenant-id"
+	metadataRequestID     = "x-request-id"
+)
+
+// publicServicePrefix marks the services reachable without credentials.
+// Reflection only describes the API, like the HTTP API's documentation.
+const publicServicePrefix = "/grpc.reflection."
+
+func isPublic(method string) bool {
+	return strings.HasPrefix(method, publicServicePrefix)
+}
+
+// isRead tells whether a method only reads, and so counts against the read
+// rate limit like GET requests do
+func isRead(method string) bool {
+	_, name, _ := strings.Cut(strings.TrimPrefix(method, "/"), "/")
+	for _, prefix := range []string{"Get", "List", "Watch"} {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// interceptor wraps a call to a handler. The same interceptor serves unary
+// and streaming calls: call runs the rest of the chain with the context it
+// is given.
+type interceptor func(ctx context.Context, method string, call func(context.Context) error) error
+
+func (i interceptor) unary() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		var resp any
+		err := i(ctx, info.FullMethod, func(ctx context.Context) error {
+			var err error
+			resp, err = handler(ctx, req)
+			return err
+		})
+		return resp, err
+	}
+}
+
+func (i interceptor) stream() grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		return i(ss.Context(), info.FullMethod, func(ctx context.Context) error {
+			return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+		})
+	}
+}
+
+// contextStream replaces the context of a server stream
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+func firstMetadata(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// requestIDInterceptor gives each call an ID and returns it in the
+// x-request-id header. A valid ID sent by the caller is kept.
+func requestIDInterceptor() interceptor {
+	return func(ctx context.Context, method string, call func(context.Context) error) error {
+		id := firstMetadata(ctx, metadataRequestID)
+		if !domain.IsValidRequestID(id) {
+			id = uuid.New().String()
+		}
+
+		_ = grpc.SetHeader(ctx, metadata.Pairs(metadataRequestID, id))
+		return call(domain.ContextWithRequestID(ctx, id))
+	}
+}
+
+// accessLogInterceptor logs one record per call. Server errors are logged
+// at error level and everything else at info.
+func accessLogInterceptor(logger *slog.Logger) interceptor {
+	return func(ctx context.Context, method string, call func(context.Context) error) error {
+		start := time.Now()
+		err := call(ctx)
+
+		code := status.Code(err)
+		level := slog.LevelInfo
+		if isServerError(code) {
+			level = slog.LevelError
+		}
+		attrs := []slog.Attr{
+			slog.String("method", method),
+			slog.String("code", code.String()),
+			slog.Duration("latency", time.Since(start)),
+			slog.String("remote_ip", peerHost(ctx)),
+		}
+		if err != nil {
+			attrs = append(attrs, slog.String("error", status.Convert(err).Message()))
+		}
+		logger.LogAttrs(ctx, level, "rpc", attrs...)
+		return err
+	}
+}
+
+// isServerError tells whether a code is one the HTTP API would report with
+// a 5xx status
+func isServerError(code codes.Code) bool {
+	switch code {
+	case codes.Unknown, codes.Internal, codes.Unavailable, codes.DataLoss, codes.Unimplemented:
+		return true
+	}
+	return false
+}
+
+func peerHost(ctx context.Context) string {
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return ""
+	}
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		return p.Addr.String()
+	}
+	return host
+}
+
+// metricsInterceptor records each call in m
+func metricsInterceptor(m *RequestMetrics) interceptor {
+	return func(ctx context.Context, method string, call func(context.Context) error) error {
+		start := time.Now()
+		err := call(ctx)
+		m.observe(method, status.Code(err), time.Since(start))
+		return err
+	}
+}
+
+// statusInterceptor turns every error returned further down the chain into
+// a gRPC status
+func statusInterceptor(logger *slog.Logger) interceptor {
+	return func(ctx context.Context, method string, call func(context.Context) error) error {
+		if err := call(ctx); err != nil {
+			return toStatus(ctx, err, logger).Err()
+		}
+		return nil
+	}
+}
+
+// recoverInterceptor turns a panic in a handler into an internal error
+func recoverInterceptor() interceptor {
+	return func(ctx context.Context, method string, call func(context.Context) error) (err error) {
+		defer func() {
+			if r := recover(); r != nil {
+				err = fmt.Errorf("panic: %v", r)
+			}
+		}()
+		return call(ctx)
+	}
+}
+
+// authInterceptor rejects calls that do not carry valid credentials and
+// stores the authenticated principal in the call context
+func authInterceptor(authenticator auth.Authenticator) interceptor {
+	return func(ctx context.Context, method string, call func(context.Context) error) error {
+		if isPublic(method) {
+			return call(ctx)
+		}
+
+		principal, err := authenticator.Authenticate(ctx, credentialFromMetadata(ctx))
+		if err != nil {
+			message := "Invalid credentials"
+			switch {
+			case errors.Is(err, auth.ErrMissingCredentials):
+				message = "Missing credentials"
+			case errors.Is(err, auth.ErrExpiredCredentials):
+				message = "Credentials have expired"
+			}
+			return status.Error(codes.Unauthenticated, message)
+		}
+
+		return call(domain.ContextWithPrincipal(ctx, principal))
+	}
+}
+
+// credentialFromMetadata extracts a credential from either the authorization
+// bearer token or the x-api-key metadata
+func credentialFromMetadata(ctx context.Context) string {
+	if value := firstMetadata(ctx, metadataAuthorization); value != "" {
+		scheme, token, ok := strings.Cut(value, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return firstMetadata(ctx, metadataAPIKey)
+}
+
+// tenantInterceptor scopes each call to a tenant, chosen as the HTTP API's
+// TenantMiddleware does
+func tenantInterceptor() interceptor {
+	return func(ctx context.Context, method string, call func(context.Context) error) error {
+		if isPublic(method) {
+			return call(ctx)
+		}
+
+		principal, _ := domain.PrincipalFromContext(ctx)
+		tenantID, err := domain.ResolveTenant(principal, firstMetadata(ctx, metadataTenantID))
+		switch {
+		case errors.Is(err, domain.ErrTenantMismatch):
+			return status.Error(codes.PermissionDenied, "Credentials are not valid for the requested tenant")
+		case err != nil:
+			return status.Error(codes.InvalidArgument, "Invalid tenant ID")
+		}
+
+		return call(domain.ContextWithTenant(ctx, tenantID))
+	}
+}
+
+// rateLimitInterceptor applies the same token buckets as the HTTP API's
+// RateLimitMiddleware, so that a caller shares one budget across both
+func rateLimitInterceptor(store ports.RateLimitStore, policy ports.RateLimitPolicy, logger *slog.Logger) interceptor {
+	return rateLimit(store, logger, true, func(ctx context.Context) (string, ports.RateLimits) {
+		if principal, ok := domain.PrincipalFromContext(ctx); ok {
+			return "key:" + principal.ID, policy.LimitsFor(principal.ID)
+		}
+		return "ip:" + peerHost(ctx), policy.Default
+	})
+}
+
+// clientRateLimitInterceptor limits calls per client IP before they are
+// authenticated, as the HTTP API's ClientRateLimitMiddleware does. Its
+// limits are only reported on the calls it rejects, as headers cannot be
+// replaced once set.
+func clientRateLimitInterceptor(store ports.RateLimitStore, policy ports.RateLimitPolicy, logger *slog.Logger) interceptor {
+	limits := policy.Ceiling()
+	return rateLimit(store, logger, false, func(ctx context.Context) (string, ports.RateLimits) {
+		return "client:" + peerHost(ctx), limits
+	})
+}
+
+// rateLimit takes a token from the bucket of the caller identified by
+// identify, rejecting the call when the bucket is empty. The bucket's state
+// is sent in headers when report is set or the call is rejected.
+func rateLimit(store ports.RateLimitStore, logger *slog.Logger, report bool, identify func(context.Context) (string, ports.RateLimits)) interceptor {
+	return func(ctx context.Context, method string, call func(context.Context) error) error {
+		if isPublic(method) {
+			return call(ctx)
+		}
+
+		caller, limits := identify(ctx)
+		class, limit := "write", limits.Write
+		if isRead(method) {
+			class, limit = "read", limits.Read
+		}
+
+		result, err := store.Take(ctx, caller+":"+class, limit)
+		if err != nil {
+			// Fail open: an unavailable limiter should not take the API down
+			logger.WarnContext(ctx, "rate limiter unavailable", "error", err)
+			return call(ctx)
+		}
+
+		if report || !result.Allowed {
+			_ = grpc.SetHeader(ctx, metadata.Pairs(
+				"ratelimit-limit", strconv.Itoa(limit.Burst),
+				"ratelimit-remaining", strconv.Itoa(result.Remaining),
+				"ratelimit-reset", strconv.Itoa(result.ResetSeconds()),
+			))
+		}
+
+		if !result.Allowed {
+			st := status.New(codes.ResourceExhausted, "Rate limit exceeded")
+			retry := time.Duration(result.RetryAfterSeconds()) * time.Second
+			if withRetry, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retry)}); err == nil {
+				st = withRetry
+			}
+			return st.Err()
+		}
+
+		return call(ctx)
+	}
+}
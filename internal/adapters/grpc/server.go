@@ -0,0 +1,120 @@
+// Package grpc serves the task service over gRPC. It shares the core
+// services, authentication, tenancy and rate limits of the HTTP API and
+// reports errors with the matching status codes.
+package grpc
+
+import (
+	"log/slog"
+
+	taskv1 "task-tracking-service/api/task/v1"
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/internal/core/services"
+
+	"go.opentelemetry.io/contrib/instrumentation/google.golang.org/grpc/otelgrpc"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/stats"
+)
+
+// ServerOption customises the server built by NewServer
+type ServerOption func(*serverOptions)
+
+type serverOptions struct {
+	authenticator   auth.Authenticator
+	rateLimitStore  ports.RateLimitStore
+	rateLimitPolicy ports.RateLimitPolicy
+	requestMetrics  *RequestMetrics
+	tracing         bool
+	logger          *slog.Logger
+}
+
+// WithAuthenticator requires every call except reflection to authenticate
+func WithAuthenticator(authenticator auth.Authenticator) ServerOption {
+	return func(o *serverOptions) {
+		o.authenticator = authenticator
+	}
+}
+
+// WithRateLimit limits each caller's call rate using the given store. With
+// the HTTP API's store and policy, callers share one budget across both.
+func WithRateLimit(store ports.RateLimitStore, policy ports.RateLimitPolicy) ServerOption {
+	return func(o *serverOptions) {
+		o.rateLimitStore = store
+		o.rateLimitPolicy = policy
+	}
+}
+
+// WithRequestMetrics records the rate, errors and duration of calls to
+// every method
+func WithRequestMetrics(metrics *RequestMetrics) ServerOption {
+	return func(o *serverOptions) {
+		o.requestMetrics = metrics
+	}
+}
+
+// WithTracing records a span for each call, continuing any trace passed in
+// the call metadata. Spans go to the global tracer provider.
+func WithTracing() ServerOption {
+	return func(o *serverOptions) {
+		o.tracing = true
+	}
+}
+
+// WithLogger sets the logger used for access logs and errors. Without it,
+// slog.Default is used.
+func WithLogger(logger *slog.Logger) ServerOption {
+	return func(o *serverOptions) {
+		o.logger = logger
+	}
+}
+
+// NewServer creates a gRPC server offering taskv1.TaskService and server
+// reflection. Interceptors run in the same order as the HTTP middleware.
+func NewServer(taskService ports.TaskService, watcher *services.TaskWatcher, opts ...ServerOption) *grpc.Server {
+	options := serverOptions{logger: slog.Default()}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
+	chain := []interceptor{requestIDInterceptor(), accessLogInterceptor(options.logger)}
+	if options.requestMetrics != nil {
+		chain = append(chain, metricsInterceptor(options.requestMetrics))
+	}
+	chain = append(chain, statusInterceptor(options.logger), recoverInterceptor())
+	if options.authenticator != nil {
+		if options.rateLimitStore != nil {
+			chain = append(chain, clientRateLimitInterceptor(options.rateLimitStore, options.rateLimitPolicy, options.logger))
+		}
+		chain = append(chain, authInterceptor(options.authenticator))
+	}
+	chain = append(chain, tenantInterceptor())
+	if options.rateLimitStore != nil {
+		chain = append(chain, rateLimitInterceptor(options.rateLimitStore, options.rateLimitPolicy, options.logger))
+	}
+
+	unary := make([]grpc.UnaryServerInterceptor, len(chain))
+	stream := make([]grpc.StreamServerInterceptor, len(chain))
+	for i, interceptor := range chain {
+		unary[i] = interceptor.unary()
+		stream[i] = interceptor.stream()
+	}
+
+	serverOpts := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(unary...),
+		grpc.ChainStreamInterceptor(stream...),
+	}
+	// Tracing comes first so that access log records carry the trace ID
+	if options.tracing {
+		serverOpts = append(serverOpts, grpc.StatsHandler(otelgrpc.NewServerHandler(
+			otelgrpc.WithFilter(func(info *stats.RPCTagInfo) bool {
+				return !isPublic(info.FullMethodName)
+			}),
+		)))
+	}
+
+	server := grpc.NewServer(serverOpts...)
+	taskv1.RegisterTaskServiceServer(server, NewTaskServer(taskService, watcher))
+	reflection.Register(server)
+	return server
+}
@@ -0,0 +1,337 @@
+package grpc
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	taskv1 "task-tracking-service/api/task/v1"
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const testAPIKey = "test-api-key-at-least-32-characters-long"
+
+// newTestClient serves a server built with opts over an in-memory
+// connection and returns a client for it
+func newTestClient(t *testing.T, opts ...ServerOption) taskv1.TaskServiceClient {
+	t.Helper()
+	return newTestClientFor(t, services.NewTaskService(memory.NewTaskRepository(), slog.Default()), opts...)
+}
+
+// newTestClientFor is newTestClient for a server offering tasks
+func newTestClientFor(t *testing.T, tasks ports.TaskService, opts ...ServerOption) taskv1.TaskServiceClient {
+	t.Helper()
+	authenticator := auth.NewAPIKeyAuthenticator([]config.APIKey{{Name: "primary", Key: testAPIKey}})
+	server := NewServer(tasks, services.NewTaskWatcher(tasks, nil, 5*time.Millisecond),
+		append([]ServerOption{WithAuthenticator(authenticator)}, opts...)...)
+
+	listener := bufconn.Listen(1 << 20)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufconn",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return taskv1.NewTaskServiceClient(conn)
+}
+
+func authenticated(ctx context.Context) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, "x-api-key", testAPIKey)
+}
+
+func createTask(t *testing.T, client taskv1.TaskServiceClient, title string) *taskv1.Task {
+	t.Helper()
+	task, err := client.CreateTask(authenticated(context.Background()), &taskv1.CreateTaskRequest{
+		Title:   title,
+		DueDate: timestamppb.New(time.Now().Add(time.Hour)),
+	})
+	require.NoError(t, err)
+	return task
+}
+
+func TestServer_Authentication(t *testing.T) {
+	client := newTestClient(t)
+
+	tests := []struct {
+		name        string
+		metadata    []string
+		wantCode    codes.Code
+		wantMessage string
+	}{
+		{name: "accepts bearer token", metadata: []string{"authorization", "Bearer " + testAPIKey}, wantCode: codes.OK},
+		{name: "accepts x-api-key", metadata: []string{"x-api-key", testAPIKey}, wantCode: codes.OK},
+		{name: "rejects missing credentials", wantCode: codes.Unauthenticated, wantMessage: "Missing credentials"},
+		{name: "rejects unknown key", metadata: []string{"x-api-key", "wrong"}, wantCode: codes.Unauthenticated, wantMessage: "Invalid credentials"},
+		{name: "rejects an invalid tenant ID", metadata: []string{"x-api-key", testAPIKey, "x-tenant-id", "not a tenant"}, wantCode: codes.InvalidArgument, wantMessage: "Invalid tenant ID"},
+		{name: "rejects a tenant the key is not bound to", metadata: []string{"x-api-key", testAPIKey, "x-tenant-id", "acme"}, wantCode: codes.PermissionDenied, wantMessage: "Credentials are not valid for the requested tenant"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := metadata.AppendToOutgoingContext(context.Background(), tt.metadata...)
+			_, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{})
+			assert.Equal(t, tt.wantCode, status.Code(err))
+			if tt.wantMessage != "" {
+				assert.Equal(t, tt.wantMessage, status.Convert(err).Message())
+			}
+		})
+	}
+}
+
+func TestServer_Tasks(t *testing.T) {
+	client := newTestClient(t)
+	ctx := authenticated(context.Background())
+
+	t.Run("creates and gets a task", func(t *testing.T) {
+		created := createTask(t, client, "Write docs")
+		assert.Equal(t, taskv1.TaskStatus_TASK_STATUS_PENDING, created.GetStatus())
+		assert.Equal(t, int64(1), created.GetVersion())
+
+		got, err := client.GetTask(ctx, &taskv1.GetTaskRequest{Id: created.GetId()})
+		require.NoError(t, err)
+		assert.Equal(t, "Write docs", got.GetTitle())
+	})
+
+	t.Run("reports invalid fields", func(t *testing.T) {
+		_, err := client.CreateTask(ctx, &taskv1.CreateTaskRequest{})
+		st := status.Convert(err)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+
+		var fields []string
+		for _, detail := range st.Details() {
+			if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+				for _, violation := range badRequest.GetFieldViolations() {
+					fields = append(fields, violation.GetField())
+				}
+			}
+		}
+		assert.ElementsMatch(t, []string{"title", "due_date"}, fields)
+	})
+
+	t.Run("returns the request ID", func(t *testing.T) {
+		var header metadata.MD
+		ctx := metadata.AppendToOutgoingContext(ctx, "x-request-id", "req-123")
+		_, err := client.GetTask(ctx, &taskv1.GetTaskRequest{Id: "missing"}, grpc.Header(&header))
+		assert.Equal(t, codes.NotFound, status.Code(err))
+		assert.Equal(t, []string{"req-123"}, header.Get("x-request-id"))
+		assert.Equal(t, "req-123", requestIDOf(status.Convert(err)))
+	})
+
+	t.Run("pages through tasks", func(t *testing.T) {
+		createTask(t, client, "Second")
+		createTask(t, client, "Third")
+
+		var titles []string
+		token := ""
+		for {
+			resp, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{PageSize: 2, PageToken: token})
+			require.NoError(t, err)
+			for _, task := range resp.GetTasks() {
+				titles = append(titles, task.GetTitle())
+			}
+			if token = resp.GetNextPageToken(); token == "" {
+				break
+			}
+		}
+		assert.ElementsMatch(t, []string{"Write docs", "Second", "Third"}, titles)
+
+		_, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{PageToken: "not a token"})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	})
+
+	t.Run("updates only the masked fields", func(t *testing.T) {
+		task := createTask(t, client, "Original")
+
+		updated, err := client.UpdateTask(ctx, &taskv1.UpdateTaskRequest{
+			Task:       &taskv1.Task{Id: task.GetId(), Title: "Ignored", Status: taskv1.TaskStatus_TASK_STATUS_IN_PROGRESS},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"status"}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, "Original", updated.GetTitle())
+		assert.Equal(t, taskv1.TaskStatus_TASK_STATUS_IN_PROGRESS, updated.GetStatus())
+		assert.Equal(t, int64(2), updated.GetVersion())
+
+		_, err = client.UpdateTask(ctx, &taskv1.UpdateTaskRequest{
+			Task:            &taskv1.Task{Id: task.GetId(), Title: "Stale"},
+			UpdateMask:      &fieldmaskpb.FieldMask{Paths: []string{"title"}},
+			ExpectedVersion: 1,
+		})
+		assert.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+		_, err = client.UpdateTask(ctx, &taskv1.UpdateTaskRequest{
+			Task:       &taskv1.Task{Id: task.GetId()},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"version"}},
+		})
+		assert.Equal(t, codes.InvalidArgument, status.Code(err))
+
+		// Fields are validated by the task service, as for the HTTP API
+		_, err = client.UpdateTask(ctx, &taskv1.UpdateTaskRequest{
+			Task:       &taskv1.Task{Id: task.GetId(), Title: strings.Repeat("x", domain.MaxTitleLength+1)},
+			UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"title", "status"}},
+		})
+		st := status.Convert(err)
+		require.Equal(t, codes.InvalidArgument, st.Code())
+		var fields []string
+		for _, detail := range st.Details() {
+			if badRequest, ok := detail.(*errdetails.BadRequest); ok {
+				for _, violation := range badRequest.GetFieldViolations() {
+					fields = append(fields, violation.GetField())
+				}
+			}
+		}
+		assert.ElementsMatch(t, []string{"title", "status"}, fields)
+	})
+
+	t.Run("moves deleted tasks to the trash", func(t *testing.T) {
+		task := createTask(t, client, "Disposable")
+
+		_, err := client.DeleteTask(ctx, &taskv1.DeleteTaskRequest{Id: task.GetId()})
+		require.NoError(t, err)
+		_, err = client.GetTask(ctx, &taskv1.GetTaskRequest{Id: task.GetId()})
+		assert.Equal(t, codes.NotFound, status.Code(err))
+
+		trashed, err := client.GetDeletedTask(ctx, &taskv1.GetDeletedTaskRequest{Id: task.GetId()})
+		require.NoError(t, err)
+		assert.NotNil(t, trashed.GetDeletedAt())
+
+		restored, err := client.RestoreTask(ctx, &taskv1.RestoreTaskRequest{Id: task.GetId()})
+		require.NoError(t, err)
+		assert.Nil(t, restored.GetDeletedAt())
+	})
+}
+
+func TestServer_ListTasksForProjectScopedCallers(t *testing.T) {
+	inner := services.NewTaskService(memory.NewTaskRepository(), slog.Default())
+	policy := services.NewPolicy([]domain.RoleBinding{
+		{Subject: "apikey:primary", Role: domain.RoleViewer, ProjectID: "proj-1"},
+	}, "")
+	client := newTestClientFor(t, services.NewAuthorizedTaskService(inner, policy, slog.Default()))
+	ctx := authenticated(context.Background())
+
+	// Readable and unreadable tasks alternate, so every page of the
+	// unfiltered listing holds some the caller may not see
+	var readable []string
+	for i := 0; i < 6; i++ {
+		projectID := "proj-2"
+		if i%2 == 0 {
+			projectID = "proj-1"
+		}
+		task, err := inner.CreateTask(context.Background(), domain.CreateTaskInput{
+			ProjectID: projectID, Title: "Task", DueDate: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		if projectID == "proj-1" {
+			readable = append([]string{task.ID}, readable...)
+		}
+	}
+
+	var listed []string
+	token := ""
+	for pages := 0; ; pages++ {
+		require.Less(t, pages, 2)
+		resp, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{PageSize: 2, PageToken: token})
+		require.NoError(t, err)
+		for _, task := range resp.GetTasks() {
+			listed = append(listed, task.GetId())
+		}
+		if token = resp.GetNextPageToken(); token == "" {
+			break
+		}
+	}
+	assert.Equal(t, readable, listed)
+}
+
+func TestServer_WatchTasks(t *testing.T) {
+	client := newTestClient(t)
+	existing := createTask(t, client, "Existing")
+
+	ctx, cancel := context.WithTimeout(authenticated(context.Background()), 5*time.Second)
+	defer cancel()
+	stream, err := client.WatchTasks(ctx, &taskv1.WatchTasksRequest{IncludeExisting: true})
+	require.NoError(t, err)
+
+	event, err := stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, taskv1.TaskEvent_TYPE_CREATED, event.GetType())
+	assert.Equal(t, existing.GetId(), event.GetTask().GetId())
+
+	created := createTask(t, client, "New")
+	event, err = stream.Recv()
+	require.NoError(t, err)
+	assert.Equal(t, taskv1.TaskEvent_TYPE_CREATED, event.GetType())
+	assert.Equal(t, created.GetId(), event.GetTask().GetId())
+
+	t.Run("requires credentials", func(t *testing.T) {
+		unauthenticated, err := client.WatchTasks(context.Background(), &taskv1.WatchTasksRequest{})
+		require.NoError(t, err)
+		_, err = unauthenticated.Recv()
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+}
+
+func TestServer_RateLimit(t *testing.T) {
+	limits := ports.RateLimits{Read: ports.PerMinute(1), Write: ports.PerMinute(1)}
+	client := newTestClient(t, WithRateLimit(memory.NewRateLimitStore(), ports.RateLimitPolicy{Default: limits}))
+	ctx := authenticated(context.Background())
+
+	var header metadata.MD
+	_, err := client.ListTasks(ctx, &taskv1.ListTasksRequest{}, grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"1"}, header.Get("ratelimit-limit"))
+	assert.Equal(t, []string{"0"}, header.Get("ratelimit-remaining"))
+
+	_, err = client.ListTasks(ctx, &taskv1.ListTasksRequest{})
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	var retry *errdetails.RetryInfo
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok {
+			retry = info
+		}
+	}
+	require.NotNil(t, retry)
+	assert.Equal(t, time.Minute, retry.GetRetryDelay().AsDuration())
+
+	// Writes have their own budget
+	createTask(t, client, "Still allowed")
+}
+
+func TestServer_RateLimitsBeforeAuthentication(t *testing.T) {
+	limits := ports.RateLimits{Read: ports.PerMinute(2), Write: ports.PerMinute(2)}
+	client := newTestClient(t, WithRateLimit(memory.NewRateLimitStore(), ports.RateLimitPolicy{Default: limits}))
+	wrongKey := metadata.AppendToOutgoingContext(context.Background(), "x-api-key", "wrong")
+
+	for i := 0; i < 2; i++ {
+		_, err := client.ListTasks(wrongKey, &taskv1.ListTasksRequest{})
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	}
+	_, err := client.ListTasks(wrongKey, &taskv1.ListTasksRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+	_, err = client.ListTasks(authenticated(context.Background()), &taskv1.ListTasksRequest{})
+	assert.Equal(t, codes.ResourceExhausted, status.Code(err))
+}
@@ -0,0 +1,287 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	taskv1 "task-tracking-service/api/task/v1"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/internal/core/services"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const maxPageSize = 1000
+
+// TaskServer serves taskv1.TaskService from the same task service as the
+// HTTP handlers
+type TaskServer struct {
+	taskv1.UnimplementedTaskServiceServer
+	taskService ports.TaskService
+	watcher     *services.TaskWatcher
+}
+
+// NewTaskServer creates a TaskServer. Changes reported by WatchTasks are
+// found by watcher, which should poll the same task service.
+func NewTaskServer(taskService ports.TaskService, watcher *services.TaskWatcher) *TaskServer {
+	return &TaskServer{taskService: taskService, watcher: watcher}
+}
+
+// CreateTask leaves validation to the task service, whose errors map to
+// the same statuses and field violations as in the HTTP API
+func (s *TaskServer) CreateTask(ctx context.Context, req *taskv1.CreateTaskRequest) (*taskv1.Task, error) {
+	task, err := s.taskService.CreateTask(ctx, domain.CreateTaskInput{
+		ProjectID:   req.GetProjectId(),
+		Title:       req.GetTitle(),
+		Description: req.GetDescription(),
+		DueDate:     timeFromProto(req.GetDueDate()),
+	})
+	if err != nil {
+		return nil, err
+	}
+	return taskToProto(task), nil
+}
+
+func (s *TaskServer) GetTask(ctx context.Context, req *taskv1.GetTaskRequest) (*taskv1.Task, error) {
+	task, err := s.taskService.GetTask(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return taskToProto(task), nil
+}
+
+func (s *TaskServer) ListTasks(ctx context.Context, req *taskv1.ListTasksRequest) (*taskv1.ListTasksResponse, error) {
+	pageSize := int(req.GetPageSize())
+	if pageSize < 0 || pageSize > maxPageSize {
+		return nil, customerrors.NewValidationError("Request validation failed",
+			customerrors.FieldError{Field: "page_size", Message: "must be between 0 and 1000"})
+	}
+
+	filter := domain.TaskFilter{IncludeArchived: req.GetIncludeArchived()}
+	if req.GetPageToken() != "" {
+		cursor, err := domain.ParseTaskCursor(req.GetPageToken())
+		if err != nil {
+			return nil, customerrors.NewValidationError("Invalid page token",
+				customerrors.FieldError{Field: "page_token", Message: "is not a valid page token"})
+		}
+		filter.After = cursor
+	}
+	if pageSize > 0 {
+		// One extra task tells whether there is another page
+		filter.Limit = pageSize + 1
+	}
+
+	tasks, err := s.taskService.ListTasks(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &taskv1.ListTasksResponse{}
+	if pageSize > 0 && len(tasks) > pageSize {
+		tasks = tasks[:pageSize]
+		resp.NextPageToken = tasks[len(tasks)-1].Cursor().Token()
+	}
+	resp.Tasks = tasksToProto(tasks)
+	return resp, nil
+}
+
+// UpdateTask changes the fields named by the update mask, like a PATCH of
+// the HTTP API
+func (s *TaskServer) UpdateTask(ctx context.Context, req *taskv1.UpdateTaskRequest) (*taskv1.Task, error) {
+	if req.GetTask() == nil {
+		return nil, customerrors.NewValidationError("Request validation failed",
+			customerrors.FieldError{Field: "task", Message: "is required"})
+	}
+	paths := req.GetUpdateMask().GetPaths()
+	if len(paths) == 0 {
+		paths = []string{"project_id", "title", "description", "status", "due_date"}
+	}
+
+	existing, err := s.taskService.GetTask(ctx, req.GetTask().GetId())
+	if err != nil {
+		return nil, err
+	}
+	version := req.GetExpectedVersion()
+	if version != 0 && version != existing.Version {
+		return nil, customerrors.ErrTaskVersionMismatch
+	}
+
+	updated := *existing
+	var violations []customerrors.FieldError
+	for _, path := range paths {
+		switch path {
+		case "project_id":
+			updated.ProjectID = req.GetTask().GetProjectId()
+		case "title":
+			updated.Title = req.GetTask().GetTitle()
+		case "description":
+			updated.Description = req.GetTask().GetDescription()
+		case "status":
+			updated.Status = statusFromProto(req.GetTask().GetStatus())
+		case "due_date":
+			updated.DueDate = timeFromProto(req.GetTask().GetDueDate())
+		default:
+			violations = append(violations, customerrors.FieldError{Field: "update_mask", Message: "cannot update " + path})
+		}
+	}
+	if len(violations) > 0 {
+		return nil, customerrors.NewValidationError("Request validation failed", violations...)
+	}
+
+	// The update was computed from the version just read, so it must only
+	// be written over that version. Without an expected version, losing
+	// that race is a conflict the client can resolve by retrying.
+	updated.Version = existing.Version
+	task, err := s.taskService.UpdateTask(ctx, &updated)
+	if version == 0 && customerrors.IsPreconditionFailedError(err) {
+		return nil, customerrors.ErrTaskVersionConflict
+	}
+	if err != nil {
+		return nil, err
+	}
+	return taskToProto(task), nil
+}
+
+func (s *TaskServer) DeleteTask(ctx context.Context, req *taskv1.DeleteTaskRequest) (*emptypb.Empty, error) {
+	if err := s.taskService.DeleteTask(ctx, req.GetId(), req.GetExpectedVersion()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *TaskServer) ListDeletedTasks(ctx context.Context, _ *taskv1.ListDeletedTasksRequest) (*taskv1.ListDeletedTasksResponse, error) {
+	tasks, err := s.taskService.ListDeletedTasks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return &taskv1.ListDeletedTasksResponse{Tasks: tasksToProto(tasks)}, nil
+}
+
+func (s *TaskServer) GetDeletedTask(ctx context.Context, req *taskv1.GetDeletedTaskRequest) (*taskv1.Task, error) {
+	task, err := s.taskService.GetDeletedTask(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return taskToProto(task), nil
+}
+
+func (s *TaskServer) RestoreTask(ctx context.Context, req *taskv1.RestoreTaskRequest) (*taskv1.Task, error) {
+	task, err := s.taskService.RestoreTask(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return taskToProto(task), nil
+}
+
+func (s *TaskServer) PurgeTask(ctx context.Context, req *taskv1.PurgeTaskRequest) (*emptypb.Empty, error) {
+	if err := s.taskService.PurgeTask(ctx, req.GetId()); err != nil {
+		return nil, err
+	}
+	return &emptypb.Empty{}, nil
+}
+
+func (s *TaskServer) UnarchiveTask(ctx context.Context, req *taskv1.UnarchiveTaskRequest) (*taskv1.Task, error) {
+	task, err := s.taskService.UnarchiveTask(ctx, req.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return taskToProto(task), nil
+}
+
+// WatchTasks streams changes until the client cancels the call
+func (s *TaskServer) WatchTasks(req *taskv1.WatchTasksRequest, stream taskv1.TaskService_WatchTasksServer) error {
+	opts := domain.TaskWatchOptions{
+		IncludeArchived: req.GetIncludeArchived(),
+		IncludeExisting: req.GetIncludeExisting(),
+	}
+	return s.watcher.Watch(stream.Context(), opts, func(event domain.TaskEvent) error {
+		return stream.Send(&taskv1.TaskEvent{
+			Type: eventTypeToProto(event.Type),
+			Task: taskToProto(event.Task),
+		})
+	})
+}
+
+func taskToProto(task *domain.Task) *taskv1.Task {
+	return &taskv1.Task{
+		Id:          task.ID,
+		ProjectId:   task.ProjectID,
+		Title:       task.Title,
+		Description: task.Description,
+		Status:      statusToProto(task.Status),
+		CreatedAt:   timestamppb.New(task.CreatedAt),
+		UpdatedAt:   timestamppb.New(task.UpdatedAt),
+		DueDate:     timestamppb.New(task.DueDate),
+		Version:     task.Version,
+		DeletedAt:   optionalTimestamp(task.DeletedAt),
+		ArchivedAt:  optionalTimestamp(task.ArchivedAt),
+	}
+}
+
+func tasksToProto(tasks []*domain.Task) []*taskv1.Task {
+	result := make([]*taskv1.Task, len(tasks))
+	for i, task := range tasks {
+		result[i] = taskToProto(task)
+	}
+	return result
+}
+
+// timeFromProto converts ts, leaving a missing timestamp as the zero time
+// rather than the Unix epoch
+func timeFromProto(ts *timestamppb.Timestamp) time.Time {
+	if ts == nil {
+		return time.Time{}
+	}
+	return ts.AsTime()
+}
+
+func optionalTimestamp(t *time.Time) *timestamppb.Timestamp {
+	if t == nil {
+		return nil
+	}
+	return timestamppb.New(*t)
+}
+
+func statusToProto(s domain.TaskStatus) taskv1.TaskStatus {
+	switch s {
+	case domain.StatusPending:
+		return taskv1.TaskStatus_TASK_STATUS_PENDING
+	case domain.StatusInProgress:
+		return taskv1.TaskStatus_TASK_STATUS_IN_PROGRESS
+	case domain.StatusCompleted:
+		return taskv1.TaskStatus_TASK_STATUS_COMPLETED
+	default:
+		return taskv1.TaskStatus_TASK_STATUS_UNSPECIFIED
+	}
+}
+
+// statusFromProto returns an invalid status for TASK_STATUS_UNSPECIFIED and
+// unknown values, so that validation rejects them
+func statusFromProto(s taskv1.TaskStatus) domain.TaskStatus {
+	switch s {
+	case taskv1.TaskStatus_TASK_STATUS_PENDING:
+		return domain.StatusPending
+	case taskv1.TaskStatus_TASK_STATUS_IN_PROGRESS:
+		return domain.StatusInProgress
+	case taskv1.TaskStatus_TASK_STATUS_COMPLETED:
+		return domain.StatusCompleted
+	default:
+		return ""
+	}
+}
+
+func eventTypeToProto(t domain.TaskEventType) taskv1.TaskEvent_Type {
+	switch t {
+	case domain.TaskCreated:
+		return taskv1.TaskEvent_TYPE_CREATED
+	case domain.TaskUpdated:
+		return taskv1.TaskEvent_TYPE_UPDATED
+	case domain.TaskRemoved:
+		return taskv1.TaskEvent_TYPE_REMOVED
+	default:
+		return taskv1.TaskEvent_TYPE_UNSPECIFIED
+	}
+}
@@ -0,0 +1,40 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"testing"
+
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/logging"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAccessLogMiddleware(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := logging.New(config.LogConfig{Level: "info", Format: "json"}, &buf)
+	require.NoError(t, err)
+	e := newTestRouter(WithLogger(logger))
+
+	rec := doRequest(e, http.MethodGet, "/api/v1/task/00000000-0000-0000-0000-000000000000", "",
+		echo.HeaderXRequestID, "req-42")
+	require.Equal(t, http.StatusNotFound, rec.Code)
+
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+	require.Len(t, lines, 1)
+	var record map[string]any
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &record))
+
+	assert.Equal(t, "request", record["msg"])
+	assert.Equal(t, "INFO", record["level"])
+	assert.Equal(t, "GET", record["method"])
+	assert.Equal(t, "/api/v1/task/:id", record["route"])
+	assert.Equal(t, float64(http.StatusNotFound), record["status"])
+	assert.Equal(t, "req-42", record["request_id"])
+	assert.Equal(t, "req-42", rec.Header().Get(echo.HeaderXRequestID))
+}
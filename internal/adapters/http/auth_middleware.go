@@ -0,0 +1,72 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+const headerAPIKey = "X-API-Key"
+
+// publicPaths are reachable without credentials so that orchestrators can
+// probe the service and anyone can read the API documentation.
+var publicPaths = map[string]bool{
+	"/healthz":           true,
+	"/readyz":            true,
+	"/api/openapi.yaml":  true,
+	"/api/openapi.json":  true,
+	"/api/docs":          true,
+	"/api/docs/assets/*": true,
+}
+
+// AuthMiddleware rejects requests that do not carry valid credentials and
+// stores the authenticated principal in the request context.
+func AuthMiddleware(authenticator auth.Authenticator) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if publicPaths[c.Path()] {
+				return next(c)
+			}
+
+			req := c.Request()
+			principal, err := authenticator.Authenticate(req.Context(), credentialFromRequest(req))
+			if err != nil {
+				return unauthorized(c, err)
+			}
+
+			c.SetRequest(req.WithContext(domain.ContextWithPrincipal(req.Context(), principal)))
+			return next(c)
+		}
+	}
+}
+
+// credentialFromRequest extracts a credential from either the Authorization
+// bearer token or the X-API-Key header.
+func credentialFromRequest(req *http.Request) string {
+	if header := req.Header.Get(echo.HeaderAuthorization); header != "" {
+		scheme, token, ok := strings.Cut(header, " ")
+		if ok && strings.EqualFold(scheme, "Bearer") {
+			return strings.TrimSpace(token)
+		}
+		return ""
+	}
+	return req.Header.Get(headerAPIKey)
+}
+
+func unauthorized(c echo.Context, err error) error {
+	message := "Invalid credentials"
+	switch {
+	case errors.Is(err, auth.ErrMissingCredentials):
+		message = "Missing credentials"
+	case errors.Is(err, auth.ErrExpiredCredentials):
+		message = "Credentials have expired"
+	}
+
+	c.Response().Header().Set(echo.HeaderWWWAuthenticate, `Bearer realm="task-tracking-service"`)
+	return echo.NewHTTPError(http.StatusUnauthorized, message)
+}
@@ -0,0 +1,111 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testAPIKey = "test-api-key-at-least-32-characters-long"
+
+func newAuthTestServer() *echo.Echo {
+	authenticator := auth.NewAPIKeyAuthenticator([]config.APIKey{{Name: "primary", Key: testAPIKey}})
+
+	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler(slog.Default())
+	e.Use(AuthMiddleware(authenticator))
+	e.GET("/healthz", func(c echo.Context) error {
+		return c.NoContent(http.StatusOK)
+	})
+	e.GET("/api/v1/task", func(c echo.Context) error {
+		principal, ok := domain.PrincipalFromContext(c.Request().Context())
+		if !ok {
+			return c.NoContent(http.StatusInternalServerError)
+		}
+		return c.String(http.StatusOK, principal.ID)
+	})
+	return e
+}
+
+func TestAuthMiddleware(t *testing.T) {
+	e := newAuthTestServer()
+
+	tests := []struct {
+		name           string
+		path           string
+		headers        map[string]string
+		expectedStatus int
+		expectedBody   string
+	}{
+		{
+			name:           "accepts bearer token",
+			path:           "/api/v1/task",
+			headers:        map[string]string{"Authorization": "Bearer " + testAPIKey},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "apikey:primary",
+		},
+		{
+			name:           "accepts X-API-Key header",
+			path:           "/api/v1/task",
+			headers:        map[string]string{"X-API-Key": testAPIKey},
+			expectedStatus: http.StatusOK,
+			expectedBody:   "apikey:primary",
+		},
+		{
+			name:           "rejects missing credentials",
+			path:           "/api/v1/task",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects wrong key",
+			path:           "/api/v1/task",
+			headers:        map[string]string{"X-API-Key": "wrong-key"},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "rejects non-bearer authorization",
+			path:           "/api/v1/task",
+			headers:        map[string]string{"Authorization": "Basic " + testAPIKey},
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "health endpoints are public",
+			path:           "/healthz",
+			expectedStatus: http.StatusOK,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, tt.path, nil)
+			for k, v := range tt.headers {
+				req.Header.Set(k, v)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedBody != "" {
+				assert.Equal(t, tt.expectedBody, rec.Body.String())
+			}
+			if tt.expectedStatus == http.StatusUnauthorized {
+				var body ErrorResponse
+				require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+				assert.Equal(t, http.StatusUnauthorized, body.Code)
+				assert.NotEmpty(t, body.Message)
+				assert.NotEmpty(t, rec.Header().Get(echo.HeaderWWWAuthenticate))
+			}
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package http
+
+import (
+	"net/http"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/services"
+
+	"github.com/labstack/echo/v4"
+)
+
+type AuthorizationHandler struct {
+	policy *services.Policy
+}
+
+func NewAuthorizationHandler(policy *services.Policy) *AuthorizationHandler {
+	return &AuthorizationHandler{
+		policy: policy,
+	}
+}
+
+// GetPermissions reports the caller's effective roles and permissions,
+// optionally within the project named by the project_id query parameter.
+func (h *AuthorizationHandler) GetPermissions(c echo.Context) error {
+	principal, ok := domain.PrincipalFromContext(c.Request().Context())
+	if !ok {
+		return echo.NewHTTPError(http.StatusUnauthorized, "Missing credentials")
+	}
+
+	return c.JSON(http.StatusOK, h.policy.EffectivePermissions(principal, c.QueryParam("project_id")))
+}
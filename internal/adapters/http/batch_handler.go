@@ -0,0 +1,181 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/internal/core/services"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	batchModeAtomic     = "atomic"
+	batchModeBestEffort = "best_effort"
+)
+
+type BatchHandler struct {
+	batchService ports.TaskBatchService
+	logger       *slog.Logger
+}
+
+func NewBatchHandler(batchService ports.TaskBatchService, logger *slog.Logger) *BatchHandler {
+	return &BatchHandler{
+		batchService: batchService,
+		logger:       logger,
+	}
+}
+
+// BatchRequest lists operations to run in order. Mode defaults to atomic.
+type BatchRequest struct {
+	Mode       string                  `json:"mode" validate:"omitempty,oneof=atomic best_effort"`
+	Operations []BatchOperationRequest `json:"operations" validate:"required"`
+}
+
+// BatchOperationRequest is one create, update or delete. Creates carry the
+// new task, updates a JSON Merge Patch, and updates and deletes may carry
+// the version they expect the task to have.
+type BatchOperationRequest struct {
+	Op      domain.BatchAction `json:"op"`
+	ID      string             `json:"id"`
+	Version int64              `json:"version"`
+	Task    *CreateTaskRequest `json:"task"`
+	Patch   json.RawMessage    `json:"patch"`
+}
+
+// BatchItemResult reports the outcome of one operation using the status
+// code and body the equivalent single request would have produced.
+type BatchItemResult struct {
+	Index  int            `json:"index"`
+	Op     string         `json:"op"`
+	Status int            `json:"status"`
+	Task   *domain.Task   `json:"task,omitempty"`
+	Error  *ErrorResponse `json:"error,omitempty"`
+}
+
+type BatchResponse struct {
+	Results []BatchItemResult `json:"results"`
+}
+
+// ExecuteBatch runs a batch of task operations. The response is 200 with a
+// result per operation whenever the batch itself is well formed.
+func (h *BatchHandler) ExecuteBatch(c echo.Context) error {
+	var req BatchRequest
+	if err := c.Bind(&req); err != nil {
+		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	operations := make([]domain.BatchOperation, len(req.Operations))
+	for i, op := range req.Operations {
+		operations[i] = h.operation(c, op)
+	}
+
+	results, err := h.batchService.ExecuteBatch(c.Request().Context(), operations, req.Mode != batchModeBestEffort)
+	if err != nil {
+		return err
+	}
+
+	response := BatchResponse{Results: make([]BatchItemResult, len(results))}
+	for i, result := range results {
+		response.Results[i] = h.batchItemResult(c, i, req.Operations[i].Op, result)
+	}
+	return c.JSON(http.StatusOK, response)
+}
+
+// operation converts a request into a batch operation. Invalid requests
+// become operations that fail with the validation error.
+func (h *BatchHandler) operation(c echo.Context, op BatchOperationRequest) domain.BatchOperation {
+	operation := domain.BatchOperation{Action: op.Op, ID: op.ID, Version: op.Version}
+	invalid := func(err error) domain.BatchOperation {
+		operation.Err = err
+		return operation
+	}
+
+	switch op.Op {
+	case domain.BatchCreate:
+		if op.Task == nil {
+			return invalid(fieldError("task", "is required for create operations"))
+		}
+		if err := c.Validate(op.Task); err != nil {
+			return invalid(err)
+		}
+		operation.Create = domain.CreateTaskInput{
+			ProjectID:   op.Task.ProjectID,
+			Title:       op.Task.Title,
+			Description: op.Task.Description,
+			DueDate:     op.Task.DueDate,
+		}
+
+	case domain.BatchUpdate:
+		if op.ID == "" {
+			return invalid(fieldError("id", "is required for update operations"))
+		}
+		if len(op.Patch) == 0 {
+			return invalid(fieldError("patch", "is required for update operations"))
+		}
+		operation.Update = func(current *domain.Task) (*domain.Task, error) {
+			patched, err := applyTaskPatch(current, MIMEMergePatch, op.Patch)
+			if err != nil {
+				return nil, err
+			}
+			err = c.Validate(&UpdateTaskRequest{
+				ProjectID:   patched.ProjectID,
+				Title:       patched.Title,
+				Description: patched.Description,
+				Status:      patched.Status,
+				DueDate:     patched.DueDate,
+			})
+			if err != nil {
+				return nil, err
+			}
+			return patched, nil
+		}
+
+	case domain.BatchDelete:
+		if op.ID == "" {
+			return invalid(fieldError("id", "is required for delete operations"))
+		}
+
+	default:
+		return invalid(fieldError("op", "must be one of create, update, delete"))
+	}
+
+	return operation
+}
+
+func fieldError(field, message string) error {
+	return customerrors.NewValidationError("Invalid operation", customerrors.FieldError{Field: field, Message: message})
+}
+
+func (h *BatchHandler) batchItemResult(c echo.Context, index int, op domain.BatchAction, result domain.BatchResult) BatchItemResult {
+	item := BatchItemResult{Index: index, Op: string(op), Task: result.Task}
+
+	switch {
+	case result.Err == nil && op == domain.BatchCreate:
+		item.Status = http.StatusCreated
+	case result.Err == nil && op == domain.BatchDelete:
+		item.Status = http.StatusNoContent
+	case result.Err == nil:
+		item.Status = http.StatusOK
+	case errors.Is(result.Err, services.ErrOperationRolledBack):
+		item.Status = http.StatusFailedDependency
+		item.Error = &ErrorResponse{Code: item.Status, Message: result.Err.Error()}
+	default:
+		response := errorResponse(result.Err)
+		if response.Code == http.StatusInternalServerError {
+			h.logger.ErrorContext(c.Request().Context(), "batch operation failed",
+				"index", index, "error", result.Err)
+		}
+		item.Status = response.Code
+		item.Error = &response
+	}
+	return item
+}
@@ -0,0 +1,109 @@
+package http
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"testing"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBatchTestRouter(maxSize int) *echo.Echo {
+	repo := memory.NewTaskRepository()
+	taskService := services.NewTaskService(repo, slog.Default())
+	batchHandler := NewBatchHandler(services.NewBatchService(taskService, repo, maxSize), slog.Default())
+	return NewRouter(NewTaskHandler(taskService, slog.Default()), WithBatchHandler(batchHandler))
+}
+
+func postBatch(t *testing.T, e *echo.Echo, body string) ([]BatchItemResult, int) {
+	t.Helper()
+	rec := doRequest(e, http.MethodPost, "/api/v1/task:batch", body)
+	if rec.Code != http.StatusOK {
+		return nil, rec.Code
+	}
+
+	var response BatchResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response))
+	return response.Results, rec.Code
+}
+
+func statuses(results []BatchItemResult) []int {
+	codes := make([]int, len(results))
+	for i, result := range results {
+		codes[i] = result.Status
+	}
+	return codes
+}
+
+func TestBatchHandler_ExecuteBatch(t *testing.T) {
+	t.Run("atomic batch reports per-item results", func(t *testing.T) {
+		e := newBatchTestRouter(10)
+		task := createTestTask(t, e)
+
+		results, code := postBatch(t, e, `{"operations":[
+			{"op":"create","task":{"title":"New","due_date":"2099-01-01T00:00:00Z"}},
+			{"op":"update","id":"`+task.ID+`","patch":{"status":"completed"}},
+			{"op":"delete","id":"`+task.ID+`","version":2}
+		]}`)
+
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int{http.StatusCreated, http.StatusOK, http.StatusNoContent}, statuses(results))
+		assert.Equal(t, "New", results[0].Task.Title)
+		assert.Equal(t, domain.StatusCompleted, results[1].Task.Status)
+
+		rec := doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "")
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("atomic batch applies nothing when an item fails", func(t *testing.T) {
+		e := newBatchTestRouter(10)
+		task := createTestTask(t, e)
+
+		results, code := postBatch(t, e, `{"mode":"atomic","operations":[
+			{"op":"update","id":"`+task.ID+`","patch":{"status":"in_progress"}},
+			{"op":"update","id":"`+task.ID+`","patch":{"status":"archived"}}
+		]}`)
+
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int{http.StatusFailedDependency, http.StatusBadRequest}, statuses(results))
+		assert.Equal(t, "status", results[1].Error.Details[0].Field)
+
+		rec := doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "")
+		assert.Contains(t, rec.Body.String(), `"status":"pending"`)
+	})
+
+	t.Run("best-effort batch applies what it can", func(t *testing.T) {
+		e := newBatchTestRouter(10)
+		task := createTestTask(t, e)
+
+		results, code := postBatch(t, e, `{"mode":"best_effort","operations":[
+			{"op":"create","task":{"title":""}},
+			{"op":"update","id":"`+task.ID+`","version":1,"patch":{"status":"completed"}},
+			{"op":"delete","id":"`+task.ID+`","version":1},
+			{"op":"archive","id":"`+task.ID+`"}
+		]}`)
+
+		require.Equal(t, http.StatusOK, code)
+		assert.Equal(t, []int{http.StatusBadRequest, http.StatusOK, http.StatusPreconditionFailed, http.StatusBadRequest}, statuses(results))
+	})
+
+	t.Run("rejects malformed batches outright", func(t *testing.T) {
+		e := newBatchTestRouter(2)
+
+		_, code := postBatch(t, e, `{"operations":[]}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		_, code = postBatch(t, e, `{"operations":[{"op":"delete","id":"a"},{"op":"delete","id":"b"},{"op":"delete","id":"c"}]}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+
+		_, code = postBatch(t, e, `{"mode":"sometimes","operations":[{"op":"delete","id":"a"}]}`)
+		assert.Equal(t, http.StatusBadRequest, code)
+	})
+}
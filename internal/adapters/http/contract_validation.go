@@ -0,0 +1,258 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"task-tracking-service/internal/core/domain"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/gorillamux"
+	"github.com/labstack/echo/v4"
+)
+
+func init() {
+	// kin-openapi knows neither merge patches nor the HTML, CSS and
+	// JavaScript of the docs, and leaves the uuid format unchecked unless
+	// asked to
+	openapi3filter.RegisterBodyDecoder(MIMEMergePatch, openapi3filter.RegisteredBodyDecoder(echo.MIMEApplicationJSON))
+	for _, contentType := range []string{echo.MIMETextHTML, "text/css", "text/javascript"} {
+		openapi3filter.RegisterBodyDecoder(contentType, openapi3filter.RegisteredBodyDecoder(echo.MIMETextPlain))
+	}
+	openapi3.DefineStringFormatValidator("uuid", openapi3.NewRegexpFormatValidator(openapi3.FormatOfStringForUUIDOfRFC4122))
+}
+
+// ContractValidator checks requests and responses against the OpenAPI
+// description of the service, so that the two cannot drift apart unnoticed.
+// Routes the description does not cover are left alone.
+type ContractValidator struct {
+	router  routers.Router
+	options *openapi3filter.Options
+	logger  *slog.Logger
+}
+
+// NewContractValidator loads the OpenAPI description in spec
+func NewContractValidator(spec []byte, logger *slog.Logger) (*ContractValidator, error) {
+	loader := openapi3.NewLoader()
+	doc, err := loader.LoadFromData(spec)
+	if err != nil {
+		return nil, fmt.Errorf("parsing OpenAPI spec: %w", err)
+	}
+	if err := doc.Validate(loader.Context); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	if err := mountPaths(doc); err != nil {
+		return nil, err
+	}
+	router, err := gorillamux.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("building OpenAPI router: %w", err)
+	}
+
+	return &ContractValidator{
+		router: router,
+		options: &openapi3filter.Options{
+			MultiError: true,
+			// Credentials are checked by AuthMiddleware
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+			// Every status a route returns must be documented
+			IncludeResponseStatus: true,
+		},
+		logger: logger,
+	}, nil
+}
+
+// RequestMiddleware rejects requests that do not match the description
+// with a 400 listing every violation
+func (v *ContractValidator) RequestMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			input, ok := v.findRoute(c.Request())
+			if !ok {
+				return next(c)
+			}
+			if err := openapi3filter.ValidateRequest(c.Request().Context(), input); err != nil {
+				return customerrors.NewValidationError("Request does not match the API contract", contractViolations(err, "")...)
+			}
+			return next(c)
+		}
+	}
+}
+
+// ResponseMiddleware holds back each response until it has been checked
+// against the description. A response that does not match is logged and
+// replaced by a 500, so that drift is noticed rather than shipped.
+func (v *ContractValidator) ResponseMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			input, ok := v.findRoute(c.Request())
+			if !ok {
+				return next(c)
+			}
+
+			response := c.Response()
+			buffer := &bufferedResponse{ResponseWriter: response.Writer}
+			response.Writer = buffer
+			err := next(c)
+			if err != nil {
+				// Write the error response now so that it is checked too.
+				// The error is still returned for the access log; the
+				// error handler skips committed responses.
+				c.Error(err)
+			}
+			response.Writer = buffer.ResponseWriter
+
+			ctx := c.Request().Context()
+			if validationErr := v.validateResponse(ctx, input, buffer); validationErr != nil {
+				v.logger.ErrorContext(ctx, "response does not match the API contract",
+					"method", c.Request().Method,
+					"route", c.Path(),
+					"status", buffer.status,
+					"error", validationErr,
+				)
+				return writeContractViolation(c)
+			}
+
+			if buffer.status != 0 {
+				buffer.ResponseWriter.WriteHeader(buffer.status)
+			}
+			if buffer.body.Len() > 0 {
+				if _, writeErr := buffer.ResponseWriter.Write(buffer.body.Bytes()); writeErr != nil {
+					v.logger.ErrorContext(ctx, "failed to write response", "error", writeErr)
+				}
+			}
+			return err
+		}
+	}
+}
+
+func (v *ContractValidator) findRoute(req *http.Request) (*openapi3filter.RequestValidationInput, bool) {
+	route, pathParams, err := v.router.FindRoute(req)
+	if err != nil {
+		return nil, false
+	}
+	return &openapi3filter.RequestValidationInput{
+		Request:    req,
+		PathParams: pathParams,
+		Route:      route,
+		Options:    v.options,
+	}, true
+}
+
+func (v *ContractValidator) validateResponse(ctx context.Context, input *openapi3filter.RequestValidationInput, buffer *bufferedResponse) error {
+	status := buffer.status
+	if status == 0 {
+		status = http.StatusOK
+	}
+	return openapi3filter.ValidateResponse(ctx, &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: input,
+		Status:                 status,
+		Header:                 buffer.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(buffer.body.Bytes())),
+		Options:                v.options,
+	})
+}
+
+// writeContractViolation replaces a response that broke the contract. The
+// response is already committed as far as Echo is concerned, so it is
+// written to the underlying writer directly.
+func writeContractViolation(c echo.Context) error {
+	response := c.Response()
+	header := response.Header()
+	for name := range header {
+		if name != echo.HeaderXRequestID {
+			header.Del(name)
+		}
+	}
+	header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	response.Status = http.StatusInternalServerError
+	response.Writer.WriteHeader(http.StatusInternalServerError)
+	return json.NewEncoder(response.Writer).Encode(ErrorResponse{
+		Code:      http.StatusInternalServerError,
+		Message:   "Response does not match the API contract",
+		RequestID: domain.RequestIDFromContext(c.Request().Context()),
+	})
+}
+
+// contractViolations lists the problems found in a request, naming each
+// parameter, or each body field by its JSON path
+func contractViolations(err error, field string) []customerrors.FieldError {
+	// A type switch rather than errors.As, because a MultiError would
+	// answer for the first error it holds
+	switch err := err.(type) {
+	case openapi3.MultiError:
+		var violations []customerrors.FieldError
+		for _, err := range err {
+			violations = append(violations, contractViolations(err, field)...)
+		}
+		return violations
+	case *openapi3filter.RequestError:
+		switch {
+		case err.Parameter != nil:
+			field = err.Parameter.Name
+		case err.RequestBody != nil:
+			field = "body"
+		}
+		if err.Err == nil {
+			return []customerrors.FieldError{{Field: field, Message: err.Reason}}
+		}
+		return contractViolations(err.Err, field)
+	case *openapi3.SchemaError:
+		if pointer := err.JSONPointer(); len(pointer) > 0 {
+			field = strings.Join(pointer, ".")
+		}
+		return []customerrors.FieldError{{Field: field, Message: err.Reason}}
+	default:
+		return []customerrors.FieldError{{Field: field, Message: err.Error()}}
+	}
+}
+
+// mountPaths prefixes each path with the path of its server and drops the
+// servers, so that routes match on whatever host the service runs
+func mountPaths(doc *openapi3.T) error {
+	paths := openapi3.NewPaths()
+	for path, item := range doc.Paths.Map() {
+		servers := doc.Servers
+		if len(item.Servers) > 0 {
+			servers = item.Servers
+		}
+		base := ""
+		if len(servers) > 0 {
+			serverURL, err := url.Parse(servers[0].URL)
+			if err != nil {
+				return fmt.Errorf("invalid server URL for %s: %w", path, err)
+			}
+			base = strings.TrimSuffix(serverURL.Path, "/")
+		}
+		item.Servers = nil
+		paths.Set(base+path, item)
+	}
+	doc.Paths = paths
+	doc.Servers = nil
+	return nil
+}
+
+// bufferedResponse holds back the status and body written to it
+type bufferedResponse struct {
+	http.ResponseWriter
+	status int
+	body   bytes.Buffer
+}
+
+func (r *bufferedResponse) WriteHeader(status int) {
+	r.status = status
+}
+
+func (r *bufferedResponse) Write(b []byte) (int, error) {
+	return r.body.Write(b)
+}
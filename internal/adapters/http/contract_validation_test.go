@@ -0,0 +1,385 @@
+package http
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"task-tracking-service/api"
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/internal/core/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	contractViewerKey  = "contract-viewer-key-at-least-32-characters"
+	contractLimitedKey = "contract-limited-key-at-least-32-characters"
+	missingTaskID      = "00000000-0000-0000-0000-000000000000"
+)
+
+// contractTestServer is the service with every optional route enabled and
+// contract validation switched on
+type contractTestServer struct {
+	echo      *echo.Echo
+	repo      *memory.TaskRepository
+	validator *ContractValidator
+	logs      *bytes.Buffer
+}
+
+func newContractTestServer(t *testing.T) *contractTestServer {
+	t.Helper()
+	logs := &bytes.Buffer{}
+	logger := slog.New(slog.NewTextHandler(logs, nil))
+
+	validator, err := NewContractValidator(api.Spec, logger)
+	require.NoError(t, err)
+	docsHandler, err := NewDocsHandler(api.Spec, "http://example.com")
+	require.NoError(t, err)
+
+	authenticator := auth.NewAPIKeyAuthenticator([]config.APIKey{
+		{Name: "primary", Key: testAPIKey},
+		{Name: "viewer", Key: contractViewerKey},
+		{Name: "limited", Key: contractLimitedKey},
+	})
+	policy := services.NewPolicy([]domain.RoleBinding{
+		{Subject: "apikey:primary", Role: domain.RoleAdmin},
+		{Subject: "apikey:viewer", Role: domain.RoleViewer},
+		{Subject: "apikey:limited", Role: domain.RoleViewer},
+	}, "")
+	rateLimits := ports.RateLimitPolicy{
+		Default: ports.RateLimits{Read: ports.PerMinute(1000), Write: ports.PerMinute(1000)},
+		PerKey:  map[string]ports.RateLimits{"apikey:limited": {Read: ports.PerMinute(1), Write: ports.PerMinute(1)}},
+	}
+
+	repo := memory.NewTaskRepository()
+	taskService := services.NewAuthorizedTaskService(services.NewTaskService(repo, slog.Default()), policy, logger)
+	reg := prometheus.NewRegistry()
+	metrics, err := NewRequestMetrics(reg)
+	require.NoError(t, err)
+	graphQLHandler, err := NewGraphQLHandler(taskService, GraphQLLimits{MaxDepth: 10, MaxComplexity: 1000}, logger)
+	require.NoError(t, err)
+
+	e := NewRouter(NewTaskHandler(taskService, slog.Default()),
+		WithLogger(logger),
+		WithContractValidation(validator),
+		WithAuthenticator(authenticator),
+		WithAuthorizationHandler(NewAuthorizationHandler(policy)),
+		WithBatchHandler(NewBatchHandler(services.NewBatchService(taskService, repo, 10), logger)),
+		WithRateLimit(memory.NewRateLimitStore(), rateLimits),
+		WithIdempotency(memory.NewIdempotencyStore(), time.Hour),
+		WithRequestMetrics(metrics),
+		WithMetricsHandler(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})),
+		WithDocsHandler(docsHandler),
+		WithGraphQLHandler(graphQLHandler),
+	)
+	return &contractTestServer{echo: e, repo: repo, validator: validator, logs: logs}
+}
+
+// do sends a request as the primary key unless other credentials are given
+func (s *contractTestServer) do(method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	return doRequest(s.echo, method, path, body, append([]string{headerAPIKey, testAPIKey}, headers...)...)
+}
+
+// echoParam matches the path parameters of Echo routes
+var echoParam = regexp.MustCompile(`/:[a-z_]+`)
+
+func TestContract_EveryRouteIsDocumented(t *testing.T) {
+	s := newContractTestServer(t)
+
+	for _, route := range s.echo.Routes() {
+		path := echoParam.ReplaceAllString(route.Path, "/"+missingTaskID)
+		path = strings.ReplaceAll(path, `\:`, ":")
+
+		_, ok := s.validator.findRoute(httptest.NewRequest(route.Method, path, nil))
+		assert.True(t, ok, "%s %s is not in api/openapi.yaml", route.Method, route.Path)
+	}
+}
+
+// TestContract_Responses exercises every route and checks each response
+// against the spec. Responses that do not match are turned into 500s by
+// the validator, so any drift shows up as an unexpected status.
+func TestContract_Responses(t *testing.T) {
+	s := newContractTestServer(t)
+
+	var task domain.Task
+	decodeTask := func(t *testing.T, rec *httptest.ResponseRecorder) {
+		t.Helper()
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &task))
+	}
+	taskPath := func() string { return "/api/v1/task/" + task.ID }
+
+	steps := []struct {
+		name     string
+		request  func() *httptest.ResponseRecorder
+		expected int
+		then     func(t *testing.T, rec *httptest.ResponseRecorder)
+	}{
+		// Probes, metrics and docs
+		{name: "liveness", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/healthz", "") }},
+		{name: "readiness", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/readyz", "") }},
+		{name: "metrics", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/metrics", "") }},
+		{name: "metrics without credentials", expected: http.StatusUnauthorized,
+			request: func() *httptest.ResponseRecorder { return doRequest(s.echo, http.MethodGet, "/metrics", "") }},
+		{name: "spec as YAML", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/openapi.yaml", "") }},
+		{name: "spec as JSON", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/openapi.json", "") }},
+		{name: "Swagger UI", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/docs", "") }},
+		{name: "Swagger UI stylesheet", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/docs/assets/swagger-ui.css", "") }},
+		{name: "Swagger UI script", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodGet, "/api/docs/assets/swagger-ui-bundle.js", "")
+			}},
+		{name: "missing Swagger UI asset", expected: http.StatusNotFound,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/docs/assets/missing.js", "") }},
+		{name: "permissions", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/v1/permissions", "") }},
+
+		// Creating and reading tasks
+		{name: "create", expected: http.StatusCreated,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPost, "/api/v1/task",
+					`{"title":"Write docs","description":"API reference","due_date":"2099-01-01T00:00:00Z"}`)
+			},
+			then: decodeTask},
+		{name: "create without a due date", expected: http.StatusBadRequest,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPost, "/api/v1/task", `{"title":"Write docs"}`)
+			}},
+		{name: "create replayed for an idempotency key", expected: http.StatusCreated,
+			request: func() *httptest.ResponseRecorder {
+				body := `{"title":"Retried","due_date":"2099-01-01T00:00:00Z"}`
+				s.do(http.MethodPost, "/api/v1/task", body, headerIdempotencyKey, "contract-1")
+				return s.do(http.MethodPost, "/api/v1/task", body, headerIdempotencyKey, "contract-1")
+			}},
+		{name: "create with a reused idempotency key", expected: http.StatusUnprocessableEntity,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPost, "/api/v1/task", `{"title":"Other","due_date":"2099-01-01T00:00:00Z"}`,
+					headerIdempotencyKey, "contract-1")
+			}},
+		{name: "create without permission", expected: http.StatusForbidden,
+			request: func() *httptest.ResponseRecorder {
+				return doRequest(s.echo, http.MethodPost, "/api/v1/task", `{"title":"x","due_date":"2099-01-01T00:00:00Z"}`,
+					headerAPIKey, contractViewerKey)
+			}},
+		{name: "list", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodGet, "/api/v1/task?include_archived=true", "")
+			}},
+		{name: "list with an invalid flag", expected: http.StatusBadRequest,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodGet, "/api/v1/task?include_archived=maybe", "")
+			}},
+		{name: "list without credentials", expected: http.StatusUnauthorized,
+			request: func() *httptest.ResponseRecorder { return doRequest(s.echo, http.MethodGet, "/api/v1/task", "") }},
+		{name: "list for an invalid tenant", expected: http.StatusBadRequest,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodGet, "/api/v1/task", "", headerTenantID, "-bad-")
+			}},
+		{name: "get", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, taskPath(), "") }},
+		{name: "get unchanged", expected: http.StatusNotModified,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodGet, taskPath(), "", headerIfNoneMatch, `"1"`)
+			}},
+		{name: "get missing", expected: http.StatusNotFound,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/v1/task/"+missingTaskID, "") }},
+		{name: "get with a malformed ID", expected: http.StatusBadRequest,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/v1/task/not-a-uuid", "") }},
+
+		// Changing tasks
+		{name: "update", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPut, taskPath(),
+					`{"title":"Write docs","description":"API reference","status":"in_progress","due_date":"2099-01-01T00:00:00Z"}`)
+			},
+			then: decodeTask},
+		{name: "update with a stale ETag", expected: http.StatusPreconditionFailed,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPut, taskPath(), `{"title":"Write docs","status":"in_progress"}`, headerIfMatch, `"1"`)
+			}},
+		{name: "update with an unknown status", expected: http.StatusBadRequest,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPut, taskPath(), `{"title":"Write docs","status":"archived"}`)
+			}},
+		{name: "merge patch", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPatch, taskPath(), `{"description":"Reference and guides"}`,
+					echo.HeaderContentType, MIMEMergePatch)
+			}},
+		{name: "JSON patch", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPatch, taskPath(), `[{"op":"replace","path":"/title","value":"Write the docs"}]`,
+					echo.HeaderContentType, MIMEJSONPatch)
+			}},
+		{name: "JSON patch with a failing test", expected: http.StatusConflict,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPatch, taskPath(), `[{"op":"test","path":"/status","value":"pending"}]`,
+					echo.HeaderContentType, MIMEJSONPatch)
+			}},
+		{name: "delete without permission", expected: http.StatusForbidden,
+			request: func() *httptest.ResponseRecorder {
+				return doRequest(s.echo, http.MethodDelete, taskPath(), "", headerAPIKey, contractViewerKey)
+			}},
+
+		// The trash
+		{name: "delete", expected: http.StatusNoContent,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodDelete, taskPath(), "") }},
+		{name: "delete missing", expected: http.StatusNotFound,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodDelete, taskPath(), "") }},
+		{name: "list trash", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/v1/task/trash", "") }},
+		{name: "get from trash", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, "/api/v1/task/trash/"+task.ID, "") }},
+		{name: "restore", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodPost, taskPath()+"/restore", "") },
+			then:    decodeTask},
+		{name: "restore missing", expected: http.StatusNotFound,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodPost, taskPath()+"/restore", "") }},
+		{name: "purge", expected: http.StatusNoContent,
+			request: func() *httptest.ResponseRecorder {
+				s.do(http.MethodDelete, taskPath(), "")
+				return s.do(http.MethodDelete, "/api/v1/task/trash/"+task.ID, "")
+			}},
+		{name: "purge missing", expected: http.StatusNotFound,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodDelete, "/api/v1/task/trash/"+task.ID, "") }},
+
+		// The archive
+		{name: "complete a task", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				rec := s.do(http.MethodPost, "/api/v1/task", `{"title":"Ship it","due_date":"2099-01-01T00:00:00Z"}`)
+				decodeTask(t, rec)
+				return s.do(http.MethodPatch, taskPath(), `{"status":"completed"}`, echo.HeaderContentType, MIMEMergePatch)
+			}},
+		{name: "change an archived task", expected: http.StatusConflict,
+			request: func() *httptest.ResponseRecorder {
+				_, err := services.NewTaskArchiver(s.repo, 0, slog.Default()).ArchiveCompleted(context.Background())
+				require.NoError(t, err)
+				return s.do(http.MethodDelete, taskPath(), "")
+			}},
+		{name: "get an archived task", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodGet, taskPath(), "") }},
+		{name: "unarchive", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodPost, taskPath()+"/unarchive", "") }},
+		{name: "unarchive missing", expected: http.StatusNotFound,
+			request: func() *httptest.ResponseRecorder { return s.do(http.MethodPost, taskPath()+"/unarchive", "") }},
+
+		// Batches
+		{name: "batch", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPost, "/api/v1/task:batch", `{"mode":"best_effort","operations":[
+					{"op":"create","task":{"title":"Batched","due_date":"2099-01-01T00:00:00Z"}},
+					{"op":"delete","id":"`+missingTaskID+`","version":1}]}`)
+			}},
+		{name: "empty batch", expected: http.StatusBadRequest,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPost, "/api/v1/task:batch", `{"operations":[]}`)
+			}},
+
+		// GraphQL
+		{name: "GraphQL query", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPost, "/api/graphql",
+					`{"query":"query($id: ID!) { task(id: $id) { id } tasks(first: 2) { nodes { id title } nextPageToken } }",
+					"variables":{"id":"`+missingTaskID+`"}}`)
+			}},
+		{name: "GraphQL query with GET", expected: http.StatusOK,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodGet, "/api/graphql?query="+url.QueryEscape("{ tasks { nodes { id } } }"), "")
+			}},
+		{name: "GraphQL mutation with GET", expected: http.StatusMethodNotAllowed,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodGet, "/api/graphql?query="+url.QueryEscape(`mutation { deleteTask(id: "x") }`), "")
+			}},
+		{name: "invalid GraphQL query", expected: http.StatusBadRequest,
+			request: func() *httptest.ResponseRecorder {
+				return s.do(http.MethodPost, "/api/graphql", `{"query":"{ tasks { unknown } }"}`)
+			}},
+
+		// Rate limits
+		{name: "rate limited", expected: http.StatusTooManyRequests,
+			request: func() *httptest.ResponseRecorder {
+				doRequest(s.echo, http.MethodGet, "/api/v1/task", "", headerAPIKey, contractLimitedKey)
+				return doRequest(s.echo, http.MethodGet, "/api/v1/task", "", headerAPIKey, contractLimitedKey)
+			}},
+	}
+
+	for _, step := range steps {
+		t.Run(step.name, func(t *testing.T) {
+			s.logs.Reset()
+			rec := step.request()
+
+			require.Equal(t, step.expected, rec.Code, "body: %s\nlogs: %s", rec.Body.String(), s.logs.String())
+			assert.NotContains(t, s.logs.String(), "does not match the API contract")
+			if step.then != nil {
+				step.then(t, rec)
+			}
+		})
+	}
+}
+
+func TestContractValidator(t *testing.T) {
+	s := newContractTestServer(t)
+
+	t.Run("invalid requests list every violation", func(t *testing.T) {
+		rec := s.do(http.MethodPost, "/api/v1/task", `{"title":"","due_date":"tomorrow"}`)
+
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		body := decodeError(t, rec)
+		fields := make([]string, 0, len(body.Details))
+		for _, detail := range body.Details {
+			fields = append(fields, detail.Field)
+		}
+		assert.ElementsMatch(t, []string{"title", "due_date"}, fields)
+	})
+
+	t.Run("responses that break the contract become 500s", func(t *testing.T) {
+		e := echo.New()
+		e.HTTPErrorHandler = NewHTTPErrorHandler(slog.Default())
+		e.Use(s.validator.ResponseMiddleware())
+		e.GET("/healthz", func(c echo.Context) error {
+			return c.JSON(http.StatusOK, map[string]string{"status": "fine"})
+		})
+
+		rec := doRequest(e, http.MethodGet, "/healthz", "")
+
+		assert.Equal(t, http.StatusInternalServerError, rec.Code)
+		assert.Equal(t, "Response does not match the API contract", decodeError(t, rec).Message)
+		assert.Contains(t, s.logs.String(), "response does not match the API contract")
+	})
+
+	t.Run("routes outside the contract are left alone", func(t *testing.T) {
+		e := echo.New()
+		e.Use(s.validator.ResponseMiddleware(), s.validator.RequestMiddleware())
+		e.GET("/debug", func(c echo.Context) error {
+			return c.String(http.StatusTeapot, "short and stout")
+		})
+
+		rec := doRequest(e, http.MethodGet, "/debug", "")
+
+		assert.Equal(t, http.StatusTeapot, rec.Code)
+		assert.Equal(t, "short and stout", rec.Body.String())
+	})
+}
@@ -0,0 +1,64 @@
+package http
+
+import (
+	"context"
+	"sync"
+)
+
+// dataLoader batches the lookups made while a GraphQL query resolves. Load
+// only records the key; the first time a result is needed, every key
+// recorded so far is fetched with one call. As sibling fields are resolved
+// before any of their results are used, a list of n tasks costs one fetch
+// rather than n. Results are kept for the rest of the request.
+type dataLoader[K comparable, V any] struct {
+	fetch func(ctx context.Context, keys []K) (map[K]V, error)
+
+	mu      sync.Mutex
+	pending []K
+	results map[K]*loaded[V]
+}
+
+type loaded[V any] struct {
+	value V
+	err   error
+	done  bool
+}
+
+// newDataLoader creates a loader that fetches with fetch. Keys missing
+// from the map fetch returns resolve to the zero value.
+func newDataLoader[K comparable, V any](fetch func(ctx context.Context, keys []K) (map[K]V, error)) *dataLoader[K, V] {
+	return &dataLoader[K, V]{fetch: fetch, results: make(map[K]*loaded[V])}
+}
+
+// Load schedules key to be fetched and returns a thunk yielding its value
+func (l *dataLoader[K, V]) Load(ctx context.Context, key K) func() (V, error) {
+	l.mu.Lock()
+	result, ok := l.results[key]
+	if !ok {
+		result = &loaded[V]{}
+		l.results[key] = result
+		l.pending = append(l.pending, key)
+	}
+	l.mu.Unlock()
+
+	return func() (V, error) {
+		l.mu.Lock()
+		defer l.mu.Unlock()
+		if !result.done {
+			l.dispatch(ctx)
+		}
+		return result.value, result.err
+	}
+}
+
+// dispatch fetches every pending key. The caller holds l.mu.
+func (l *dataLoader[K, V]) dispatch(ctx context.Context) {
+	keys := l.pending
+	l.pending = nil
+
+	values, err := l.fetch(ctx, keys)
+	for _, key := range keys {
+		result := l.results[key]
+		result.value, result.err, result.done = values[key], err, true
+	}
+}
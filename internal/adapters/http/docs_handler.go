@@ -0,0 +1,117 @@
+package http
+
+import (
+	"bytes"
+	"embed"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/labstack/echo/v4"
+	"gopkg.in/yaml.v3"
+)
+
+//go:embed docs.html
+var docsPage []byte
+
+// swaggerUIFiles holds the stylesheet and bundle of swagger-ui-dist 5.18.2,
+// unmodified, so that the docs page loads nothing from third parties
+//
+//go:embed swagger-ui
+var swaggerUIFiles embed.FS
+
+var swaggerUIAssets = echo.StaticDirectoryHandler(echo.MustSubFS(swaggerUIFiles, "swagger-ui"), false)
+
+// DocsHandler publishes the OpenAPI description and a Swagger UI page
+// for it
+type DocsHandler struct {
+	yaml []byte
+	json []byte
+}
+
+// NewDocsHandler prepares spec for publishing. The origin of every server
+// URL in it is replaced by baseURL, so that "Try it out" calls reach this
+// deployment.
+func NewDocsHandler(spec []byte, baseURL string) (*DocsHandler, error) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal(spec, &doc); err != nil {
+		return nil, fmt.Errorf("invalid OpenAPI spec: %w", err)
+	}
+	if err := rewriteServers(&doc, strings.TrimSuffix(baseURL, "/")); err != nil {
+		return nil, err
+	}
+
+	var yamlSpec bytes.Buffer
+	encoder := yaml.NewEncoder(&yamlSpec)
+	encoder.SetIndent(2)
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI spec: %w", err)
+	}
+	var decoded any
+	if err := doc.Decode(&decoded); err != nil {
+		return nil, fmt.Errorf("failed to decode OpenAPI spec: %w", err)
+	}
+	jsonSpec, err := json.Marshal(decoded)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode OpenAPI spec as JSON: %w", err)
+	}
+
+	return &DocsHandler{yaml: yamlSpec.Bytes(), json: jsonSpec}, nil
+}
+
+func (h *DocsHandler) GetSpecYAML(c echo.Context) error {
+	return c.Blob(http.StatusOK, "application/yaml", h.yaml)
+}
+
+func (h *DocsHandler) GetSpecJSON(c echo.Context) error {
+	return c.JSONBlob(http.StatusOK, h.json)
+}
+
+func (h *DocsHandler) GetDocs(c echo.Context) error {
+	return c.HTMLBlob(http.StatusOK, docsPage)
+}
+
+// GetDocsAsset serves the Swagger UI files the docs page loads
+func (h *DocsHandler) GetDocsAsset(c echo.Context) error {
+	return swaggerUIAssets(c)
+}
+
+// rewriteServers walks node and points the url of each entry in a servers
+// list at baseURL, keeping its path
+func rewriteServers(node *yaml.Node, baseURL string) error {
+	if node.Kind == yaml.MappingNode {
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, value := node.Content[i], node.Content[i+1]
+			if key.Value == "servers" && value.Kind == yaml.SequenceNode {
+				for _, server := range value.Content {
+					if err := rewriteServerURL(server, baseURL); err != nil {
+						return err
+					}
+				}
+			}
+		}
+	}
+
+	for _, child := range node.Content {
+		if err := rewriteServers(child, baseURL); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func rewriteServerURL(server *yaml.Node, baseURL string) error {
+	for i := 0; i+1 < len(server.Content); i += 2 {
+		if server.Content[i].Value != "url" {
+			continue
+		}
+		u, err := url.Parse(server.Content[i+1].Value)
+		if err != nil {
+			return fmt.Errorf("invalid server URL in OpenAPI spec: %w", err)
+		}
+		server.Content[i+1].Value = baseURL + u.Path
+	}
+	return nil
+}
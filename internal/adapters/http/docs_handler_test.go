@@ -0,0 +1,86 @@
+package http
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"task-tracking-service/api"
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+// specServers decodes the top-level servers and those of /healthz
+type specServers struct {
+	Servers []struct {
+		URL string `json:"url" yaml:"url"`
+	} `json:"servers" yaml:"servers"`
+	Paths struct {
+		Healthz struct {
+			Servers []struct {
+				URL string `json:"url" yaml:"url"`
+			} `json:"servers" yaml:"servers"`
+		} `json:"/healthz" yaml:"/healthz"`
+	} `json:"paths" yaml:"paths"`
+}
+
+func TestDocsHandler(t *testing.T) {
+	docs, err := NewDocsHandler(api.Spec, "https://tasks.example.com/")
+	require.NoError(t, err)
+	// The docs are readable without credentials
+	e := newTestRouter(WithDocsHandler(docs), WithAuthenticator(auth.NewAPIKeyAuthenticator([]config.APIKey{{Name: "primary", Key: testAPIKey}})))
+
+	t.Run("YAML spec points at the configured base URL", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/api/openapi.yaml", "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "application/yaml", rec.Header().Get("Content-Type"))
+		var spec specServers
+		require.NoError(t, yaml.Unmarshal(rec.Body.Bytes(), &spec))
+		assert.Equal(t, "https://tasks.example.com/api/v1", spec.Servers[0].URL)
+		assert.Equal(t, "https://tasks.example.com", spec.Paths.Healthz.Servers[0].URL)
+	})
+
+	t.Run("JSON spec matches the YAML one", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/api/openapi.json", "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		var spec specServers
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &spec))
+		assert.Equal(t, "https://tasks.example.com/api/v1", spec.Servers[0].URL)
+	})
+
+	t.Run("Swagger UI loads the spec", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/api/docs", "")
+
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `url: "openapi.yaml"`)
+		assert.NotContains(t, rec.Body.String(), "https://")
+	})
+
+	t.Run("Swagger UI assets are served locally", func(t *testing.T) {
+		for path, contentType := range map[string]string{
+			"/api/docs/assets/swagger-ui.css":       "text/css",
+			"/api/docs/assets/swagger-ui-bundle.js": "javascript",
+		} {
+			rec := doRequest(e, http.MethodGet, path, "")
+
+			require.Equal(t, http.StatusOK, rec.Code, path)
+			assert.Contains(t, rec.Header().Get("Content-Type"), contentType, path)
+			assert.NotEmpty(t, rec.Body.Bytes(), path)
+		}
+		assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodGet, "/api/docs/assets/missing.js", "").Code)
+	})
+}
+
+func TestDocsHandlerIsOptional(t *testing.T) {
+	e := newTestRouter()
+
+	for _, path := range []string{"/api/openapi.yaml", "/api/openapi.json", "/api/docs", "/api/docs/assets/swagger-ui.css"} {
+		assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodGet, path, "").Code, path)
+	}
+}
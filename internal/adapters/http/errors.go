@@ -0,0 +1,77 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"task-tracking-service/internal/core/domain"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+// ErrorResponse mirrors the Error schema in api/openapi.yaml
+type ErrorResponse struct {
+	Code      int                       `json:"code"`
+	Message   string                    `json:"message"`
+	Details   []customerrors.FieldError `json:"details,omitempty"`
+	RequestID string                    `json:"request_id,omitempty"`
+}
+
+// NewHTTPErrorHandler returns the single place where errors returned by
+// handlers and middleware are turned into responses. Errors from the core
+// are classified by type; anything unrecognised is logged and reported as
+// an internal error without leaking its details.
+func NewHTTPErrorHandler(logger *slog.Logger) echo.HTTPErrorHandler {
+	return func(err error, c echo.Context) {
+		if c.Response().Committed {
+			return
+		}
+
+		ctx := c.Request().Context()
+		response := errorResponse(err)
+		response.RequestID = domain.RequestIDFromContext(ctx)
+		if response.Code == http.StatusInternalServerError {
+			logger.ErrorContext(ctx, "internal error", "error", err)
+		}
+
+		if c.Request().Method == http.MethodHead {
+			err = c.NoContent(response.Code)
+		} else {
+			err = c.JSON(response.Code, response)
+		}
+		if err != nil {
+			logger.ErrorContext(ctx, "failed to write error response", "error", err)
+		}
+	}
+}
+
+func errorResponse(err error) ErrorResponse {
+	var (
+		httpErr         *echo.HTTPError
+		validationErr   *customerrors.ValidationError
+		notFoundErr     *customerrors.NotFoundError
+		conflictErr     *customerrors.ConflictError
+		forbiddenErr    *customerrors.ForbiddenError
+		preconditionErr *customerrors.PreconditionFailedError
+	)
+
+	switch {
+	case errors.As(err, &validationErr):
+		return ErrorResponse{Code: http.StatusBadRequest, Message: validationErr.Error(), Details: validationErr.Fields}
+	case errors.As(err, &notFoundErr):
+		return ErrorResponse{Code: http.StatusNotFound, Message: notFoundErr.Error()}
+	case errors.As(err, &conflictErr):
+		return ErrorResponse{Code: http.StatusConflict, Message: conflictErr.Error()}
+	case errors.As(err, &forbiddenErr):
+		return ErrorResponse{Code: http.StatusForbidden, Message: forbiddenErr.Error()}
+	case errors.As(err, &preconditionErr):
+		return ErrorResponse{Code: http.StatusPreconditionFailed, Message: preconditionErr.Error()}
+	case errors.As(err, &httpErr):
+		return ErrorResponse{Code: httpErr.Code, Message: fmt.Sprint(httpErr.Message)}
+	default:
+		return ErrorResponse{Code: http.StatusInternalServerError, Message: http.StatusText(http.StatusInternalServerError)}
+	}
+}
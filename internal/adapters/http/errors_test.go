@@ -0,0 +1,98 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-tracking-service/internal/core/domain"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHTTPErrorHandler(t *testing.T) {
+	tests := []struct {
+		name            string
+		err             error
+		expectedStatus  int
+		expectedMessage string
+		expectedDetails []customerrors.FieldError
+	}{
+		{
+			name:            "not found",
+			err:             customerrors.ErrTaskNotFound,
+			expectedStatus:  http.StatusNotFound,
+			expectedMessage: "task not found",
+		},
+		{
+			name: "wrapped validation error keeps field details",
+			err: fmt.Errorf("creating task: %w", customerrors.NewValidationError("invalid task",
+				customerrors.FieldError{Field: "title", Message: "is required"},
+				customerrors.FieldError{Field: "due_date", Message: "must not be in the past"},
+			)),
+			expectedStatus:  http.StatusBadRequest,
+			expectedMessage: "invalid task",
+			expectedDetails: []customerrors.FieldError{
+				{Field: "title", Message: "is required"},
+				{Field: "due_date", Message: "must not be in the past"},
+			},
+		},
+		{
+			name:            "conflict",
+			err:             customerrors.NewConflictError("task was modified concurrently"),
+			expectedStatus:  http.StatusConflict,
+			expectedMessage: "task was modified concurrently",
+		},
+		{
+			name:            "forbidden",
+			err:             customerrors.NewForbiddenError("permission task:delete denied"),
+			expectedStatus:  http.StatusForbidden,
+			expectedMessage: "permission task:delete denied",
+		},
+		{
+			name:            "precondition failed",
+			err:             customerrors.NewPreconditionFailedError("version mismatch"),
+			expectedStatus:  http.StatusPreconditionFailed,
+			expectedMessage: "version mismatch",
+		},
+		{
+			name:            "echo HTTP error",
+			err:             echo.NewHTTPError(http.StatusUnauthorized, "Missing credentials"),
+			expectedStatus:  http.StatusUnauthorized,
+			expectedMessage: "Missing credentials",
+		},
+		{
+			name:            "unknown errors do not leak details",
+			err:             errors.New("pq: connection refused"),
+			expectedStatus:  http.StatusInternalServerError,
+			expectedMessage: "Internal Server Error",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			req := httptest.NewRequest(http.MethodGet, "/", nil)
+			req = req.WithContext(domain.ContextWithRequestID(req.Context(), "req-123"))
+			rec := httptest.NewRecorder()
+			c := e.NewContext(req, rec)
+
+			NewHTTPErrorHandler(slog.Default())(tt.err, c)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			var body ErrorResponse
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body))
+			assert.Equal(t, tt.expectedStatus, body.Code)
+			assert.Equal(t, tt.expectedMessage, body.Message)
+			assert.Equal(t, tt.expectedDetails, body.Details)
+			assert.Equal(t, "req-123", body.RequestID)
+		})
+	}
+}
@@ -0,0 +1,61 @@
+package http
+
+import (
+	"strconv"
+	"strings"
+
+	"task-tracking-service/internal/core/domain"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerETag        = "ETag"
+	headerIfMatch     = "If-Match"
+	headerIfNoneMatch = "If-None-Match"
+)
+
+// taskETag is the strong entity tag of a task, derived from its version
+func taskETag(task *domain.Task) string {
+	return `"` + strconv.FormatInt(task.Version, 10) + `"`
+}
+
+// taskJSON writes a single task along with its ETag
+func taskJSON(c echo.Context, code int, task *domain.Task) error {
+	c.Response().Header().Set(headerETag, taskETag(task))
+	return c.JSON(code, task)
+}
+
+// ifMatchVersion returns the task version required by the If-Match header,
+// or zero when the header is absent or "*". If-Match uses strong comparison,
+// so weak or malformed tags can never match and fail the precondition.
+func ifMatchVersion(c echo.Context) (int64, error) {
+	header := strings.TrimSpace(c.Request().Header.Get(headerIfMatch))
+	if header == "" || header == "*" {
+		return 0, nil
+	}
+
+	tag, ok := strings.CutPrefix(header, `"`)
+	if ok {
+		tag, ok = strings.CutSuffix(tag, `"`)
+	}
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if !ok || err != nil || version < 1 {
+		return 0, customerrors.ErrTaskVersionMismatch
+	}
+	return version, nil
+}
+
+// noneMatch reports whether the If-None-Match header lists the given tag.
+// It uses weak comparison, as RFC 9110 requires for If-None-Match.
+func noneMatch(c echo.Context, etag string) bool {
+	header := c.Request().Header.Get(headerIfNoneMatch)
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
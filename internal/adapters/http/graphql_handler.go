@@ -0,0 +1,201 @@
+package http
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+	"github.com/labstack/echo/v4"
+)
+
+// GraphQLHandler serves GraphQL queries and mutations over tasks at
+// /api/graphql
+type GraphQLHandler struct {
+	schema      graphql.Schema
+	taskService ports.TaskService
+	limits      GraphQLLimits
+	logger      *slog.Logger
+}
+
+// GraphQLRequest is the body of a POST to /api/graphql. GET requests carry
+// the same fields as query parameters, with variables encoded as JSON.
+type GraphQLRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+}
+
+// NewGraphQLHandler creates a handler whose resolvers go through
+// taskService. Operations exceeding limits are rejected before they run.
+func NewGraphQLHandler(taskService ports.TaskService, limits GraphQLLimits, logger *slog.Logger) (*GraphQLHandler, error) {
+	schema, err := newGraphQLSchema(taskService)
+	if err != nil {
+		return nil, fmt.Errorf("building GraphQL schema: %w", err)
+	}
+	return &GraphQLHandler{schema: schema, taskService: taskService, limits: limits, logger: logger}, nil
+}
+
+// Serve runs a GraphQL operation. Requests that cannot run at all get a
+// 4xx status; once an operation runs, the response is a 200 whose errors
+// carry the code, details and request ID of the equivalent REST error in
+// their extensions. Mutations are only accepted in POST requests.
+func (h *GraphQLHandler) Serve(c echo.Context) error {
+	req, err := bindGraphQLRequest(c)
+	if err != nil {
+		return graphQLErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	doc, err := parser.Parse(parser.ParseParams{Source: req.Query})
+	if err != nil {
+		return graphQLErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if validation := graphql.ValidateDocument(&h.schema, doc, nil); !validation.IsValid {
+		requestID := domain.RequestIDFromContext(c.Request().Context())
+		for i, validationErr := range validation.Errors {
+			response := ErrorResponse{Code: http.StatusBadRequest, Message: validationErr.Message}
+			validation.Errors[i] = presentedError(validationErr, response, requestID)
+		}
+		return c.JSON(http.StatusBadRequest, &graphql.Result{Errors: validation.Errors})
+	}
+
+	operation, err := selectOperation(doc, req.OperationName)
+	if err != nil {
+		return graphQLErrorResponse(c, http.StatusBadRequest, err)
+	}
+	if operation.Operation != ast.OperationTypeQuery && c.Request().Method != http.MethodPost {
+		c.Response().Header().Set(echo.HeaderAllow, http.MethodPost)
+		return graphQLErrorResponse(c, http.StatusMethodNotAllowed,
+			fmt.Errorf("%s operations must be sent with POST", operation.Operation))
+	}
+	if err := checkLimits(&h.schema, doc, operation, req.Variables, h.limits); err != nil {
+		return graphQLErrorResponse(c, http.StatusBadRequest, err)
+	}
+
+	ctx := contextWithGraphQLLoaders(c.Request().Context(), newGraphQLLoaders(h.taskService))
+	result := graphql.Execute(graphql.ExecuteParams{
+		Schema:        h.schema,
+		AST:           doc,
+		OperationName: req.OperationName,
+		Args:          req.Variables,
+		Context:       ctx,
+	})
+	for i, resultErr := range result.Errors {
+		result.Errors[i] = h.presentError(c, resultErr)
+	}
+	return c.JSON(http.StatusOK, result)
+}
+
+func bindGraphQLRequest(c echo.Context) (GraphQLRequest, error) {
+	var req GraphQLRequest
+	switch c.Request().Method {
+	case http.MethodPost:
+		if err := json.NewDecoder(c.Request().Body).Decode(&req); err != nil {
+			return req, errors.New("Invalid request body")
+		}
+	default:
+		req.Query = c.QueryParam("query")
+		req.OperationName = c.QueryParam("operationName")
+		if variables := c.QueryParam("variables"); variables != "" {
+			if err := json.Unmarshal([]byte(variables), &req.Variables); err != nil {
+				return req, errors.New("Invalid variables")
+			}
+		}
+	}
+	if req.Query == "" {
+		return req, errors.New("A query is required")
+	}
+	return req, nil
+}
+
+// selectOperation picks the operation to run as graphql.Execute will
+func selectOperation(doc *ast.Document, name string) (*ast.OperationDefinition, error) {
+	var selected *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		operation, ok := definition.(*ast.OperationDefinition)
+		if !ok {
+			continue
+		}
+		switch {
+		case name == "" && selected != nil:
+			return nil, errors.New("Must provide operation name if query contains multiple operations.")
+		case name == "" || operation.Name != nil && operation.Name.Value == name:
+			selected = operation
+		}
+	}
+	if selected == nil {
+		return nil, fmt.Errorf("Unknown operation named %q.", name)
+	}
+	return selected, nil
+}
+
+// graphQLErrorResponse rejects a request that cannot run. Errors from the
+// core keep their own status; any other error is reported with code.
+func graphQLErrorResponse(c echo.Context, code int, err error) error {
+	formatted := gqlerrors.FormatError(err)
+	response := errorResponse(err)
+	if response.Code == http.StatusInternalServerError {
+		response = ErrorResponse{Code: code, Message: formatted.Message}
+	}
+	return c.JSON(response.Code, &graphql.Result{Errors: []gqlerrors.FormattedError{
+		presentedError(formatted, response, domain.RequestIDFromContext(c.Request().Context())),
+	}})
+}
+
+// presentError classifies an error raised while an operation ran, as the
+// HTTP error handler does. Errors raised by resolvers without a known
+// cause are logged and reported as internal errors without their details;
+// the others, such as invalid variables, are the caller's mistake.
+func (h *GraphQLHandler) presentError(c echo.Context, err gqlerrors.FormattedError) gqlerrors.FormattedError {
+	ctx := c.Request().Context()
+	cause := graphQLErrorCause(err)
+
+	response := errorResponse(cause)
+	if response.Code == http.StatusInternalServerError && len(err.Path) == 0 {
+		response = ErrorResponse{Code: http.StatusBadRequest, Message: err.Message}
+	}
+	if response.Code == http.StatusInternalServerError {
+		h.logger.ErrorContext(ctx, "internal error", "error", cause)
+	}
+	return presentedError(err, response, domain.RequestIDFromContext(ctx))
+}
+
+// presentedError replaces the message of err with that of response and
+// adds response's other fields as extensions
+func presentedError(err gqlerrors.FormattedError, response ErrorResponse, requestID string) gqlerrors.FormattedError {
+	err.Message = response.Message
+	err.Extensions = map[string]interface{}{"code": response.Code}
+	if len(response.Details) > 0 {
+		err.Extensions["details"] = response.Details
+	}
+	if requestID != "" {
+		err.Extensions["request_id"] = requestID
+	}
+	return err
+}
+
+// graphQLErrorCause digs the error returned by a resolver out of the
+// wrappers the GraphQL executor puts around it
+func graphQLErrorCause(err error) error {
+	for {
+		var next error
+		switch wrapped := err.(type) {
+		case gqlerrors.FormattedError:
+			next = wrapped.OriginalError()
+		case *gqlerrors.Error:
+			next = wrapped.OriginalError
+		}
+		if next == nil {
+			return err
+		}
+		err = next
+	}
+}
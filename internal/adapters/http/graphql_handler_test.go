@@ -0,0 +1,284 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync/atomic"
+	"testing"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/internal/core/services"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// countingTaskService counts the lookups that reach the service
+type countingTaskService struct {
+	ports.TaskService
+	gets  atomic.Int32
+	lists atomic.Int32
+}
+
+func (s *countingTaskService) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	s.gets.Add(1)
+	return s.TaskService.GetTask(ctx, id)
+}
+
+func (s *countingTaskService) ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	s.lists.Add(1)
+	return s.TaskService.ListTasks(ctx, filter)
+}
+
+type graphQLTestResponse struct {
+	Data   map[string]json.RawMessage `json:"data"`
+	Errors []struct {
+		Message    string        `json:"message"`
+		Path       []interface{} `json:"path"`
+		Extensions struct {
+			Code      int                       `json:"code"`
+			Details   []customerrors.FieldError `json:"details"`
+			RequestID string                    `json:"request_id"`
+		} `json:"extensions"`
+	} `json:"errors"`
+}
+
+func newGraphQLTestRouter(t *testing.T, limits GraphQLLimits) (*echo.Echo, *countingTaskService) {
+	t.Helper()
+	taskService := &countingTaskService{TaskService: services.NewTaskService(memory.NewTaskRepository(), slog.Default())}
+	handler, err := NewGraphQLHandler(taskService, limits, slog.Default())
+	require.NoError(t, err)
+	return NewRouter(NewTaskHandler(taskService, slog.Default()), WithGraphQLHandler(handler)), taskService
+}
+
+func postGraphQL(t *testing.T, e *echo.Echo, query string, variables map[string]interface{}) (graphQLTestResponse, int) {
+	t.Helper()
+	body, err := json.Marshal(GraphQLRequest{Query: query, Variables: variables})
+	require.NoError(t, err)
+	rec := doRequest(e, http.MethodPost, "/api/graphql", string(body), echo.HeaderXRequestID, "req-graphql")
+	return decodeGraphQL(t, rec), rec.Code
+}
+
+func decodeGraphQL(t *testing.T, rec *httptest.ResponseRecorder) graphQLTestResponse {
+	t.Helper()
+	var response graphQLTestResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &response), rec.Body.String())
+	return response
+}
+
+func createGraphQLTask(t *testing.T, e *echo.Echo, title, projectID string) string {
+	t.Helper()
+	response, code := postGraphQL(t, e, `mutation($input: CreateTaskInput!) { createTask(input: $input) { id } }`,
+		map[string]interface{}{"input": map[string]interface{}{
+			"title": title, "projectId": projectID, "dueDate": "2099-01-01T00:00:00Z",
+		}})
+	require.Equal(t, http.StatusOK, code)
+	require.Empty(t, response.Errors)
+
+	var created struct{ ID string }
+	require.NoError(t, json.Unmarshal(response.Data["createTask"], &created))
+	return created.ID
+}
+
+var defaultTestGraphQLLimits = GraphQLLimits{MaxDepth: 10, MaxComplexity: 1000}
+
+func TestGraphQLHandler_Queries(t *testing.T) {
+	e, _ := newGraphQLTestRouter(t, defaultTestGraphQLLimits)
+	first := createGraphQLTask(t, e, "First", "docs")
+	second := createGraphQLTask(t, e, "Second", "docs")
+	createGraphQLTask(t, e, "Third", "")
+
+	t.Run("gets a task by ID", func(t *testing.T) {
+		response, code := postGraphQL(t, e, `query($id: ID!) { task(id: $id) { title status version project { id } } }`,
+			map[string]interface{}{"id": first})
+		require.Equal(t, http.StatusOK, code)
+		require.Empty(t, response.Errors)
+		assert.JSONEq(t, `{"title":"First","status":"PENDING","version":1,"project":{"id":"docs"}}`,
+			string(response.Data["task"]))
+	})
+
+	t.Run("filters tasks", func(t *testing.T) {
+		response, _ := postGraphQL(t, e, `{ tasks(filter: {projectId: "docs"}) { nodes { title } } }`, nil)
+		require.Empty(t, response.Errors)
+		assert.JSONEq(t, `{"nodes":[{"title":"Second"},{"title":"First"}]}`, string(response.Data["tasks"]))
+	})
+
+	t.Run("pages through tasks", func(t *testing.T) {
+		var titles []string
+		after := ""
+		for {
+			response, _ := postGraphQL(t, e, `query($after: String) { tasks(first: 2, after: $after) { nodes { title } nextPageToken } }`,
+				map[string]interface{}{"after": after})
+			require.Empty(t, response.Errors)
+
+			var page struct {
+				Nodes         []struct{ Title string }
+				NextPageToken *string
+			}
+			require.NoError(t, json.Unmarshal(response.Data["tasks"], &page))
+			for _, node := range page.Nodes {
+				titles = append(titles, node.Title)
+			}
+			if page.NextPageToken == nil {
+				break
+			}
+			after = *page.NextPageToken
+		}
+		assert.Equal(t, []string{"Third", "Second", "First"}, titles)
+	})
+
+	t.Run("follows a project to its tasks", func(t *testing.T) {
+		response, _ := postGraphQL(t, e, `{ project(id: "docs") { tasks { id } } }`, nil)
+		require.Empty(t, response.Errors)
+		assert.JSONEq(t, `{"tasks":[{"id":"`+second+`"},{"id":"`+first+`"}]}`, string(response.Data["project"]))
+	})
+
+	t.Run("filters a project's tasks", func(t *testing.T) {
+		response, _ := postGraphQL(t, e, `{ project(id: "docs") { tasks(first: 1, filter: {status: [PENDING]}) { title } } }`, nil)
+		require.Empty(t, response.Errors)
+		assert.JSONEq(t, `{"tasks":[{"title":"Second"}]}`, string(response.Data["project"]))
+	})
+
+	t.Run("rejects page sizes out of range", func(t *testing.T) {
+		response, _ := postGraphQL(t, e, `{ tasks(first: 0) { nodes { id } } }`, nil)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, http.StatusBadRequest, response.Errors[0].Extensions.Code)
+		assert.Equal(t, "first", response.Errors[0].Extensions.Details[0].Field)
+	})
+
+	t.Run("accepts queries sent with GET", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/api/graphql?query="+url.QueryEscape(`{ tasks { nodes { id } } }`), "")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Empty(t, decodeGraphQL(t, rec).Errors)
+	})
+}
+
+func TestGraphQLHandler_BatchesLookups(t *testing.T) {
+	e, taskService := newGraphQLTestRouter(t, defaultTestGraphQLLimits)
+	ids := []string{
+		createGraphQLTask(t, e, "One", "docs"),
+		createGraphQLTask(t, e, "Two", "docs"),
+		createGraphQLTask(t, e, "Three", "site"),
+	}
+
+	t.Run("fetches several tasks with one call", func(t *testing.T) {
+		taskService.gets.Store(0)
+		taskService.lists.Store(0)
+		response, _ := postGraphQL(t, e, `query($a: ID!, $b: ID!, $c: ID!) {
+			a: task(id: $a) { title } b: task(id: $b) { title } c: task(id: $c) { title }
+		}`, map[string]interface{}{"a": ids[0], "b": ids[1], "c": ids[2]})
+		require.Empty(t, response.Errors)
+		assert.JSONEq(t, `{"title":"Two"}`, string(response.Data["b"]))
+		assert.Equal(t, int32(0), taskService.gets.Load())
+		assert.Equal(t, int32(1), taskService.lists.Load())
+	})
+
+	t.Run("fetches the tasks of every project with one call", func(t *testing.T) {
+		taskService.lists.Store(0)
+		response, _ := postGraphQL(t, e, `{ tasks(first: 3) { nodes { project { tasks(first: 1) { title } } } } }`, nil)
+		require.Empty(t, response.Errors)
+		assert.JSONEq(t, `{"nodes":[
+			{"project":{"tasks":[{"title":"Three"}]}},
+			{"project":{"tasks":[{"title":"Two"}]}},
+			{"project":{"tasks":[{"title":"Two"}]}}
+		]}`, string(response.Data["tasks"]))
+		// One call lists the tasks, one more their projects' tasks
+		assert.Equal(t, int32(2), taskService.lists.Load())
+	})
+}
+
+func TestGraphQLHandler_Limits(t *testing.T) {
+	e, _ := newGraphQLTestRouter(t, GraphQLLimits{MaxDepth: 4, MaxComplexity: 100})
+
+	tests := []struct {
+		name        string
+		query       string
+		wantMessage string
+	}{
+		{
+			name:        "rejects deep queries",
+			query:       `{ tasks { nodes { project { tasks { id } } } } }`,
+			wantMessage: "Query depth 5 exceeds the limit of 4",
+		},
+		{
+			name:        "rejects complex queries",
+			query:       `{ tasks(first: 50) { nodes { id title } } }`,
+			wantMessage: "Query complexity 102 exceeds the limit of 100",
+		},
+		{
+			name:        "costs lists without first at the default page size",
+			query:       `{ tasks { nodes { id } } }`,
+			wantMessage: "Query complexity 102 exceeds the limit of 100",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			response, code := postGraphQL(t, e, tt.query, nil)
+			assert.Equal(t, http.StatusBadRequest, code)
+			require.Len(t, response.Errors, 1)
+			assert.Equal(t, tt.wantMessage, response.Errors[0].Message)
+			assert.Equal(t, http.StatusBadRequest, response.Errors[0].Extensions.Code)
+		})
+	}
+
+	t.Run("allows queries within the limits", func(t *testing.T) {
+		_, code := postGraphQL(t, e, `{ tasks(first: 10) { nodes { id title } } }`, nil)
+		assert.Equal(t, http.StatusOK, code)
+	})
+}
+
+func TestGraphQLHandler_Errors(t *testing.T) {
+	e, _ := newGraphQLTestRouter(t, defaultTestGraphQLLimits)
+	id := createGraphQLTask(t, e, "Existing", "")
+
+	t.Run("reports invalid fields", func(t *testing.T) {
+		response, code := postGraphQL(t, e, `mutation { createTask(input: {title: "", dueDate: "2099-01-01T00:00:00Z"}) { id } }`, nil)
+		assert.Equal(t, http.StatusOK, code)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, http.StatusBadRequest, response.Errors[0].Extensions.Code)
+		require.NotEmpty(t, response.Errors[0].Extensions.Details)
+		assert.Equal(t, "title", response.Errors[0].Extensions.Details[0].Field)
+		assert.Equal(t, "req-graphql", response.Errors[0].Extensions.RequestID)
+	})
+
+	t.Run("reports missing tasks", func(t *testing.T) {
+		response, _ := postGraphQL(t, e, `{ task(id: "missing") { id } }`, nil)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, http.StatusNotFound, response.Errors[0].Extensions.Code)
+		assert.Equal(t, []interface{}{"task"}, response.Errors[0].Path)
+		assert.Equal(t, "null", string(response.Data["task"]))
+	})
+
+	t.Run("reports stale versions", func(t *testing.T) {
+		response, _ := postGraphQL(t, e, `mutation($id: ID!) { updateTask(id: $id, input: {title: "Stale"}, expectedVersion: 7) { id } }`,
+			map[string]interface{}{"id": id})
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, http.StatusPreconditionFailed, response.Errors[0].Extensions.Code)
+	})
+
+	t.Run("rejects invalid queries", func(t *testing.T) {
+		response, code := postGraphQL(t, e, `{ tasks { unknown } }`, nil)
+		assert.Equal(t, http.StatusBadRequest, code)
+		require.Len(t, response.Errors, 1)
+		assert.Equal(t, http.StatusBadRequest, response.Errors[0].Extensions.Code)
+	})
+
+	t.Run("rejects mutations sent with GET", func(t *testing.T) {
+		query := url.QueryEscape(`mutation { deleteTask(id: "` + id + `") }`)
+		rec := doRequest(e, http.MethodGet, "/api/graphql?query="+query, "")
+		assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+		assert.Equal(t, http.MethodPost, rec.Header().Get(echo.HeaderAllow))
+
+		response, _ := postGraphQL(t, e, `query($id: ID!) { task(id: $id) { id } }`, map[string]interface{}{"id": id})
+		assert.Empty(t, response.Errors, "the task was deleted")
+	})
+}
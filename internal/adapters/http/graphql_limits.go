@@ -0,0 +1,186 @@
+package http
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/language/ast"
+)
+
+// List fields return at most first items, or graphQLDefaultPageSize
+// without a first argument, so that no query lists every task at once
+const (
+	graphQLDefaultPageSize = 100
+	graphQLMaxPageSize     = 1000
+
+	pageSizeDescription = "At most this many tasks, up to 1000; 100 by default"
+)
+
+// GraphQLLimits bounds the work a single GraphQL operation may ask for.
+// Both are checked before the operation runs.
+type GraphQLLimits struct {
+	// MaxDepth caps how deeply fields may be nested
+	MaxDepth int
+	// MaxComplexity caps the estimated number of fields resolved. Each
+	// field costs 1, and the fields below a list count once per item it
+	// may return: the first argument of the list or of the connection
+	// holding it or, without one, graphQLDefaultPageSize.
+	MaxComplexity int
+}
+
+// queryCost is the depth and estimated complexity of an operation
+type queryCost struct {
+	depth      int
+	complexity int
+}
+
+// checkLimits rejects an operation that exceeds limits. Introspection
+// fields are free, so that tools can always read the schema.
+func checkLimits(schema *graphql.Schema, doc *ast.Document, operation *ast.OperationDefinition, variables map[string]interface{}, limits GraphQLLimits) error {
+	root := schema.QueryType()
+	if operation.Operation == ast.OperationTypeMutation {
+		root = schema.MutationType()
+	}
+
+	m := costMeasurer{fragments: make(map[string]*ast.FragmentDefinition), variables: variables, schema: schema}
+	for _, definition := range doc.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			m.fragments[fragment.Name.Value] = fragment
+		}
+	}
+	cost := m.selectionSet(operation.SelectionSet, root, 0)
+
+	switch {
+	case cost.depth > limits.MaxDepth:
+		return customerrors.NewValidationError(fmt.Sprintf("Query depth %d exceeds the limit of %d", cost.depth, limits.MaxDepth))
+	case cost.complexity > limits.MaxComplexity:
+		return customerrors.NewValidationError(fmt.Sprintf("Query complexity %d exceeds the limit of %d", cost.complexity, limits.MaxComplexity))
+	}
+	return nil
+}
+
+type costMeasurer struct {
+	schema    *graphql.Schema
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+}
+
+// selectionSet measures the fields selected on parent. A non-zero pageSize
+// is the first argument of parent's field, which sizes the lists below it.
+// Fragments do not add a level of nesting; validation has already ruled out
+// fragment cycles.
+func (m costMeasurer) selectionSet(set *ast.SelectionSet, parent *graphql.Object, pageSize int) queryCost {
+	var total queryCost
+	if set == nil {
+		return total
+	}
+
+	for _, selection := range set.Selections {
+		var cost queryCost
+		switch selection := selection.(type) {
+		case *ast.Field:
+			cost = m.field(selection, parent, pageSize)
+		case *ast.InlineFragment:
+			cost = m.selectionSet(selection.SelectionSet, m.typeCondition(selection.TypeCondition, parent), pageSize)
+		case *ast.FragmentSpread:
+			if fragment, ok := m.fragments[selection.Name.Value]; ok {
+				cost = m.selectionSet(fragment.SelectionSet, m.typeCondition(fragment.TypeCondition, parent), pageSize)
+			}
+		}
+		total.depth = max(total.depth, cost.depth)
+		total.complexity += cost.complexity
+	}
+	return total
+}
+
+func (m costMeasurer) field(field *ast.Field, parent *graphql.Object, pageSize int) queryCost {
+	if strings.HasPrefix(field.Name.Value, "__") {
+		return queryCost{}
+	}
+
+	var (
+		child  *graphql.Object
+		isList bool
+	)
+	if parent != nil {
+		if definition, ok := parent.Fields()[field.Name.Value]; ok {
+			child, isList = unwrapOutput(definition.Type)
+		}
+	}
+
+	// The first argument of a connection sizes the list it holds
+	first := m.first(field)
+	if !isList {
+		sub := m.selectionSet(field.SelectionSet, child, first)
+		return queryCost{depth: 1 + sub.depth, complexity: 1 + sub.complexity}
+	}
+
+	multiplier := graphQLDefaultPageSize
+	switch {
+	case first > 0:
+		multiplier = first
+	case pageSize > 0:
+		multiplier = pageSize
+	}
+	sub := m.selectionSet(field.SelectionSet, child, 0)
+	return queryCost{depth: 1 + sub.depth, complexity: 1 + multiplier*sub.complexity}
+}
+
+// first is the value of field's first argument, or 0 without one
+func (m costMeasurer) first(field *ast.Field) int {
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if n, err := strconv.Atoi(value.Value); err == nil && n > 0 {
+				return n
+			}
+		case *ast.Variable:
+			// Variables decoded from JSON are float64
+			switch n := m.variables[value.Name.Value].(type) {
+			case float64:
+				if n > 0 {
+					return int(n)
+				}
+			case int:
+				if n > 0 {
+					return n
+				}
+			}
+		}
+	}
+	return 0
+}
+
+func (m costMeasurer) typeCondition(condition *ast.Named, parent *graphql.Object) *graphql.Object {
+	if condition == nil {
+		return parent
+	}
+	object, _ := m.schema.Type(condition.Name.Value).(*graphql.Object)
+	return object
+}
+
+// unwrapOutput returns the object type a field resolves to, if any, and
+// whether it is a list
+func unwrapOutput(output graphql.Output) (*graphql.Object, bool) {
+	isList := false
+	for {
+		switch t := output.(type) {
+		case *graphql.NonNull:
+			output = t.OfType
+		case *graphql.List:
+			isList = true
+			output = t.OfType
+		case *graphql.Object:
+			return t, isList
+		default:
+			return nil, isList
+		}
+	}
+}
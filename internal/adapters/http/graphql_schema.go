@@ -0,0 +1,526 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/graphql-go/graphql"
+)
+
+// projectRef is the GraphQL Project type. Projects exist only as the
+// project IDs of tasks, so a project is fully described by its ID.
+type projectRef struct {
+	ID string
+}
+
+// taskConnection is one page of a task listing
+type taskConnection struct {
+	Nodes         []*domain.Task
+	NextPageToken string
+}
+
+// parseTaskFilter reads the TaskFilter input of the schema. Fields left out
+// of the input match every task.
+func parseTaskFilter(arg interface{}) domain.TaskFilter {
+	var filter domain.TaskFilter
+	input, _ := arg.(map[string]interface{})
+	if statuses, ok := input["status"].([]interface{}); ok {
+		for _, status := range statuses {
+			if status, ok := status.(domain.TaskStatus); ok {
+				filter.Statuses = append(filter.Statuses, status)
+			}
+		}
+	}
+	if projectID, ok := input["projectId"].(string); ok {
+		filter.ProjectIDs = []string{projectID}
+	}
+	if dueBefore, ok := input["dueBefore"].(time.Time); ok {
+		filter.DueBefore = &dueBefore
+	}
+	if dueAfter, ok := input["dueAfter"].(time.Time); ok {
+		filter.DueAfter = &dueAfter
+	}
+	filter.IncludeArchived, _ = input["includeArchived"].(bool)
+	return filter
+}
+
+// pageSize reads the first argument of a list field, which defaults to
+// graphQLDefaultPageSize
+func pageSize(p graphql.ResolveParams) (int, error) {
+	first, ok := p.Args["first"].(int)
+	if !ok {
+		return graphQLDefaultPageSize, nil
+	}
+	if first < 1 || first > graphQLMaxPageSize {
+		return 0, customerrors.NewValidationError("Request validation failed",
+			customerrors.FieldError{Field: "first", Message: fmt.Sprintf("must be between 1 and %d", graphQLMaxPageSize)})
+	}
+	return first, nil
+}
+
+// graphQLLoaders batch the lookups of one GraphQL request. Both kinds of
+// lookup fetch through the task service, so callers only see the tasks
+// they may read.
+type graphQLLoaders struct {
+	taskService ports.TaskService
+	tasks       *dataLoader[string, *domain.Task]
+
+	mu           sync.Mutex
+	projectTasks map[string]*dataLoader[string, []*domain.Task]
+}
+
+type graphQLLoadersKey struct{}
+
+func newGraphQLLoaders(taskService ports.TaskService) *graphQLLoaders {
+	return &graphQLLoaders{
+		taskService: taskService,
+		// A single task is fetched directly; several are listed by ID
+		tasks: newDataLoader(func(ctx context.Context, ids []string) (map[string]*domain.Task, error) {
+			found := make(map[string]*domain.Task, len(ids))
+			if len(ids) == 1 {
+				task, err := taskService.GetTask(ctx, ids[0])
+				if customerrors.IsNotFoundError(err) {
+					return found, nil
+				}
+				if err != nil {
+					return nil, err
+				}
+				found[task.ID] = task
+				return found, nil
+			}
+
+			listed, err := taskService.ListTasks(ctx, domain.TaskFilter{IDs: ids, IncludeArchived: true})
+			if err != nil {
+				return nil, err
+			}
+			for _, task := range listed {
+				found[task.ID] = task
+			}
+			return found, nil
+		}),
+		projectTasks: make(map[string]*dataLoader[string, []*domain.Task]),
+	}
+}
+
+// projectTasksLoader returns the loader for the tasks of projects matching
+// filter, whose LimitPerProject sizes each project's list. The projects
+// asked for with the same filter are fetched with one listing.
+func (l *graphQLLoaders) projectTasksLoader(filter domain.TaskFilter) *dataLoader[string, []*domain.Task] {
+	encoded, _ := json.Marshal(filter)
+	key := string(encoded)
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	loader, ok := l.projectTasks[key]
+	if !ok {
+		loader = newDataLoader(func(ctx context.Context, projectIDs []string) (map[string][]*domain.Task, error) {
+			projectFilter := filter
+			projectFilter.ProjectIDs = projectIDs
+			listed, err := l.taskService.ListTasks(ctx, projectFilter)
+			if err != nil {
+				return nil, err
+			}
+			byProject := make(map[string][]*domain.Task, len(projectIDs))
+			for _, task := range listed {
+				byProject[task.ProjectID] = append(byProject[task.ProjectID], task)
+			}
+			return byProject, nil
+		})
+		l.projectTasks[key] = loader
+	}
+	return loader
+}
+
+func contextWithGraphQLLoaders(ctx context.Context, loaders *graphQLLoaders) context.Context {
+	return context.WithValue(ctx, graphQLLoadersKey{}, loaders)
+}
+
+func graphQLLoadersFromContext(ctx context.Context) *graphQLLoaders {
+	return ctx.Value(graphQLLoadersKey{}).(*graphQLLoaders)
+}
+
+// newGraphQLSchema builds the schema served at /api/graphql. Resolvers go
+// through taskService, which validates changes and enforces status
+// transitions and permissions as it does for the REST API.
+func newGraphQLSchema(taskService ports.TaskService) (graphql.Schema, error) {
+	taskStatus := graphql.NewEnum(graphql.EnumConfig{
+		Name: "TaskStatus",
+		Values: graphql.EnumValueConfigMap{
+			"PENDING":     {Value: domain.StatusPending},
+			"IN_PROGRESS": {Value: domain.StatusInProgress},
+			"COMPLETED":   {Value: domain.StatusCompleted},
+		},
+	})
+
+	taskFilter := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "TaskFilter",
+		Description: "Restricts a listing to the tasks matching every field given",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"status":          {Type: graphql.NewList(graphql.NewNonNull(taskStatus)), Description: "Any of these statuses"},
+			"projectId":       {Type: graphql.String},
+			"dueBefore":       {Type: graphql.DateTime},
+			"dueAfter":        {Type: graphql.DateTime},
+			"includeArchived": {Type: graphql.Boolean, Description: "Also list archived tasks"},
+		},
+	})
+
+	project := graphql.NewObject(graphql.ObjectConfig{
+		Name:   "Project",
+		Fields: graphql.Fields{},
+	})
+
+	task := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Task",
+		Fields: graphql.Fields{
+			"id": taskField(graphql.NewNonNull(graphql.ID), func(t *domain.Task) interface{} { return t.ID }),
+			"projectId": taskField(graphql.String, func(t *domain.Task) interface{} {
+				if t.ProjectID == "" {
+					return nil
+				}
+				return t.ProjectID
+			}),
+			"title":       taskField(graphql.NewNonNull(graphql.String), func(t *domain.Task) interface{} { return t.Title }),
+			"description": taskField(graphql.NewNonNull(graphql.String), func(t *domain.Task) interface{} { return t.Description }),
+			"status":      taskField(graphql.NewNonNull(taskStatus), func(t *domain.Task) interface{} { return t.Status }),
+			"createdAt":   taskField(graphql.NewNonNull(graphql.DateTime), func(t *domain.Task) interface{} { return t.CreatedAt }),
+			"updatedAt":   taskField(graphql.NewNonNull(graphql.DateTime), func(t *domain.Task) interface{} { return t.UpdatedAt }),
+			"dueDate":     taskField(graphql.NewNonNull(graphql.DateTime), func(t *domain.Task) interface{} { return t.DueDate }),
+			"version":     taskField(graphql.NewNonNull(graphql.Int), func(t *domain.Task) interface{} { return t.Version }),
+			"deletedAt":   taskField(graphql.DateTime, func(t *domain.Task) interface{} { return optionalTime(t.DeletedAt) }),
+			"archivedAt":  taskField(graphql.DateTime, func(t *domain.Task) interface{} { return optionalTime(t.ArchivedAt) }),
+			"project": taskField(project, func(t *domain.Task) interface{} {
+				if t.ProjectID == "" {
+					return nil
+				}
+				return projectRef{ID: t.ProjectID}
+			}),
+		},
+	})
+
+	project.AddFieldConfig("id", &graphql.Field{
+		Type: graphql.NewNonNull(graphql.ID),
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return p.Source.(projectRef).ID, nil
+		},
+	})
+	project.AddFieldConfig("tasks", &graphql.Field{
+		Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(task))),
+		Description: "The project's tasks, newest first",
+		Args: graphql.FieldConfigArgument{
+			"filter": {Type: taskFilter},
+			"first":  {Type: graphql.Int, Description: pageSizeDescription},
+		},
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			first, err := pageSize(p)
+			if err != nil {
+				return nil, err
+			}
+			projectID := p.Source.(projectRef).ID
+			filter := parseTaskFilter(p.Args["filter"])
+			if len(filter.ProjectIDs) > 0 && filter.ProjectIDs[0] != projectID {
+				return []*domain.Task{}, nil
+			}
+			filter.ProjectIDs = nil
+			filter.LimitPerProject = first
+
+			load := graphQLLoadersFromContext(p.Context).projectTasksLoader(filter).Load(p.Context, projectID)
+			return func() (interface{}, error) {
+				tasks, err := load()
+				if tasks == nil && err == nil {
+					tasks = []*domain.Task{}
+				}
+				return tasks, err
+			}, nil
+		},
+	})
+
+	connection := graphql.NewObject(graphql.ObjectConfig{
+		Name: "TaskConnection",
+		Fields: graphql.Fields{
+			"nodes": {
+				Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(task))),
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(taskConnection).Nodes, nil
+				},
+			},
+			"nextPageToken": {
+				Type:        graphql.String,
+				Description: "Pass as after to fetch the next page; null on the last page",
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if token := p.Source.(taskConnection).NextPageToken; token != "" {
+						return token, nil
+					}
+					return nil, nil
+				},
+			},
+		},
+	})
+
+	query := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"task": {
+				Type:        task,
+				Description: "A live or archived task",
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					id := p.Args["id"].(string)
+					load := graphQLLoadersFromContext(p.Context).tasks.Load(p.Context, id)
+					return func() (interface{}, error) {
+						task, err := load()
+						if err != nil {
+							return nil, err
+						}
+						if task == nil {
+							return nil, customerrors.ErrTaskNotFound
+						}
+						return task, nil
+					}, nil
+				},
+			},
+			"tasks": {
+				Type:        graphql.NewNonNull(connection),
+				Description: "Tasks matching the filter, newest first",
+				Args: graphql.FieldConfigArgument{
+					"filter": {Type: taskFilter},
+					"first":  {Type: graphql.Int, Description: pageSizeDescription},
+					"after":  {Type: graphql.String, Description: "The nextPageToken of the previous page"},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveTasks(p, taskService)
+				},
+			},
+			"deletedTasks": {
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(task))),
+				Description: "Tasks in the trash, most recently deleted first",
+				Args: graphql.FieldConfigArgument{
+					"first": {Type: graphql.Int, Description: pageSizeDescription},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					first, err := pageSize(p)
+					if err != nil {
+						return nil, err
+					}
+					tasks, err := taskService.ListDeletedTasks(p.Context)
+					if err != nil {
+						return nil, err
+					}
+					return tasks[:min(first, len(tasks))], nil
+				},
+			},
+			"project": {
+				Type: graphql.NewNonNull(project),
+				Args: graphql.FieldConfigArgument{
+					"id": {Type: graphql.NewNonNull(graphql.ID)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return projectRef{ID: p.Args["id"].(string)}, nil
+				},
+			},
+		},
+	})
+
+	createTaskInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name: "CreateTaskInput",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"projectId":   {Type: graphql.String},
+			"title":       {Type: graphql.NewNonNull(graphql.String)},
+			"description": {Type: graphql.String},
+			"dueDate":     {Type: graphql.NewNonNull(graphql.DateTime)},
+		},
+	})
+
+	updateTaskInput := graphql.NewInputObject(graphql.InputObjectConfig{
+		Name:        "UpdateTaskInput",
+		Description: "The fields to change; fields left out keep their value",
+		Fields: graphql.InputObjectConfigFieldMap{
+			"projectId":   {Type: graphql.String},
+			"title":       {Type: graphql.String},
+			"description": {Type: graphql.String},
+			"status":      {Type: taskStatus},
+			"dueDate":     {Type: graphql.DateTime},
+		},
+	})
+
+	idArgs := graphql.FieldConfigArgument{
+		"id": {Type: graphql.NewNonNull(graphql.ID)},
+	}
+	versionedIDArgs := graphql.FieldConfigArgument{
+		"id": {Type: graphql.NewNonNull(graphql.ID)},
+		"expectedVersion": {
+			Type:        graphql.Int,
+			Description: "Only change the task if it is still at this version",
+		},
+	}
+
+	mutation := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Mutation",
+		Fields: graphql.Fields{
+			"createTask": {
+				Type: graphql.NewNonNull(task),
+				Args: graphql.FieldConfigArgument{
+					"input": {Type: graphql.NewNonNull(createTaskInput)},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					input := p.Args["input"].(map[string]interface{})
+					var create domain.CreateTaskInput
+					create.ProjectID, _ = input["projectId"].(string)
+					create.Title, _ = input["title"].(string)
+					create.Description, _ = input["description"].(string)
+					create.DueDate, _ = input["dueDate"].(time.Time)
+					return taskService.CreateTask(p.Context, create)
+				},
+			},
+			"updateTask": {
+				Type:        graphql.NewNonNull(task),
+				Description: "Changes the fields given, like a PATCH of the REST API",
+				Args: graphql.FieldConfigArgument{
+					"id":              versionedIDArgs["id"],
+					"input":           {Type: graphql.NewNonNull(updateTaskInput)},
+					"expectedVersion": versionedIDArgs["expectedVersion"],
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return resolveUpdateTask(p, taskService)
+				},
+			},
+			"deleteTask": {
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Moves a task to the trash",
+				Args:        versionedIDArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					version, _ := p.Args["expectedVersion"].(int)
+					if err := taskService.DeleteTask(p.Context, p.Args["id"].(string), int64(version)); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+			"restoreTask": {
+				Type:        graphql.NewNonNull(task),
+				Description: "Takes a task out of the trash",
+				Args:        idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return taskService.RestoreTask(p.Context, p.Args["id"].(string))
+				},
+			},
+			"purgeTask": {
+				Type:        graphql.NewNonNull(graphql.Boolean),
+				Description: "Permanently removes a task from the trash",
+				Args:        idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					if err := taskService.PurgeTask(p.Context, p.Args["id"].(string)); err != nil {
+						return nil, err
+					}
+					return true, nil
+				},
+			},
+			"unarchiveTask": {
+				Type:        graphql.NewNonNull(task),
+				Description: "Makes an archived task editable again",
+				Args:        idArgs,
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return taskService.UnarchiveTask(p.Context, p.Args["id"].(string))
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: query, Mutation: mutation})
+}
+
+func resolveTasks(p graphql.ResolveParams, taskService ports.TaskService) (interface{}, error) {
+	first, err := pageSize(p)
+	if err != nil {
+		return nil, err
+	}
+
+	filter := parseTaskFilter(p.Args["filter"])
+	if after, ok := p.Args["after"].(string); ok && after != "" {
+		cursor, err := decodePageToken(after)
+		if err != nil {
+			return nil, err
+		}
+		filter.After = cursor
+	}
+	// One extra task tells whether there is another page
+	filter.Limit = first + 1
+
+	tasks, err := taskService.ListTasks(p.Context, filter)
+	if err != nil {
+		return nil, err
+	}
+	page := taskConnection{Nodes: tasks}
+	if len(page.Nodes) > first {
+		page.Nodes = page.Nodes[:first]
+		page.NextPageToken = page.Nodes[first-1].Cursor().Token()
+	}
+	return page, nil
+}
+
+func resolveUpdateTask(p graphql.ResolveParams, taskService ports.TaskService) (interface{}, error) {
+	version, _ := p.Args["expectedVersion"].(int)
+	existing, err := taskService.GetTask(p.Context, p.Args["id"].(string))
+	if err != nil {
+		return nil, err
+	}
+	if version != 0 && int64(version) != existing.Version {
+		return nil, customerrors.ErrTaskVersionMismatch
+	}
+
+	// Fields set to null are cleared, which validation rejects for those
+	// that are required
+	updated := *existing
+	input := p.Args["input"].(map[string]interface{})
+	if value, ok := input["projectId"]; ok {
+		updated.ProjectID, _ = value.(string)
+	}
+	if value, ok := input["title"]; ok {
+		updated.Title, _ = value.(string)
+	}
+	if value, ok := input["description"]; ok {
+		updated.Description, _ = value.(string)
+	}
+	if value, ok := input["status"]; ok {
+		updated.Status, _ = value.(domain.TaskStatus)
+	}
+	if value, ok := input["dueDate"]; ok {
+		updated.DueDate, _ = value.(time.Time)
+	}
+
+	// As with PATCH, the change must only be written over the version it
+	// was computed from
+	updated.Version = existing.Version
+	task, err := taskService.UpdateTask(p.Context, &updated)
+	if version == 0 && customerrors.IsPreconditionFailedError(err) {
+		return nil, customerrors.ErrTaskVersionConflict
+	}
+	return task, err
+}
+
+// taskField resolves a field of the Task type with get
+func taskField(fieldType graphql.Output, get func(*domain.Task) interface{}) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+			return get(p.Source.(*domain.Task)), nil
+		},
+	}
+}
+
+// optionalTime returns an untyped nil for unset timestamps so that they
+// resolve to null
+func optionalTime(t *time.Time) interface{} {
+	if t == nil {
+		return nil
+	}
+	return *t
+}
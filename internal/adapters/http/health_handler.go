@@ -0,0 +1,42 @@
+package http
+
+import (
+	"net/http"
+
+	"task-tracking-service/internal/health"
+
+	"github.com/labstack/echo/v4"
+)
+
+// HealthHandler answers liveness and readiness probes
+type HealthHandler struct {
+	registry *health.Registry
+}
+
+func NewHealthHandler(registry *health.Registry) *HealthHandler {
+	return &HealthHandler{
+		registry: registry,
+	}
+}
+
+// Liveness reports that the process is serving requests. It checks no
+// dependencies, so an unavailable database does not get the service
+// restarted.
+func (h *HealthHandler) Liveness(c echo.Context) error {
+	return c.JSON(http.StatusOK, health.Report{
+		Status:     health.StatusUp,
+		Components: map[string]health.ComponentReport{},
+	})
+}
+
+// Readiness reports whether every registered dependency is usable, and
+// responds 503 when one is not so that traffic is routed elsewhere
+func (h *HealthHandler) Readiness(c echo.Context) error {
+	report := h.registry.Check(c.Request().Context())
+
+	status := http.StatusOK
+	if report.Status != health.StatusUp {
+		status = http.StatusServiceUnavailable
+	}
+	return c.JSON(status, report)
+}
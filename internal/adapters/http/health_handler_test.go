@@ -0,0 +1,50 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/health"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestHealthHandler(t *testing.T) {
+	registry := health.NewRegistry(time.Second)
+	databaseErr := errors.New("connection refused")
+	registry.Register("database", health.CheckerFunc(func(ctx context.Context) error { return databaseErr }))
+	registry.Register("migrations", health.CheckerFunc(func(ctx context.Context) error { return nil }))
+	e := newTestRouter(WithHealthRegistry(registry))
+
+	t.Run("liveness does not depend on other components", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/healthz", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, `{"status":"up","components":{}}`, rec.Body.String())
+	})
+
+	t.Run("readiness reports each component", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/readyz", "")
+
+		assert.Equal(t, http.StatusServiceUnavailable, rec.Code)
+		var report health.Report
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &report))
+		assert.Equal(t, health.StatusDown, report.Status)
+		assert.Equal(t, health.StatusDown, report.Components["database"].Status)
+		assert.Equal(t, "connection refused", report.Components["database"].Error)
+		assert.Equal(t, health.StatusUp, report.Components["migrations"].Status)
+	})
+
+	t.Run("ready once every component is up", func(t *testing.T) {
+		databaseErr = nil
+
+		rec := doRequest(e, http.MethodGet, "/readyz", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+}
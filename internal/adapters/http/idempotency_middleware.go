@@ -0,0 +1,156 @@
+package http
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"log/slog"
+	"net/http"
+	"time"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	headerIdempotencyKey = "Idempotency-Key"
+	headerReplayed       = "Idempotent-Replayed"
+
+	// maxIdempotencyKeyLength bounds the keys clients may send
+	maxIdempotencyKeyLength = 255
+
+	// idempotencyLease is how long a key stays claimed by a request that
+	// has not finished, so that a crashed request cannot hold it forever
+	idempotencyLease = time.Minute
+)
+
+// replayedHeaders are the response headers stored with a response. Others,
+// such as the request ID and rate limit headers, belong to each request.
+var replayedHeaders = []string{echo.HeaderContentType, echo.HeaderLocation, headerETag}
+
+// IdempotencyMiddleware makes a route safe to retry. The first response to
+// a request carrying an Idempotency-Key header is stored for ttl and
+// replayed to repeats of that request. A repeat that arrives while the
+// first is in flight gets a 409, and reusing a key for a different request
+// gets a 422. Requests that fail with an error are not stored, so they can
+// be retried with the same key.
+func IdempotencyMiddleware(store ports.IdempotencyStore, ttl time.Duration, logger *slog.Logger) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			key := c.Request().Header.Get(headerIdempotencyKey)
+			if key == "" {
+				return next(c)
+			}
+			if len(key) > maxIdempotencyKeyLength {
+				return echo.NewHTTPError(http.StatusBadRequest, "Idempotency-Key is too long")
+			}
+
+			req := c.Request()
+			body, err := io.ReadAll(req.Body)
+			if err != nil {
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+			}
+			req.Body = io.NopCloser(bytes.NewReader(body))
+
+			ctx := req.Context()
+			storeKey := idempotencyScope(req) + ":" + key
+			fingerprint := requestFingerprint(req, body)
+
+			record, err := store.Begin(ctx, storeKey, fingerprint, idempotencyLease)
+			if err != nil {
+				return err
+			}
+			if record != nil {
+				switch {
+				case record.Fingerprint != fingerprint:
+					return echo.NewHTTPError(http.StatusUnprocessableEntity, "Idempotency-Key was used for a different request")
+				case record.Response == nil:
+					return echo.NewHTTPError(http.StatusConflict, "A request with this Idempotency-Key is still in progress")
+				default:
+					return replay(c, record.Response)
+				}
+			}
+
+			recorder := &responseRecorder{ResponseWriter: c.Response().Writer}
+			c.Response().Writer = recorder
+			err = next(c)
+			c.Response().Writer = recorder.ResponseWriter
+
+			status := c.Response().Status
+			if err != nil || !c.Response().Committed || status >= http.StatusInternalServerError {
+				if releaseErr := store.Release(ctx, storeKey); releaseErr != nil {
+					logger.ErrorContext(ctx, "failed to release idempotency key", "error", releaseErr)
+				}
+				return err
+			}
+
+			response := ports.IdempotentResponse{
+				StatusCode: status,
+				Header:     make(map[string][]string),
+				Body:       recorder.body.Bytes(),
+			}
+			for _, name := range replayedHeaders {
+				if values := c.Response().Header().Values(name); len(values) > 0 {
+					response.Header[name] = values
+				}
+			}
+			if err := store.Complete(ctx, storeKey, response, ttl); err != nil {
+				// The client already has its response; a retry will be
+				// processed again rather than replayed.
+				logger.ErrorContext(ctx, "failed to store idempotent response", "error", err)
+			}
+			return nil
+		}
+	}
+}
+
+// idempotencyScope keeps keys from different callers and tenants apart, so
+// one client cannot replay another's response by guessing its key. The
+// scope is hashed, as principal IDs such as JWT subjects have no length
+// limit while stores keep keys of bounded length.
+func idempotencyScope(req *http.Request) string {
+	ctx := req.Context()
+	caller := "anonymous"
+	if principal, ok := domain.PrincipalFromContext(ctx); ok {
+		caller = principal.ID
+	}
+	hash := sha256.Sum256([]byte(domain.TenantFromContext(ctx) + "\x00" + caller))
+	return hex.EncodeToString(hash[:])
+}
+
+// requestFingerprint identifies a request by its method, path and body
+func requestFingerprint(req *http.Request, body []byte) string {
+	hash := sha256.New()
+	hash.Write([]byte(req.Method + " " + req.URL.Path + "\n"))
+	hash.Write(body)
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func replay(c echo.Context, response *ports.IdempotentResponse) error {
+	header := c.Response().Header()
+	for name, values := range response.Header {
+		header[http.CanonicalHeaderKey(name)] = values
+	}
+	header.Set(headerReplayed, "true")
+
+	if len(response.Body) == 0 {
+		return c.NoContent(response.StatusCode)
+	}
+	c.Response().WriteHeader(response.StatusCode)
+	_, err := c.Response().Write(response.Body)
+	return err
+}
+
+// responseRecorder copies the response body as it is written
+type responseRecorder struct {
+	http.ResponseWriter
+	body bytes.Buffer
+}
+
+func (r *responseRecorder) Write(b []byte) (int, error) {
+	r.body.Write(b)
+	return r.ResponseWriter.Write(b)
+}
@@ -0,0 +1,129 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const createTaskBody = `{"title":"Write docs","due_date":"2099-01-01T00:00:00Z"}`
+
+func countTasks(t *testing.T, e *echo.Echo) int {
+	t.Helper()
+	rec := doRequest(e, http.MethodGet, "/api/v1/task", "")
+	require.Equal(t, http.StatusOK, rec.Code)
+	var tasks []domain.Task
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tasks))
+	return len(tasks)
+}
+
+func TestIdempotencyMiddleware(t *testing.T) {
+	t.Run("repeats replay the first response", func(t *testing.T) {
+		e := newTestRouter(WithIdempotency(memory.NewIdempotencyStore(), time.Hour))
+
+		first := doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody, headerIdempotencyKey, "key-1")
+		require.Equal(t, http.StatusCreated, first.Code)
+		second := doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody, headerIdempotencyKey, "key-1")
+
+		assert.Equal(t, http.StatusCreated, second.Code)
+		assert.Equal(t, first.Body.String(), second.Body.String())
+		assert.Equal(t, first.Header().Get(headerETag), second.Header().Get(headerETag))
+		assert.Equal(t, "true", second.Header().Get(headerReplayed))
+		assert.Empty(t, first.Header().Get(headerReplayed))
+		assert.NotEqual(t, first.Header().Get(echo.HeaderXRequestID), second.Header().Get(echo.HeaderXRequestID))
+		assert.Equal(t, 1, countTasks(t, e))
+	})
+
+	t.Run("requests without a key are not deduplicated", func(t *testing.T) {
+		e := newTestRouter(WithIdempotency(memory.NewIdempotencyStore(), time.Hour))
+
+		doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody)
+		doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody)
+
+		assert.Equal(t, 2, countTasks(t, e))
+	})
+
+	t.Run("reusing a key with a different payload is rejected", func(t *testing.T) {
+		e := newTestRouter(WithIdempotency(memory.NewIdempotencyStore(), time.Hour))
+
+		doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody, headerIdempotencyKey, "key-1")
+		rec := doRequest(e, http.MethodPost, "/api/v1/task", `{"title":"Other","due_date":"2099-01-01T00:00:00Z"}`,
+			headerIdempotencyKey, "key-1")
+
+		assert.Equal(t, http.StatusUnprocessableEntity, rec.Code)
+		assert.Equal(t, 1, countTasks(t, e))
+	})
+
+	t.Run("a repeat while the first is in flight gets a conflict", func(t *testing.T) {
+		store := memory.NewIdempotencyStore()
+		e := newTestRouter(WithIdempotency(store, time.Hour))
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/task", strings.NewReader(createTaskBody))
+		_, err := store.Begin(context.Background(), idempotencyScope(req)+":key-1",
+			requestFingerprint(req, []byte(createTaskBody)), time.Minute)
+		require.NoError(t, err)
+
+		rec := doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody, headerIdempotencyKey, "key-1")
+
+		assert.Equal(t, http.StatusConflict, rec.Code)
+		assert.Equal(t, 0, countTasks(t, e))
+	})
+
+	t.Run("failed requests release the key", func(t *testing.T) {
+		e := newTestRouter(WithIdempotency(memory.NewIdempotencyStore(), time.Hour))
+
+		rec := doRequest(e, http.MethodPost, "/api/v1/task", `{"due_date":"2099-01-01T00:00:00Z"}`, headerIdempotencyKey, "key-1")
+		require.Equal(t, http.StatusBadRequest, rec.Code)
+		rec = doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody, headerIdempotencyKey, "key-1")
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+	})
+
+	t.Run("keys are scoped per tenant", func(t *testing.T) {
+		const otherKey = "other_1234567890123456789012345678901"
+		authenticator := auth.NewAPIKeyAuthenticator([]config.APIKey{
+			{Name: "primary", Key: testAPIKey},
+			{Name: "other", Key: otherKey, TenantID: "other"},
+		})
+		e := newTestRouter(WithAuthenticator(authenticator), WithIdempotency(memory.NewIdempotencyStore(), time.Hour))
+
+		doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody, headerAPIKey, testAPIKey, headerIdempotencyKey, "key-1")
+		rec := doRequest(e, http.MethodPost, "/api/v1/task", createTaskBody,
+			headerAPIKey, otherKey, headerIdempotencyKey, "key-1")
+
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Empty(t, rec.Header().Get(headerReplayed))
+	})
+}
+
+func TestIdempotencyScope(t *testing.T) {
+	scope := func(tenantID, principalID string) string {
+		ctx := domain.ContextWithTenant(context.Background(), tenantID)
+		if principalID != "" {
+			ctx = domain.ContextWithPrincipal(ctx, &domain.Principal{ID: principalID})
+		}
+		return idempotencyScope(httptest.NewRequest(http.MethodPost, "/api/v1/task", nil).WithContext(ctx))
+	}
+
+	t.Run("separates tenants and callers", func(t *testing.T) {
+		assert.NotEqual(t, scope("default", "user-1"), scope("other", "user-1"))
+		assert.NotEqual(t, scope("default", "user-1"), scope("default", "user-2"))
+		assert.NotEqual(t, scope("default", "user-1"), scope("default", ""))
+	})
+
+	t.Run("has a fixed length", func(t *testing.T) {
+		assert.Len(t, scope("default", strings.Repeat("x", 4096)), 64)
+	})
+}
@@ -0,0 +1,54 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetricsMiddleware(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	metrics, err := NewRequestMetrics(reg)
+	require.NoError(t, err)
+	e := newTestRouter(
+		WithRequestMetrics(metrics),
+		WithMetricsHandler(promhttp.HandlerFor(reg, promhttp.HandlerOpts{})),
+	)
+
+	task := createTestTask(t, e)
+	doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "")
+	doRequest(e, http.MethodGet, "/api/v1/task/00000000-0000-0000-0000-000000000000", "")
+	doRequest(e, http.MethodGet, "/no/such/route", "")
+
+	t.Run("requests are counted by route template and status", func(t *testing.T) {
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(http.MethodPost, "/api/v1/task", "201")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(http.MethodGet, "/api/v1/task/:id", "200")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(http.MethodGet, "/api/v1/task/:id", "404")))
+		assert.Equal(t, 1.0, testutil.ToFloat64(metrics.requests.WithLabelValues(http.MethodGet, unmatchedRoute, "404")))
+	})
+
+	t.Run("metrics are served at /metrics", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/metrics", "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Contains(t, rec.Body.String(), `http_request_duration_seconds_count{method="GET",route="/api/v1/task/:id"} 2`)
+	})
+
+	t.Run("errors keep their status", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/api/v1/task/00000000-0000-0000-0000-000000000000", "")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		assert.Contains(t, rec.Body.String(), "not found")
+	})
+}
+
+func TestMetricsHandlerIsOptional(t *testing.T) {
+	rec := doRequest(newTestRouter(), http.MethodGet, "/metrics", "")
+
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+}
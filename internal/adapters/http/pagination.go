@@ -0,0 +1,21 @@
+package http
+
+import (
+	"task-tracking-service/internal/core/domain"
+	customerrors "task-tracking-service/pkg/errors"
+)
+
+// headerNextPageToken carries the page_token of the next page of a listing.
+// It is absent on the last page.
+const headerNextPageToken = "Next-Page-Token"
+
+// decodePageToken reads a page_token, reporting a malformed one as invalid
+// input
+func decodePageToken(token string) (*domain.TaskCursor, error) {
+	cursor, err := domain.ParseTaskCursor(token)
+	if err != nil {
+		return nil, customerrors.NewValidationError("Request validation failed",
+			customerrors.FieldError{Field: "page_token", Message: "is not a token returned by a previous page"})
+	}
+	return cursor, nil
+}
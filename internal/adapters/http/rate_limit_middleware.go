@@ -0,0 +1,73 @@
+package http
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/labstack/echo/v4"
+)
+
+// RateLimitMiddleware applies token bucket limits per caller and route
+// class. Authenticated callers are limited per principal and anonymous
+// callers per client IP.
+func RateLimitMiddleware(store ports.RateLimitStore, policy ports.RateLimitPolicy, logger *slog.Logger) echo.MiddlewareFunc {
+	return rateLimit(store, logger, func(c echo.Context) (string, ports.RateLimits) {
+		if principal, ok := domain.PrincipalFromContext(c.Request().Context()); ok {
+			return "key:" + principal.ID, policy.LimitsFor(principal.ID)
+		}
+		return "ip:" + c.RealIP(), policy.Default
+	})
+}
+
+// ClientRateLimitMiddleware limits requests per client IP before they are
+// authenticated, so that callers guessing credentials are limited too. An
+// IP may send as many requests as the most generous limits of policy allow,
+// which leaves authenticated callers to RateLimitMiddleware.
+func ClientRateLimitMiddleware(store ports.RateLimitStore, policy ports.RateLimitPolicy, logger *slog.Logger) echo.MiddlewareFunc {
+	limits := policy.Ceiling()
+	return rateLimit(store, logger, func(c echo.Context) (string, ports.RateLimits) {
+		return "client:" + c.RealIP(), limits
+	})
+}
+
+// rateLimit takes a token from the bucket of the caller identified by
+// identify, rejecting the request when the bucket is empty
+func rateLimit(store ports.RateLimitStore, logger *slog.Logger, identify func(echo.Context) (string, ports.RateLimits)) echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if publicPaths[c.Path()] {
+				return next(c)
+			}
+
+			req := c.Request()
+			caller, limits := identify(c)
+			class, limit := "write", limits.Write
+			if req.Method == http.MethodGet || req.Method == http.MethodHead {
+				class, limit = "read", limits.Read
+			}
+
+			result, err := store.Take(req.Context(), caller+":"+class, limit)
+			if err != nil {
+				// Fail open: an unavailable limiter should not take the API down
+				logger.WarnContext(req.Context(), "rate limiter unavailable", "error", err)
+				return next(c)
+			}
+
+			header := c.Response().Header()
+			header.Set("RateLimit-Limit", strconv.Itoa(limit.Burst))
+			header.Set("RateLimit-Remaining", strconv.Itoa(result.Remaining))
+			header.Set("RateLimit-Reset", strconv.Itoa(result.ResetSeconds()))
+
+			if !result.Allowed {
+				header.Set(echo.HeaderRetryAfter, strconv.Itoa(result.RetryAfterSeconds()))
+				return echo.NewHTTPError(http.StatusTooManyRequests, "Rate limit exceeded")
+			}
+
+			return next(c)
+		}
+	}
+}
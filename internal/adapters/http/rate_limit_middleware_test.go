@@ -0,0 +1,155 @@
+package http
+
+import (
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitMiddleware(t *testing.T) {
+	policy := ports.RateLimitPolicy{
+		Default: ports.RateLimits{Read: ports.PerMinute(2), Write: ports.PerMinute(1)},
+		PerKey:  map[string]ports.RateLimits{"apikey:ci": {Read: ports.PerMinute(3), Write: ports.PerMinute(3)}},
+	}
+
+	e := echo.New()
+	e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if id := c.Request().Header.Get("X-Test-Principal"); id != "" {
+				ctx := domain.ContextWithPrincipal(c.Request().Context(), &domain.Principal{ID: id})
+				c.SetRequest(c.Request().WithContext(ctx))
+			}
+			return next(c)
+		}
+	})
+	e.Use(RateLimitMiddleware(memory.NewRateLimitStore(), policy, slog.Default()))
+	e.GET("/api/v1/task", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+	e.POST("/api/v1/task", func(c echo.Context) error { return c.NoContent(http.StatusCreated) })
+	e.GET("/healthz", func(c echo.Context) error { return c.NoContent(http.StatusOK) })
+
+	do := func(method, path, principal string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(method, path, nil)
+		req.RemoteAddr = "192.0.2.1:1234"
+		if principal != "" {
+			req.Header.Set("X-Test-Principal", principal)
+		}
+		rec := httptest.NewRecorder()
+		e.ServeHTTP(rec, req)
+		return rec
+	}
+
+	t.Run("limits reads and writes separately", func(t *testing.T) {
+		rec := do(http.MethodGet, "/api/v1/task", "user-1")
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, "2", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Remaining"))
+		assert.Equal(t, "30", rec.Header().Get("RateLimit-Reset"))
+
+		assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/api/v1/task", "user-1").Code)
+		assert.Equal(t, http.StatusOK, do(http.MethodGet, "/api/v1/task", "user-1").Code)
+
+		rec = do(http.MethodPost, "/api/v1/task", "user-1")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "60", rec.Header().Get(echo.HeaderRetryAfter))
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	})
+
+	t.Run("limits each principal independently", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/api/v1/task", "user-2").Code)
+	})
+
+	t.Run("applies per-key overrides", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/api/v1/task", "apikey:ci").Code)
+		}
+		assert.Equal(t, http.StatusTooManyRequests, do(http.MethodPost, "/api/v1/task", "apikey:ci").Code)
+	})
+
+	t.Run("limits anonymous callers by IP", func(t *testing.T) {
+		assert.Equal(t, http.StatusCreated, do(http.MethodPost, "/api/v1/task", "").Code)
+		assert.Equal(t, http.StatusTooManyRequests, do(http.MethodPost, "/api/v1/task", "").Code)
+	})
+
+	t.Run("does not limit health checks", func(t *testing.T) {
+		for i := 0; i < 5; i++ {
+			assert.Equal(t, http.StatusOK, do(http.MethodGet, "/healthz", "").Code)
+		}
+	})
+}
+
+func TestClientRateLimitMiddleware(t *testing.T) {
+	policy := ports.RateLimitPolicy{
+		Default: ports.RateLimits{Read: ports.PerMinute(2), Write: ports.PerMinute(1)},
+		PerKey:  map[string]ports.RateLimits{"apikey:primary": {Read: ports.PerMinute(3), Write: ports.PerMinute(1)}},
+	}
+	authenticator := auth.NewAPIKeyAuthenticator([]config.APIKey{{Name: "primary", Key: testAPIKey}})
+	e := newTestRouter(WithAuthenticator(authenticator), WithRateLimit(memory.NewRateLimitStore(), policy))
+
+	t.Run("limits attempts with invalid credentials per IP", func(t *testing.T) {
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusUnauthorized, doRequest(e, http.MethodGet, "/api/v1/task", "", headerAPIKey, "wrong").Code)
+		}
+		rec := doRequest(e, http.MethodGet, "/api/v1/task", "", headerAPIKey, "wrong")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+		assert.Equal(t, "3", rec.Header().Get("RateLimit-Limit"))
+
+		// The right key is not checked either until the IP's budget refills
+		rec = doRequest(e, http.MethodGet, "/api/v1/task", "", headerAPIKey, testAPIKey)
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("ignores forwarding headers the client sets", func(t *testing.T) {
+		e := newTestRouter(WithAuthenticator(authenticator), WithRateLimit(memory.NewRateLimitStore(), policy))
+
+		for i := 0; i < 3; i++ {
+			rec := doRequest(e, http.MethodGet, "/api/v1/task", "", headerAPIKey, "wrong",
+				echo.HeaderXForwardedFor, fmt.Sprintf("198.51.100.%d", i), echo.HeaderXRealIP, fmt.Sprintf("198.51.100.%d", i))
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+		rec := doRequest(e, http.MethodGet, "/api/v1/task", "", headerAPIKey, "wrong",
+			echo.HeaderXForwardedFor, "198.51.100.3", echo.HeaderXRealIP, "198.51.100.3")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("takes the client IP from trusted proxies", func(t *testing.T) {
+		// Requests from httptest come from 192.0.2.1
+		_, proxies, err := net.ParseCIDR("192.0.2.0/24")
+		require.NoError(t, err)
+		e := newTestRouter(WithAuthenticator(authenticator), WithRateLimit(memory.NewRateLimitStore(), policy), WithTrustedProxies([]*net.IPNet{proxies}))
+
+		for i := 0; i < 4; i++ {
+			rec := doRequest(e, http.MethodGet, "/api/v1/task", "", headerAPIKey, "wrong",
+				echo.HeaderXForwardedFor, fmt.Sprintf("198.51.100.%d", i))
+			assert.Equal(t, http.StatusUnauthorized, rec.Code)
+		}
+
+		// Entries before the ones the proxies appended are the client's own
+		for i := 0; i < 3; i++ {
+			doRequest(e, http.MethodGet, "/api/v1/task", "", headerAPIKey, "wrong",
+				echo.HeaderXForwardedFor, fmt.Sprintf("203.0.113.%d, 198.51.100.9", i))
+		}
+		rec := doRequest(e, http.MethodGet, "/api/v1/task", "", headerAPIKey, "wrong",
+			echo.HeaderXForwardedFor, "203.0.113.3, 198.51.100.9")
+		assert.Equal(t, http.StatusTooManyRequests, rec.Code)
+	})
+
+	t.Run("reports the caller's own limits", func(t *testing.T) {
+		rec := doRequest(e, http.MethodPost, "/api/v1/task", `{"title":"Limited","due_date":"2099-01-01T00:00:00Z"}`, headerAPIKey, testAPIKey)
+		assert.Equal(t, http.StatusCreated, rec.Code)
+		assert.Equal(t, "1", rec.Header().Get("RateLimit-Limit"))
+		assert.Equal(t, "0", rec.Header().Get("RateLimit-Remaining"))
+	})
+}
@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+)
+
+// RequestIDMiddleware gives each request an ID and echoes it in the
+// X-Request-ID response header. A valid ID sent by the caller is kept, so
+// that a request can be followed across services; anything else is
+// replaced. The ID is carried by the request context, which is how it
+// reaches error bodies, logs, SQL comments and outgoing calls.
+func RequestIDMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			req := c.Request()
+			id := req.Header.Get(echo.HeaderXRequestID)
+			if !domain.IsValidRequestID(id) {
+				id = uuid.New().String()
+			}
+
+			c.Response().Header().Set(echo.HeaderXRequestID, id)
+			c.SetRequest(req.WithContext(domain.ContextWithRequestID(req.Context(), id)))
+			return next(c)
+		}
+	}
+}
+
+// requestIDTransport sets X-Request-ID on outgoing requests
+type requestIDTransport struct {
+	next http.RoundTripper
+}
+
+// PropagateRequestID wraps an HTTP client transport, such as one used for
+// webhook calls, so that requests made with a request's context carry its
+// ID. A nil next uses http.DefaultTransport.
+func PropagateRequestID(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return requestIDTransport{next: next}
+}
+
+func (t requestIDTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	id := domain.RequestIDFromContext(req.Context())
+	if id == "" || req.Header.Get(echo.HeaderXRequestID) != "" {
+		return t.next.RoundTrip(req)
+	}
+
+	// RoundTrippers must not modify the request they are given
+	req = req.Clone(req.Context())
+	req.Header.Set(echo.HeaderXRequestID, id)
+	return t.next.RoundTrip(req)
+}
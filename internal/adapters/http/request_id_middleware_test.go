@@ -0,0 +1,83 @@
+package http
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/google/uuid"
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRequestIDMiddleware(t *testing.T) {
+	e := echo.New()
+	e.Pre(RequestIDMiddleware())
+	e.GET("/", func(c echo.Context) error {
+		return c.String(http.StatusOK, domain.RequestIDFromContext(c.Request().Context()))
+	})
+
+	tests := []struct {
+		name     string
+		incoming string
+		keep     bool
+	}{
+		{name: "generated when missing", incoming: ""},
+		{name: "caller's ID is kept", incoming: "caller-id.1_2", keep: true},
+		{name: "IDs that could inject text are replaced", incoming: "x */ DROP TABLE tasks; /*"},
+		{name: "overlong IDs are replaced", incoming: strings.Repeat("a", 129)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodGet, "/", "", echo.HeaderXRequestID, tt.incoming)
+
+			id := rec.Header().Get(echo.HeaderXRequestID)
+			assert.Equal(t, id, rec.Body.String())
+			if tt.keep {
+				assert.Equal(t, tt.incoming, id)
+			} else {
+				_, err := uuid.Parse(id)
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestPropagateRequestID(t *testing.T) {
+	var received string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r.Header.Get(echo.HeaderXRequestID)
+	}))
+	defer server.Close()
+	client := &http.Client{Transport: PropagateRequestID(nil)}
+
+	t.Run("requests made for an API call carry its ID", func(t *testing.T) {
+		ctx := domain.ContextWithRequestID(context.Background(), "req-7")
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Equal(t, "req-7", received)
+		assert.Empty(t, req.Header.Get(echo.HeaderXRequestID))
+	})
+
+	t.Run("other requests are left alone", func(t *testing.T) {
+		req, err := http.NewRequest(http.MethodPost, server.URL, nil)
+		require.NoError(t, err)
+
+		resp, err := client.Do(req)
+		require.NoError(t, err)
+		resp.Body.Close()
+
+		assert.Empty(t, received)
+	})
+}
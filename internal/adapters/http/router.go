@@ -1,29 +1,277 @@
 package http
 
 import (
+	"log/slog"
+	"net"
+	"net/http"
+	"time"
+
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/internal/health"
+
 	"github.com/labstack/echo/v4"
 	"github.com/labstack/echo/v4/middleware"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/labstack/echo/otelecho"
 )
 
-func NewRouter(taskHandler *TaskHandler) *echo.Echo {
+// defaultHealthCheckTimeout bounds each readiness check when no registry
+// is supplied
+const defaultHealthCheckTimeout = 2 * time.Second
+
+// RouterOption customises the router built by NewRouter
+type RouterOption func(*routerOptions)
+
+type routerOptions struct {
+	authenticator        auth.Authenticator
+	authorizationHandler *AuthorizationHandler
+	batchHandler         *BatchHandler
+	graphQLHandler       *GraphQLHandler
+	rateLimitStore       ports.RateLimitStore
+	rateLimitPolicy      ports.RateLimitPolicy
+	idempotencyStore     ports.IdempotencyStore
+	idempotencyTTL       time.Duration
+	healthRegistry       *health.Registry
+	requestMetrics       *RequestMetrics
+	metricsHandler       http.Handler
+	tracingServiceName   string
+	logger               *slog.Logger
+	docsHandler          *DocsHandler
+	contractValidator    *ContractValidator
+	trustedProxies       []*net.IPNet
+}
+
+// WithAuthenticator requires every non-public route to authenticate
+func WithAuthenticator(authenticator auth.Authenticator) RouterOption {
+	return func(o *routerOptions) {
+		o.authenticator = authenticator
+	}
+}
+
+// WithAuthorizationHandler exposes the caller's effective permissions
+func WithAuthorizationHandler(handler *AuthorizationHandler) RouterOption {
+	return func(o *routerOptions) {
+		o.authorizationHandler = handler
+	}
+}
+
+// WithBatchHandler enables POST /api/v1/task:batch
+func WithBatchHandler(handler *BatchHandler) RouterOption {
+	return func(o *routerOptions) {
+		o.batchHandler = handler
+	}
+}
+
+// WithGraphQLHandler enables GET and POST /api/graphql. Mutations are only
+// accepted by POST, so they count against the write rate limit.
+func WithGraphQLHandler(handler *GraphQLHandler) RouterOption {
+	return func(o *routerOptions) {
+		o.graphQLHandler = handler
+	}
+}
+
+// WithRateLimit limits each caller's request rate using the given store
+func WithRateLimit(store ports.RateLimitStore, policy ports.RateLimitPolicy) RouterOption {
+	return func(o *routerOptions) {
+		o.rateLimitStore = store
+		o.rateLimitPolicy = policy
+	}
+}
+
+// WithIdempotency lets clients retry task creation safely by sending an
+// Idempotency-Key header. Responses are kept in store for ttl.
+func WithIdempotency(store ports.IdempotencyStore, ttl time.Duration) RouterOption {
+	return func(o *routerOptions) {
+		o.idempotencyStore = store
+		o.idempotencyTTL = ttl
+	}
+}
+
+// WithHealthRegistry decides readiness from the checkers in registry.
+// Without it, the service is ready as soon as it is live.
+func WithHealthRegistry(registry *health.Registry) RouterOption {
+	return func(o *routerOptions) {
+		o.healthRegistry = registry
+	}
+}
+
+// WithRequestMetrics records the rate, errors and duration of requests
+// to every route
+func WithRequestMetrics(metrics *RequestMetrics) RouterOption {
+	return func(o *routerOptions) {
+		o.requestMetrics = metrics
+	}
+}
+
+// WithMetricsHandler serves handler at GET /metrics. Like the API, the
+// endpoint requires authentication when an authenticator is set.
+func WithMetricsHandler(handler http.Handler) RouterOption {
+	return func(o *routerOptions) {
+		o.metricsHandler = handler
+	}
+}
+
+// WithTracing records a span for each API request, continuing any trace
+// passed in a W3C traceparent header. Spans go to the global tracer
+// provider.
+func WithTracing(serviceName string) RouterOption {
+	return func(o *routerOptions) {
+		o.tracingServiceName = serviceName
+	}
+}
+
+// WithLogger sets the logger used for access logs and errors. Without it,
+// slog.Default is used.
+func WithLogger(logger *slog.Logger) RouterOption {
+	return func(o *routerOptions) {
+		o.logger = logger
+	}
+}
+
+// WithTrustedProxies takes the client IP, which anonymous callers are rate
+// limited by, from the X-Forwarded-For header set by the given proxies.
+// Without it, the client IP is the address the connection comes from and
+// forwarding headers are ignored, since any client can set them.
+func WithTrustedProxies(proxies []*net.IPNet) RouterOption {
+	return func(o *routerOptions) {
+		o.trustedProxies = proxies
+	}
+}
+
+// WithDocsHandler publishes the OpenAPI spec at /api/openapi.yaml and
+// /api/openapi.json, and Swagger UI at /api/docs with its assets under
+// /api/docs/assets
+func WithDocsHandler(handler *DocsHandler) RouterOption {
+	return func(o *routerOptions) {
+		o.docsHandler = handler
+	}
+}
+
+// WithContractValidation checks every request and response against the
+// OpenAPI description. Invalid requests get a 400; responses that do not
+// match are replaced by a 500. Meant for development and tests.
+func WithContractValidation(validator *ContractValidator) RouterOption {
+	return func(o *routerOptions) {
+		o.contractValidator = validator
+	}
+}
+
+func NewRouter(taskHandler *TaskHandler, opts ...RouterOption) *echo.Echo {
+	options := routerOptions{
+		healthRegistry: health.NewRegistry(defaultHealthCheckTimeout),
+		logger:         slog.Default(),
+	}
+	for _, opt := range opts {
+		opt(&options)
+	}
+
 	e := echo.New()
+	e.HTTPErrorHandler = NewHTTPErrorHandler(options.logger)
+	e.Validator = NewRequestValidator()
+	e.IPExtractor = newIPExtractor(options.trustedProxies)
 
 	// Middleware
-	e.Use(middleware.Logger())
+	e.Pre(RequestIDMiddleware())
+	// Tracing comes first so that access log records carry the trace ID
+	if options.tracingServiceName != "" {
+		e.Use(otelecho.Middleware(options.tracingServiceName, otelecho.WithSkipper(func(c echo.Context) bool {
+			// Probes, scrapes and docs would drown out the API's traces
+			return publicPaths[c.Path()] || c.Path() == "/metrics"
+		})))
+	}
+	e.Use(AccessLogMiddleware(options.logger))
+	if options.requestMetrics != nil {
+		e.Use(MetricsMiddleware(options.requestMetrics))
+	}
 	e.Use(middleware.Recover())
+	// Responses are checked from here, so that those of the auth, tenant
+	// and rate limit middleware are covered too
+	if options.contractValidator != nil {
+		e.Use(options.contractValidator.ResponseMiddleware())
+	}
 	e.Use(middleware.CORS())
+	if options.authenticator != nil {
+		// Requests are limited per IP before their credentials are checked,
+		// then per caller once the caller is known
+		if options.rateLimitStore != nil {
+			e.Use(ClientRateLimitMiddleware(options.rateLimitStore, options.rateLimitPolicy, options.logger))
+		}
+		e.Use(AuthMiddleware(options.authenticator))
+	}
+	e.Use(TenantMiddleware())
+	if options.rateLimitStore != nil {
+		e.Use(RateLimitMiddleware(options.rateLimitStore, options.rateLimitPolicy, options.logger))
+	}
+	// Requests are checked once the caller is known to be allowed in
+	if options.contractValidator != nil {
+		e.Use(options.contractValidator.RequestMiddleware())
+	}
+
+	// Probes
+	healthHandler := NewHealthHandler(options.healthRegistry)
+	e.GET("/healthz", healthHandler.Liveness)
+	e.GET("/readyz", healthHandler.Readiness)
+
+	if options.metricsHandler != nil {
+		e.GET("/metrics", echo.WrapHandler(options.metricsHandler))
+	}
 
 	// Routes
 	api := e.Group("/api")
+	if options.docsHandler != nil {
+		api.GET("/openapi.yaml", options.docsHandler.GetSpecYAML)
+		api.GET("/openapi.json", options.docsHandler.GetSpecJSON)
+		api.GET("/docs", options.docsHandler.GetDocs)
+		api.GET("/docs/assets/*", options.docsHandler.GetDocsAsset)
+	}
+	if options.graphQLHandler != nil {
+		api.GET("/graphql", options.graphQLHandler.Serve)
+		api.POST("/graphql", options.graphQLHandler.Serve)
+	}
 	v1 := api.Group("/v1")
 
 	// Task routes
 	tasks := v1.Group("/task")
-	tasks.POST("", taskHandler.CreateTask)
+	var createMiddleware []echo.MiddlewareFunc
+	if options.idempotencyStore != nil {
+		createMiddleware = append(createMiddleware, IdempotencyMiddleware(options.idempotencyStore, options.idempotencyTTL, options.logger))
+	}
+	tasks.POST("", taskHandler.CreateTask, createMiddleware...)
 	tasks.GET("", taskHandler.ListTasks)
 	tasks.GET("/:id", taskHandler.GetTask)
 	tasks.PUT("/:id", taskHandler.UpdateTask)
+	tasks.PATCH("/:id", taskHandler.PatchTask)
 	tasks.DELETE("/:id", taskHandler.DeleteTask)
+	tasks.POST("/:id/restore", taskHandler.RestoreTask)
+	tasks.POST("/:id/unarchive", taskHandler.UnarchiveTask)
+
+	// Deleted tasks stay in the trash until they are purged
+	tasks.GET("/trash", taskHandler.ListDeletedTasks)
+	tasks.GET("/trash/:id", taskHandler.GetDeletedTask)
+	tasks.DELETE("/trash/:id", taskHandler.PurgeTask)
+
+	if options.batchHandler != nil {
+		// The colon is escaped so that Echo treats it literally
+		v1.POST("/task\\:batch", options.batchHandler.ExecuteBatch)
+	}
+
+	if options.authorizationHandler != nil {
+		v1.GET("/permissions", options.authorizationHandler.GetPermissions)
+	}
 
 	return e
 }
+
+// newIPExtractor trusts X-Forwarded-For only when it comes from one of
+// proxies, and only the entries those proxies appended
+func newIPExtractor(proxies []*net.IPNet) echo.IPExtractor {
+	if len(proxies) == 0 {
+		return echo.ExtractIPDirect()
+	}
+
+	trust := []echo.TrustOption{echo.TrustLoopback(false), echo.TrustLinkLocal(false), echo.TrustPrivateNet(false)}
+	for _, proxy := range proxies {
+		trust = append(trust, echo.TrustIPRange(proxy))
+	}
+	return echo.ExtractIPFromXFFHeader(trust...)
+}
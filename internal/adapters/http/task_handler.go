@@ -1,84 +1,285 @@
 package http
 
 import (
+	"io"
+	"log/slog"
 	"net/http"
 	"task-tracking-service/internal/core/domain"
-	"task-tracking-service/internal/core/services"
+	"task-tracking-service/internal/core/ports"
+	customerrors "task-tracking-service/pkg/errors"
 	"time"
 
 	"github.com/labstack/echo/v4"
 )
 
 type TaskHandler struct {
-	taskService *services.TaskService
+	taskService ports.TaskService
+	logger      *slog.Logger
 }
 
-func NewTaskHandler(taskService *services.TaskService) *TaskHandler {
+func NewTaskHandler(taskService ports.TaskService, logger *slog.Logger) *TaskHandler {
 	return &TaskHandler{
 		taskService: taskService,
+		logger:      logger,
 	}
 }
 
 type CreateTaskRequest struct {
-	Title       string    `json:"title" validate:"required"`
+	ProjectID   string    `json:"project_id"`
+	Title       string    `json:"title" validate:"required,max=255"`
 	Description string    `json:"description"`
-	DueDate     time.Time `json:"due_date" validate:"required"`
+	DueDate     time.Time `json:"due_date" validate:"required,notpast"`
+}
+
+// UpdateTaskRequest replaces every caller-editable field of a task
+type UpdateTaskRequest struct {
+	ProjectID   string            `json:"project_id"`
+	Title       string            `json:"title" validate:"required,max=255"`
+	Description string            `json:"description"`
+	Status      domain.TaskStatus `json:"status" validate:"required,oneof=pending in_progress completed"`
+	DueDate     time.Time         `json:"due_date"`
+}
+
+// ListTasksRequest holds the query parameters of a task listing. Without
+// a limit, every task matching the filters is returned at once.
+type ListTasksRequest struct {
+	IncludeArchived bool                `query:"include_archived"`
+	Statuses        []domain.TaskStatus `query:"status" validate:"dive,oneof=pending in_progress completed"`
+	ProjectID       string              `query:"project_id"`
+	DueBefore       *time.Time          `query:"due_before"`
+	DueAfter        *time.Time          `query:"due_after"`
+	Limit           int                 `query:"limit" validate:"omitempty,min=1,max=1000"`
+	PageToken       string              `query:"page_token"`
 }
 
 func (h *TaskHandler) CreateTask(c echo.Context) error {
 	var req CreateTaskRequest
 	if err := c.Bind(&req); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+		return h.invalidRequest(c, "Invalid request body", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
 	}
 
-	task, err := h.taskService.CreateTask(c.Request().Context(), req.Title, req.Description, req.DueDate)
+	task, err := h.taskService.CreateTask(c.Request().Context(), domain.CreateTaskInput{
+		ProjectID:   req.ProjectID,
+		Title:       req.Title,
+		Description: req.Description,
+		DueDate:     req.DueDate,
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to create task")
+		return err
 	}
 
-	return c.JSON(http.StatusCreated, task)
+	return taskJSON(c, http.StatusCreated, task)
 }
 
 func (h *TaskHandler) GetTask(c echo.Context) error {
 	id := c.Param("id")
 	task, err := h.taskService.GetTask(c.Request().Context(), id)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusNotFound, "Task not found")
+		return err
 	}
 
-	return c.JSON(http.StatusOK, task)
+	if noneMatch(c, taskETag(task)) {
+		c.Response().Header().Set(headerETag, taskETag(task))
+		return c.NoContent(http.StatusNotModified)
+	}
+
+	return taskJSON(c, http.StatusOK, task)
 }
 
 func (h *TaskHandler) ListTasks(c echo.Context) error {
-	tasks, err := h.taskService.ListTasks(c.Request().Context())
+	var req ListTasksRequest
+	if err := c.Bind(&req); err != nil {
+		return h.invalidRequest(c, "Invalid query parameters", err)
+	}
+
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	filter := domain.TaskFilter{
+		IncludeArchived: req.IncludeArchived,
+		Statuses:        req.Statuses,
+		DueBefore:       req.DueBefore,
+		DueAfter:        req.DueAfter,
+	}
+	if req.ProjectID != "" {
+		filter.ProjectIDs = []string{req.ProjectID}
+	}
+	if req.PageToken != "" {
+		cursor, err := decodePageToken(req.PageToken)
+		if err != nil {
+			return err
+		}
+		filter.After = cursor
+	}
+	if req.Limit > 0 {
+		// One extra task tells whether there is another page
+		filter.Limit = req.Limit + 1
+	}
+
+	tasks, err := h.taskService.ListTasks(c.Request().Context(), filter)
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to fetch tasks")
+		return err
 	}
 
+	if req.Limit > 0 && len(tasks) > req.Limit {
+		tasks = tasks[:req.Limit]
+		c.Response().Header().Set(headerNextPageToken, tasks[len(tasks)-1].Cursor().Token())
+	}
 	return c.JSON(http.StatusOK, tasks)
 }
 
 func (h *TaskHandler) UpdateTask(c echo.Context) error {
 	id := c.Param("id")
-	var task domain.Task
-	if err := c.Bind(&task); err != nil {
-		return echo.NewHTTPError(http.StatusBadRequest, "Invalid request body")
+	var req UpdateTaskRequest
+	if err := c.Bind(&req); err != nil {
+		return h.invalidRequest(c, "Invalid request body", err)
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
 	}
 
-	task.ID = id
-	updatedTask, err := h.taskService.UpdateTask(c.Request().Context(), &task)
+	updatedTask, err := h.taskService.UpdateTask(c.Request().Context(), &domain.Task{
+		ID:          id,
+		ProjectID:   req.ProjectID,
+		Title:       req.Title,
+		Description: req.Description,
+		Status:      req.Status,
+		DueDate:     req.DueDate,
+		Version:     version,
+	})
 	if err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to update task")
+		return err
 	}
 
-	return c.JSON(http.StatusOK, updatedTask)
+	return taskJSON(c, http.StatusOK, updatedTask)
+}
+
+// PatchTask changes only the fields named in a JSON Merge Patch or JSON
+// Patch body, leaving the rest of the task as it is.
+func (h *TaskHandler) PatchTask(c echo.Context) error {
+	patch, err := io.ReadAll(c.Request().Body)
+	if err != nil {
+		return h.invalidRequest(c, "Invalid request body", err)
+	}
+
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	ctx := c.Request().Context()
+	existing, err := h.taskService.GetTask(ctx, c.Param("id"))
+	if err != nil {
+		return err
+	}
+	if version != 0 && version != existing.Version {
+		return customerrors.ErrTaskVersionMismatch
+	}
+
+	patched, err := applyTaskPatch(existing, c.Request().Header.Get(echo.HeaderContentType), patch)
+	if err != nil {
+		return err
+	}
+
+	req := UpdateTaskRequest{
+		ProjectID:   patched.ProjectID,
+		Title:       patched.Title,
+		Description: patched.Description,
+		Status:      patched.Status,
+		DueDate:     patched.DueDate,
+	}
+	if err := c.Validate(&req); err != nil {
+		return err
+	}
+
+	// The patch was computed from the version just read, so it must only
+	// be written over that version. Without If-Match, losing that race is
+	// a conflict the client can resolve by retrying.
+	patched.Version = existing.Version
+	updatedTask, err := h.taskService.UpdateTask(ctx, patched)
+	if version == 0 && customerrors.IsPreconditionFailedError(err) {
+		return customerrors.ErrTaskVersionConflict
+	}
+	if err != nil {
+		return err
+	}
+
+	return taskJSON(c, http.StatusOK, updatedTask)
 }
 
 func (h *TaskHandler) DeleteTask(c echo.Context) error {
 	id := c.Param("id")
-	if err := h.taskService.DeleteTask(c.Request().Context(), id); err != nil {
-		return echo.NewHTTPError(http.StatusInternalServerError, "Failed to delete task")
+	version, err := ifMatchVersion(c)
+	if err != nil {
+		return err
+	}
+
+	if err := h.taskService.DeleteTask(c.Request().Context(), id, version); err != nil {
+		return err
+	}
+
+	return c.NoContent(http.StatusNoContent)
+}
+
+// ListDeletedTasks lists the tasks in the trash
+func (h *TaskHandler) ListDeletedTasks(c echo.Context) error {
+	tasks, err := h.taskService.ListDeletedTasks(c.Request().Context())
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, tasks)
+}
+
+func (h *TaskHandler) GetDeletedTask(c echo.Context) error {
+	task, err := h.taskService.GetDeletedTask(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return c.JSON(http.StatusOK, task)
+}
+
+// RestoreTask takes a task out of the trash
+func (h *TaskHandler) RestoreTask(c echo.Context) error {
+	task, err := h.taskService.RestoreTask(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return taskJSON(c, http.StatusOK, task)
+}
+
+// PurgeTask permanently removes a task from the trash
+func (h *TaskHandler) PurgeTask(c echo.Context) error {
+	if err := h.taskService.PurgeTask(c.Request().Context(), c.Param("id")); err != nil {
+		return err
 	}
 
 	return c.NoContent(http.StatusNoContent)
 }
+
+// UnarchiveTask moves an archived task back among the live tasks
+func (h *TaskHandler) UnarchiveTask(c echo.Context) error {
+	task, err := h.taskService.UnarchiveTask(c.Request().Context(), c.Param("id"))
+	if err != nil {
+		return err
+	}
+
+	return taskJSON(c, http.StatusOK, task)
+}
+
+// invalidRequest rejects a request that could not be read. The reason is
+// only logged, as it names the Go types the request is decoded into.
+func (h *TaskHandler) invalidRequest(c echo.Context, message string, err error) error {
+	h.logger.DebugContext(c.Request().Context(), "invalid request", "error", err)
+	return echo.NewHTTPError(http.StatusBadRequest, message)
+}
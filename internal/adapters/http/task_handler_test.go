@@ -0,0 +1,573 @@
+package http
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/services"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestRouter(opts ...RouterOption) *echo.Echo {
+	taskService := services.NewTaskService(memory.NewTaskRepository(), slog.Default())
+	return NewRouter(NewTaskHandler(taskService, slog.Default()), opts...)
+}
+
+func doRequest(e *echo.Echo, method, path, body string, headers ...string) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(method, path, strings.NewReader(body))
+	if body != "" {
+		req.Header.Set(echo.HeaderContentType, echo.MIMEApplicationJSON)
+	}
+	for i := 0; i+1 < len(headers); i += 2 {
+		req.Header.Set(headers[i], headers[i+1])
+	}
+	rec := httptest.NewRecorder()
+	e.ServeHTTP(rec, req)
+	return rec
+}
+
+func createTestTask(t *testing.T, e *echo.Echo) *domain.Task {
+	t.Helper()
+	rec := doRequest(e, http.MethodPost, "/api/v1/task",
+		`{"title":"Write docs","description":"API reference","due_date":"2099-01-01T00:00:00Z"}`)
+	require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+
+	var task domain.Task
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &task))
+	return &task
+}
+
+func decodeError(t *testing.T, rec *httptest.ResponseRecorder) ErrorResponse {
+	t.Helper()
+	var body ErrorResponse
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+	return body
+}
+
+func TestTaskHandler_ErrorStatusMapping(t *testing.T) {
+	e := newTestRouter()
+	task := createTestTask(t, e)
+
+	t.Run("get missing task returns 404", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/api/v1/task/missing", "")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+		body := decodeError(t, rec)
+		assert.Equal(t, http.StatusNotFound, body.Code)
+		assert.NotEmpty(t, body.RequestID)
+		assert.Equal(t, rec.Header().Get(echo.HeaderXRequestID), body.RequestID)
+	})
+
+	t.Run("update missing task returns 404", func(t *testing.T) {
+		rec := doRequest(e, http.MethodPut, "/api/v1/task/missing", `{"title":"x","status":"pending"}`)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("invalid status returns 400 with field details", func(t *testing.T) {
+		rec := doRequest(e, http.MethodPut, "/api/v1/task/"+task.ID, `{"title":"x","status":"archived"}`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		body := decodeError(t, rec)
+		require.Len(t, body.Details, 1)
+		assert.Equal(t, "status", body.Details[0].Field)
+	})
+
+	t.Run("delete missing task returns 404", func(t *testing.T) {
+		rec := doRequest(e, http.MethodDelete, "/api/v1/task/missing", "")
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+
+	t.Run("malformed body returns 400", func(t *testing.T) {
+		rec := doRequest(e, http.MethodPost, "/api/v1/task", `{"title":`)
+
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Equal(t, http.StatusBadRequest, decodeError(t, rec).Code)
+	})
+
+	t.Run("accepts a caller supplied request ID", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/api/v1/task/missing", "", echo.HeaderXRequestID, "caller-id-1")
+
+		assert.Equal(t, "caller-id-1", decodeError(t, rec).RequestID)
+	})
+}
+
+func TestTaskHandler_RequestValidation(t *testing.T) {
+	e := newTestRouter()
+	task := createTestTask(t, e)
+
+	tests := []struct {
+		name           string
+		method         string
+		path           string
+		body           string
+		expectedFields []string
+	}{
+		{
+			name:           "create reports every missing field",
+			method:         http.MethodPost,
+			path:           "/api/v1/task",
+			body:           `{"description":"no title"}`,
+			expectedFields: []string{"title", "due_date"},
+		},
+		{
+			name:           "create rejects a long title and a past due date",
+			method:         http.MethodPost,
+			path:           "/api/v1/task",
+			body:           `{"title":"` + strings.Repeat("x", 256) + `","due_date":"2000-01-01T00:00:00Z"}`,
+			expectedFields: []string{"title", "due_date"},
+		},
+		{
+			name:           "update rejects an empty title and unknown status",
+			method:         http.MethodPut,
+			path:           "/api/v1/task/" + task.ID,
+			body:           `{"title":"","status":"archived"}`,
+			expectedFields: []string{"title", "status"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, tt.method, tt.path, tt.body)
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code)
+			body := decodeError(t, rec)
+			var fields []string
+			for _, detail := range body.Details {
+				fields = append(fields, detail.Field)
+			}
+			assert.Equal(t, tt.expectedFields, fields)
+		})
+	}
+}
+
+func TestTaskHandler_ListPages(t *testing.T) {
+	e := newTestRouter()
+	var created []string
+	for i := 0; i < 3; i++ {
+		created = append([]string{createTestTask(t, e).ID}, created...)
+	}
+
+	t.Run("pages follow the Next-Page-Token header to the end", func(t *testing.T) {
+		var listed []string
+		path := "/api/v1/task?limit=2"
+		for pages := 0; path != ""; pages++ {
+			require.Less(t, pages, 3)
+			rec := doRequest(e, http.MethodGet, path, "")
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var tasks []domain.Task
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tasks))
+			for _, task := range tasks {
+				listed = append(listed, task.ID)
+			}
+			path = ""
+			if token := rec.Header().Get(headerNextPageToken); token != "" {
+				path = "/api/v1/task?limit=2&page_token=" + token
+			}
+		}
+
+		assert.Equal(t, created, listed)
+	})
+
+	t.Run("without a limit every task is returned", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, "/api/v1/task", "")
+
+		var tasks []domain.Task
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tasks))
+		assert.Len(t, tasks, 3)
+		assert.Empty(t, rec.Header().Get(headerNextPageToken))
+	})
+
+	t.Run("invalid limits and tokens are rejected", func(t *testing.T) {
+		for _, query := range []string{"limit=1001", "limit=-1", "page_token=bogus"} {
+			rec := doRequest(e, http.MethodGet, "/api/v1/task?"+query, "")
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+
+	t.Run("limit violations describe the bound", func(t *testing.T) {
+		for query, message := range map[string]string{
+			"limit=5000": "must be at most 1000",
+			"limit=-1":   "must be at least 1",
+		} {
+			rec := doRequest(e, http.MethodGet, "/api/v1/task?"+query, "")
+
+			require.Equal(t, http.StatusBadRequest, rec.Code, query)
+			body := decodeError(t, rec)
+			require.Len(t, body.Details, 1, query)
+			assert.Equal(t, message, body.Details[0].Message, query)
+		}
+	})
+}
+
+func TestTaskHandler_ListFilters(t *testing.T) {
+	e := newTestRouter()
+	for i, body := range []string{
+		`{"title":"Soon","project_id":"docs","due_date":"2098-01-01T00:00:00Z"}`,
+		`{"title":"Later","project_id":"docs","due_date":"2099-06-01T00:00:00Z"}`,
+		`{"title":"Elsewhere","project_id":"site","due_date":"2099-06-01T00:00:00Z"}`,
+	} {
+		rec := doRequest(e, http.MethodPost, "/api/v1/task", body)
+		require.Equal(t, http.StatusCreated, rec.Code, rec.Body.String())
+		var task domain.Task
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &task))
+		if i == 0 {
+			rec = doRequest(e, http.MethodPatch, "/api/v1/task/"+task.ID, `{"status":"in_progress"}`,
+				echo.HeaderContentType, MIMEMergePatch)
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		}
+	}
+
+	tests := []struct {
+		name  string
+		query string
+		want  []string
+	}{
+		{name: "by status", query: "status=in_progress", want: []string{"Soon"}},
+		{name: "by several statuses", query: "status=pending&status=completed", want: []string{"Elsewhere", "Later"}},
+		{name: "by project", query: "project_id=docs", want: []string{"Later", "Soon"}},
+		{name: "by due date", query: "due_before=2099-01-01T00:00:00Z", want: []string{"Soon"}},
+		{name: "by due date and project", query: "due_after=2099-01-01T00:00:00Z&project_id=site", want: []string{"Elsewhere"}},
+		{name: "limit applies after filtering", query: "project_id=docs&limit=1", want: []string{"Later"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rec := doRequest(e, http.MethodGet, "/api/v1/task?"+tt.query, "")
+			require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+			var listed []domain.Task
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &listed))
+			var titles []string
+			for _, task := range listed {
+				titles = append(titles, task.Title)
+			}
+			assert.Equal(t, tt.want, titles)
+		})
+	}
+
+	t.Run("unknown statuses and malformed dates are rejected", func(t *testing.T) {
+		for _, query := range []string{"status=archived", "due_before=tomorrow"} {
+			rec := doRequest(e, http.MethodGet, "/api/v1/task?"+query, "")
+
+			assert.Equal(t, http.StatusBadRequest, rec.Code, query)
+		}
+	})
+}
+
+func TestTaskHandler_ListPagesForProjectScopedCallers(t *testing.T) {
+	inner := services.NewTaskService(memory.NewTaskRepository(), slog.Default())
+	policy := services.NewPolicy([]domain.RoleBinding{
+		{Subject: "apikey:scoped", Role: domain.RoleViewer, ProjectID: "proj-1"},
+	}, "")
+	authenticator := auth.NewAPIKeyAuthenticator([]config.APIKey{{Name: "scoped", Key: testAPIKey}})
+	e := NewRouter(NewTaskHandler(services.NewAuthorizedTaskService(inner, policy, slog.Default()), slog.Default()),
+		WithAuthenticator(authenticator))
+
+	// Readable and unreadable tasks alternate, so every page of the
+	// unfiltered listing holds some the caller may not see
+	var readable []string
+	for i := 0; i < 6; i++ {
+		projectID := "proj-2"
+		if i%2 == 0 {
+			projectID = "proj-1"
+		}
+		task, err := inner.CreateTask(context.Background(), domain.CreateTaskInput{
+			ProjectID: projectID, Title: "Task", DueDate: time.Now().Add(time.Hour),
+		})
+		require.NoError(t, err)
+		if projectID == "proj-1" {
+			readable = append([]string{task.ID}, readable...)
+		}
+	}
+
+	var listed []string
+	path := "/api/v1/task?limit=2"
+	for pages := 0; path != ""; pages++ {
+		require.Less(t, pages, 2)
+		rec := doRequest(e, http.MethodGet, path, "", headerAPIKey, testAPIKey)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+
+		var tasks []domain.Task
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tasks))
+		for _, task := range tasks {
+			listed = append(listed, task.ID)
+		}
+		path = ""
+		if token := rec.Header().Get(headerNextPageToken); token != "" {
+			path = "/api/v1/task?limit=2&page_token=" + token
+		}
+	}
+
+	assert.Equal(t, readable, listed)
+}
+
+func TestTaskHandler_PatchTask(t *testing.T) {
+	tests := []struct {
+		name           string
+		contentType    string
+		body           string
+		expectedStatus int
+		check          func(t *testing.T, original, patched *domain.Task)
+	}{
+		{
+			name:           "merge patch changes only the fields sent",
+			contentType:    MIMEMergePatch,
+			body:           `{"status":"in_progress","description":null}`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, original, patched *domain.Task) {
+				assert.Equal(t, domain.StatusInProgress, patched.Status)
+				assert.Empty(t, patched.Description)
+				assert.Equal(t, original.Title, patched.Title)
+				assert.True(t, original.DueDate.Equal(patched.DueDate))
+				assert.True(t, original.CreatedAt.Equal(patched.CreatedAt))
+			},
+		},
+		{
+			name:           "json patch applies operations in order",
+			contentType:    MIMEJSONPatch,
+			body:           `[{"op":"test","path":"/status","value":"pending"},{"op":"replace","path":"/title","value":"Renamed"}]`,
+			expectedStatus: http.StatusOK,
+			check: func(t *testing.T, original, patched *domain.Task) {
+				assert.Equal(t, "Renamed", patched.Title)
+				assert.Equal(t, original.Description, patched.Description)
+				assert.True(t, original.DueDate.Equal(patched.DueDate))
+			},
+		},
+		{
+			name:           "read-only fields are rejected",
+			contentType:    MIMEMergePatch,
+			body:           `{"id":"other","created_at":"2000-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "json patch cannot remove read-only fields",
+			contentType:    MIMEJSONPatch,
+			body:           `[{"op":"remove","path":"/updated_at"}]`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "patches cannot move a task to the trash",
+			contentType:    MIMEMergePatch,
+			body:           `{"deleted_at":"2000-01-01T00:00:00Z"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "patched task is validated",
+			contentType:    MIMEMergePatch,
+			body:           `{"title":null,"status":"archived"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "unknown fields are rejected",
+			contentType:    MIMEMergePatch,
+			body:           `{"priority":"high"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "failed test operation is a conflict",
+			contentType:    MIMEJSONPatch,
+			body:           `[{"op":"test","path":"/status","value":"completed"},{"op":"replace","path":"/title","value":"Renamed"}]`,
+			expectedStatus: http.StatusConflict,
+		},
+		{
+			name:           "malformed patch is rejected",
+			contentType:    MIMEJSONPatch,
+			body:           `{"op":"replace"}`,
+			expectedStatus: http.StatusBadRequest,
+		},
+		{
+			name:           "plain JSON is not a patch format",
+			contentType:    echo.MIMEApplicationJSON,
+			body:           `{"title":"Renamed"}`,
+			expectedStatus: http.StatusUnsupportedMediaType,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := newTestRouter()
+			original := createTestTask(t, e)
+
+			rec := doRequest(e, http.MethodPatch, "/api/v1/task/"+original.ID, tt.body,
+				echo.HeaderContentType, tt.contentType)
+
+			require.Equal(t, tt.expectedStatus, rec.Code, rec.Body.String())
+			if tt.check == nil {
+				return
+			}
+			var patched domain.Task
+			require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &patched))
+			tt.check(t, original, &patched)
+		})
+	}
+
+	t.Run("missing task returns 404", func(t *testing.T) {
+		rec := doRequest(newTestRouter(), http.MethodPatch, "/api/v1/task/missing", `{"title":"x"}`,
+			echo.HeaderContentType, MIMEMergePatch)
+
+		assert.Equal(t, http.StatusNotFound, rec.Code)
+	})
+}
+
+func TestTaskHandler_ConditionalRequests(t *testing.T) {
+	t.Run("responses carry the task version as an ETag", func(t *testing.T) {
+		e := newTestRouter()
+		task := createTestTask(t, e)
+
+		rec := doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "")
+
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"1"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("If-None-Match with the current ETag returns 304", func(t *testing.T) {
+		e := newTestRouter()
+		task := createTestTask(t, e)
+
+		rec := doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "", "If-None-Match", `W/"1"`)
+		assert.Equal(t, http.StatusNotModified, rec.Code)
+		assert.Empty(t, rec.Body.String())
+
+		rec = doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "", "If-None-Match", `"7"`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+	})
+
+	t.Run("PUT with a stale If-Match returns 412", func(t *testing.T) {
+		e := newTestRouter()
+		task := createTestTask(t, e)
+
+		rec := doRequest(e, http.MethodPut, "/api/v1/task/"+task.ID, `{"title":"First","status":"pending"}`, "If-Match", `"1"`)
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+
+		rec = doRequest(e, http.MethodPut, "/api/v1/task/"+task.ID, `{"title":"Second","status":"pending"}`, "If-Match", `"1"`)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		rec = doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "")
+		assert.Contains(t, rec.Body.String(), `"title":"First"`)
+	})
+
+	t.Run("PATCH honours If-Match", func(t *testing.T) {
+		e := newTestRouter()
+		task := createTestTask(t, e)
+
+		rec := doRequest(e, http.MethodPatch, "/api/v1/task/"+task.ID, `{"title":"Renamed"}`,
+			echo.HeaderContentType, MIMEMergePatch, "If-Match", `"2"`)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code)
+
+		rec = doRequest(e, http.MethodPatch, "/api/v1/task/"+task.ID, `{"title":"Renamed"}`,
+			echo.HeaderContentType, MIMEMergePatch, "If-Match", `"1"`)
+		assert.Equal(t, http.StatusOK, rec.Code)
+		assert.Equal(t, `"2"`, rec.Header().Get("ETag"))
+	})
+
+	t.Run("DELETE honours If-Match", func(t *testing.T) {
+		e := newTestRouter()
+		task := createTestTask(t, e)
+
+		rec := doRequest(e, http.MethodDelete, "/api/v1/task/"+task.ID, "", "If-Match", `W/"1"`)
+		assert.Equal(t, http.StatusPreconditionFailed, rec.Code, "weak tags never match If-Match")
+
+		rec = doRequest(e, http.MethodDelete, "/api/v1/task/"+task.ID, "", "If-Match", `"1"`)
+		assert.Equal(t, http.StatusNoContent, rec.Code)
+	})
+}
+
+func TestTaskHandler_Trash(t *testing.T) {
+	e := newTestRouter()
+	task := createTestTask(t, e)
+	path := "/api/v1/task/" + task.ID
+
+	rec := doRequest(e, http.MethodDelete, path, "")
+	require.Equal(t, http.StatusNoContent, rec.Code)
+
+	t.Run("deleted tasks are only listed in the trash", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodGet, path, "").Code)
+
+		rec := doRequest(e, http.MethodGet, "/api/v1/task/trash", "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var trash []domain.Task
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &trash))
+		require.Len(t, trash, 1)
+		assert.Equal(t, task.ID, trash[0].ID)
+		assert.NotNil(t, trash[0].DeletedAt)
+	})
+
+	t.Run("restore returns the task with its new version", func(t *testing.T) {
+		rec := doRequest(e, http.MethodPost, path+"/restore", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `"3"`, rec.Header().Get(headerETag))
+
+		assert.Equal(t, http.StatusOK, doRequest(e, http.MethodGet, path, "").Code)
+		assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodPost, path+"/restore", "").Code)
+	})
+
+	t.Run("purge removes the task for good", func(t *testing.T) {
+		assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodDelete, "/api/v1/task/trash/"+task.ID, "").Code)
+
+		require.Equal(t, http.StatusNoContent, doRequest(e, http.MethodDelete, path, "").Code)
+		assert.Equal(t, http.StatusNoContent, doRequest(e, http.MethodDelete, "/api/v1/task/trash/"+task.ID, "").Code)
+		assert.Equal(t, http.StatusNotFound, doRequest(e, http.MethodGet, "/api/v1/task/trash/"+task.ID, "").Code)
+	})
+}
+
+func TestTaskHandler_Archive(t *testing.T) {
+	repo := memory.NewTaskRepository()
+	e := NewRouter(NewTaskHandler(services.NewTaskService(repo, slog.Default()), slog.Default()))
+	task := createTestTask(t, e)
+	path := "/api/v1/task/" + task.ID
+
+	rec := doRequest(e, http.MethodPut, path,
+		`{"title":"Write docs","description":"API reference","status":"completed","due_date":"2099-01-01T00:00:00Z"}`)
+	require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+	_, err := services.NewTaskArchiver(repo, 0, slog.Default()).ArchiveCompleted(context.Background())
+	require.NoError(t, err)
+
+	t.Run("archived tasks can still be read", func(t *testing.T) {
+		rec := doRequest(e, http.MethodGet, path, "")
+		require.Equal(t, http.StatusOK, rec.Code)
+		var archived domain.Task
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &archived))
+		assert.NotNil(t, archived.ArchivedAt)
+	})
+
+	t.Run("listing includes archived tasks on request", func(t *testing.T) {
+		var tasks []domain.Task
+		rec := doRequest(e, http.MethodGet, "/api/v1/task", "")
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tasks))
+		assert.Empty(t, tasks)
+
+		rec = doRequest(e, http.MethodGet, "/api/v1/task?include_archived=true", "")
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &tasks))
+		assert.Len(t, tasks, 1)
+
+		assert.Equal(t, http.StatusBadRequest, doRequest(e, http.MethodGet, "/api/v1/task?include_archived=maybe", "").Code)
+	})
+
+	t.Run("archived tasks must be unarchived before they change", func(t *testing.T) {
+		assert.Equal(t, http.StatusConflict, doRequest(e, http.MethodDelete, path, "").Code)
+
+		rec := doRequest(e, http.MethodPost, path+"/unarchive", "")
+		require.Equal(t, http.StatusOK, rec.Code, rec.Body.String())
+		assert.Equal(t, `"3"`, rec.Header().Get(headerETag))
+
+		assert.Equal(t, http.StatusNoContent, doRequest(e, http.MethodDelete, path, "").Code)
+	})
+}
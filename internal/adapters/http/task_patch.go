@@ -0,0 +1,98 @@
+package http
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+	"time"
+
+	"task-tracking-service/internal/core/domain"
+	customerrors "task-tracking-service/pkg/errors"
+
+	jsonpatch "github.com/evanphx/json-patch/v5"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	// MIMEMergePatch is the media type of an RFC 7396 JSON Merge Patch
+	MIMEMergePatch = "application/merge-patch+json"
+
+	// MIMEJSONPatch is the media type of an RFC 6902 JSON Patch
+	MIMEJSONPatch = "application/json-patch+json"
+)
+
+// applyTaskPatch applies a merge patch or JSON patch to the JSON form of
+// task and returns the result. The patch may only touch caller-editable
+// fields; changing id, created_at, updated_at, version, deleted_at or
+// archived_at is reported as a validation error.
+func applyTaskPatch(task *domain.Task, contentType string, patch []byte) (*domain.Task, error) {
+	original, err := json.Marshal(task)
+	if err != nil {
+		return nil, fmt.Errorf("encoding task for patch: %w", err)
+	}
+
+	mediaType, _, _ := mime.ParseMediaType(contentType)
+	var patched []byte
+	switch mediaType {
+	case MIMEMergePatch:
+		patched, err = jsonpatch.MergePatch(original, patch)
+	case MIMEJSONPatch:
+		var operations jsonpatch.Patch
+		operations, err = jsonpatch.DecodePatch(patch)
+		if err == nil {
+			patched, err = operations.Apply(original)
+		}
+	default:
+		return nil, echo.NewHTTPError(http.StatusUnsupportedMediaType,
+			fmt.Sprintf("Content-Type must be %s or %s", MIMEMergePatch, MIMEJSONPatch))
+	}
+	switch {
+	case errors.Is(err, jsonpatch.ErrTestFailed):
+		return nil, customerrors.NewConflictError("patch test operation failed")
+	case err != nil:
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Invalid patch document")
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(patched))
+	decoder.DisallowUnknownFields()
+	var result domain.Task
+	if err := decoder.Decode(&result); err != nil {
+		return nil, echo.NewHTTPError(http.StatusBadRequest, "Patch produced an invalid task")
+	}
+
+	var readOnly []customerrors.FieldError
+	if result.ID != task.ID {
+		readOnly = append(readOnly, customerrors.FieldError{Field: "id", Message: "is read-only"})
+	}
+	if !result.CreatedAt.Equal(task.CreatedAt) {
+		readOnly = append(readOnly, customerrors.FieldError{Field: "created_at", Message: "is read-only"})
+	}
+	if !result.UpdatedAt.Equal(task.UpdatedAt) {
+		readOnly = append(readOnly, customerrors.FieldError{Field: "updated_at", Message: "is read-only"})
+	}
+	if result.Version != task.Version {
+		readOnly = append(readOnly, customerrors.FieldError{Field: "version", Message: "is read-only"})
+	}
+	if !sameTime(result.DeletedAt, task.DeletedAt) {
+		readOnly = append(readOnly, customerrors.FieldError{Field: "deleted_at", Message: "is read-only"})
+	}
+	if !sameTime(result.ArchivedAt, task.ArchivedAt) {
+		readOnly = append(readOnly, customerrors.FieldError{Field: "archived_at", Message: "is read-only"})
+	}
+	if len(readOnly) > 0 {
+		return nil, customerrors.NewValidationError("Patch modifies read-only fields", readOnly...)
+	}
+
+	return &result, nil
+}
+
+// sameTime reports whether two optional timestamps are both unset or equal
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
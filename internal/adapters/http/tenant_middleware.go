@@ -0,0 +1,38 @@
+package http
+
+import (
+	"errors"
+	"net/http"
+
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/labstack/echo/v4"
+)
+
+const headerTenantID = "X-Tenant-ID"
+
+// TenantMiddleware scopes each request to a tenant. Credentials bound to a
+// tenant act within it and all others within the default tenant. An
+// X-Tenant-ID header naming any other tenant is rejected.
+func TenantMiddleware() echo.MiddlewareFunc {
+	return func(next echo.HandlerFunc) echo.HandlerFunc {
+		return func(c echo.Context) error {
+			if publicPaths[c.Path()] {
+				return next(c)
+			}
+
+			req := c.Request()
+			principal, _ := domain.PrincipalFromContext(req.Context())
+			tenantID, err := domain.ResolveTenant(principal, req.Header.Get(headerTenantID))
+			switch {
+			case errors.Is(err, domain.ErrTenantMismatch):
+				return echo.NewHTTPError(http.StatusForbidden, "Credentials are not valid for the requested tenant")
+			case err != nil:
+				return echo.NewHTTPError(http.StatusBadRequest, "Invalid tenant ID")
+			}
+
+			c.SetRequest(req.WithContext(domain.ContextWithTenant(req.Context(), tenantID)))
+			return next(c)
+		}
+	}
+}
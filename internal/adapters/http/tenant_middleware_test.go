@@ -0,0 +1,103 @@
+package http
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/labstack/echo/v4"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTenantMiddleware(t *testing.T) {
+	tests := []struct {
+		name           string
+		principal      *domain.Principal
+		header         string
+		expectedStatus int
+		expectedTenant string
+	}{
+		{
+			name:           "defaults to the default tenant",
+			principal:      &domain.Principal{ID: "apikey:primary"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: domain.DefaultTenantID,
+		},
+		{
+			name:           "unbound credentials may name the default tenant",
+			principal:      &domain.Principal{ID: "apikey:primary"},
+			header:         domain.DefaultTenantID,
+			expectedStatus: http.StatusOK,
+			expectedTenant: domain.DefaultTenantID,
+		},
+		{
+			name:           "unbound credentials cannot choose another tenant",
+			principal:      &domain.Principal{ID: "apikey:primary"},
+			header:         "acme",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "bound credentials use their tenant",
+			principal:      &domain.Principal{ID: "user-1", TenantID: "acme"},
+			expectedStatus: http.StatusOK,
+			expectedTenant: "acme",
+		},
+		{
+			name:           "bound credentials may repeat their tenant",
+			principal:      &domain.Principal{ID: "user-1", TenantID: "acme"},
+			header:         "acme",
+			expectedStatus: http.StatusOK,
+			expectedTenant: "acme",
+		},
+		{
+			name:           "bound credentials cannot switch tenant",
+			principal:      &domain.Principal{ID: "user-1", TenantID: "acme"},
+			header:         "globex",
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "bound credentials cannot use the default tenant",
+			principal:      &domain.Principal{ID: "user-1", TenantID: "acme"},
+			header:         domain.DefaultTenantID,
+			expectedStatus: http.StatusForbidden,
+		},
+		{
+			name:           "rejects malformed tenant IDs",
+			principal:      &domain.Principal{ID: "apikey:primary"},
+			header:         "../etc",
+			expectedStatus: http.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e := echo.New()
+			e.Use(func(next echo.HandlerFunc) echo.HandlerFunc {
+				return func(c echo.Context) error {
+					ctx := domain.ContextWithPrincipal(c.Request().Context(), tt.principal)
+					c.SetRequest(c.Request().WithContext(ctx))
+					return next(c)
+				}
+			})
+			e.Use(TenantMiddleware())
+			e.GET("/api/v1/task", func(c echo.Context) error {
+				return c.String(http.StatusOK, domain.TenantFromContext(c.Request().Context()))
+			})
+
+			req := httptest.NewRequest(http.MethodGet, "/api/v1/task", nil)
+			if tt.header != "" {
+				req.Header.Set(headerTenantID, tt.header)
+			}
+			rec := httptest.NewRecorder()
+
+			e.ServeHTTP(rec, req)
+
+			assert.Equal(t, tt.expectedStatus, rec.Code)
+			if tt.expectedTenant != "" {
+				assert.Equal(t, tt.expectedTenant, rec.Body.String())
+			}
+		})
+	}
+}
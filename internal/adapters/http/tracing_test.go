@@ -0,0 +1,58 @@
+package http
+
+import (
+	"net/http"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+)
+
+func TestTracing(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	defer func() {
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	}()
+	e := newTestRouter(WithTracing("test"))
+	task := createTestTask(t, e)
+
+	t.Run("requests are recorded under their route", func(t *testing.T) {
+		exporter.Reset()
+
+		doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "")
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "GET /api/v1/task/:id", spans[0].Name)
+		assert.Contains(t, spans[0].Attributes, semconv.HTTPRoute("/api/v1/task/:id"))
+	})
+
+	t.Run("incoming trace context is continued", func(t *testing.T) {
+		exporter.Reset()
+
+		doRequest(e, http.MethodGet, "/api/v1/task/"+task.ID, "",
+			"traceparent", "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01")
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", spans[0].SpanContext.TraceID().String())
+		assert.Equal(t, "00f067aa0ba902b7", spans[0].Parent.SpanID().String())
+	})
+
+	t.Run("probes are not traced", func(t *testing.T) {
+		exporter.Reset()
+
+		doRequest(e, http.MethodGet, "/healthz", "")
+
+		assert.Empty(t, exporter.GetSpans())
+	})
+}
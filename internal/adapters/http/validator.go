@@ -0,0 +1,92 @@
+package http
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	customerrors "task-tracking-service/pkg/errors"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// RequestValidator checks bound request bodies against their validate tags.
+// It is registered as the Echo validator so handlers can call c.Validate.
+type RequestValidator struct {
+	validate *validator.Validate
+}
+
+// NewRequestValidator creates a validator that reports fields by their JSON
+// names and understands the notpast tag for timestamps.
+func NewRequestValidator() *RequestValidator {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(func(field reflect.StructField) string {
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			return ""
+		}
+		if name == "" {
+			return field.Name
+		}
+		return name
+	})
+	if err := validate.RegisterValidation("notpast", validateNotPast); err != nil {
+		panic(fmt.Sprintf("registering notpast validator: %v", err))
+	}
+	return &RequestValidator{validate: validate}
+}
+
+// Validate returns a ValidationError listing every rule the request breaks
+func (v *RequestValidator) Validate(i interface{}) error {
+	err := v.validate.Struct(i)
+	var violations validator.ValidationErrors
+	if !errors.As(err, &violations) {
+		return err
+	}
+
+	fields := make([]customerrors.FieldError, 0, len(violations))
+	for _, violation := range violations {
+		fields = append(fields, customerrors.FieldError{
+			Field:   violation.Field(),
+			Message: violationMessage(violation),
+		})
+	}
+	return customerrors.NewValidationError("Request validation failed", fields...)
+}
+
+func violationMessage(violation validator.FieldError) string {
+	switch violation.Tag() {
+	case "required":
+		return "is required"
+	case "max":
+		return fmt.Sprintf("must be at most %s", bound(violation))
+	case "min":
+		return fmt.Sprintf("must be at least %s", bound(violation))
+	case "oneof":
+		return "must be one of " + strings.Join(strings.Fields(violation.Param()), ", ")
+	case "notpast":
+		return "must not be in the past"
+	default:
+		return fmt.Sprintf("failed the %s rule", violation.Tag())
+	}
+}
+
+// bound describes the parameter of a min or max rule. Those rules limit
+// the length of strings, the size of collections and the value of numbers.
+func bound(violation validator.FieldError) string {
+	switch violation.Kind() {
+	case reflect.String:
+		return violation.Param() + " characters"
+	case reflect.Slice, reflect.Array, reflect.Map:
+		return violation.Param() + " items"
+	default:
+		return violation.Param()
+	}
+}
+
+func validateNotPast(fl validator.FieldLevel) bool {
+	t, ok := fl.Field().Interface().(time.Time)
+	return ok && !t.Before(time.Now())
+}
@@ -0,0 +1,126 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// TaskRepository records the latency of every call made to the repository
+// it wraps
+type TaskRepository struct {
+	repo     ports.TaskRepository
+	duration *prometheus.HistogramVec
+}
+
+// transactionalTaskRepository is a TaskRepository whose inner repository
+// also implements ports.Transactor
+type transactionalTaskRepository struct {
+	*TaskRepository
+	transactor ports.Transactor
+}
+
+// NewTaskRepository instruments repo, registering its histogram with reg.
+// The result implements ports.Transactor whenever repo does.
+func NewTaskRepository(repo ports.TaskRepository, reg prometheus.Registerer) (ports.TaskRepository, error) {
+	duration := prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "task_repository_operation_duration_seconds",
+		Help:    "Time taken by task repository operations.",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"operation", "outcome"})
+	if err := reg.Register(duration); err != nil {
+		return nil, err
+	}
+
+	instrumented := &TaskRepository{repo: repo, duration: duration}
+	if transactor, ok := repo.(ports.Transactor); ok {
+		return &transactionalTaskRepository{TaskRepository: instrumented, transactor: transactor}, nil
+	}
+	return instrumented, nil
+}
+
+// observe records how long the operation that started at start took. It
+// takes err by reference so that it can be deferred.
+func (r *TaskRepository) observe(operation string, start time.Time, err *error) {
+	outcome := "success"
+	if *err != nil {
+		outcome = "error"
+	}
+	r.duration.WithLabelValues(operation, outcome).Observe(time.Since(start).Seconds())
+}
+
+func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) (err error) {
+	defer r.observe("create", time.Now(), &err)
+	return r.repo.Create(ctx, task)
+}
+
+func (r *TaskRepository) GetByID(ctx context.Context, id string) (task *domain.Task, err error) {
+	defer r.observe("get_by_id", time.Now(), &err)
+	return r.repo.GetByID(ctx, id)
+}
+
+func (r *TaskRepository) List(ctx context.Context, filter domain.TaskFilter) (tasks []*domain.Task, err error) {
+	defer r.observe("list", time.Now(), &err)
+	return r.repo.List(ctx, filter)
+}
+
+func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) (err error) {
+	defer r.observe("update", time.Now(), &err)
+	return r.repo.Update(ctx, task)
+}
+
+func (r *TaskRepository) Delete(ctx context.Context, id string, version int64) (err error) {
+	defer r.observe("delete", time.Now(), &err)
+	return r.repo.Delete(ctx, id, version)
+}
+
+func (r *TaskRepository) GetDeletedByID(ctx context.Context, id string) (task *domain.Task, err error) {
+	defer r.observe("get_deleted_by_id", time.Now(), &err)
+	return r.repo.GetDeletedByID(ctx, id)
+}
+
+func (r *TaskRepository) ListDeleted(ctx context.Context) (tasks []*domain.Task, err error) {
+	defer r.observe("list_deleted", time.Now(), &err)
+	return r.repo.ListDeleted(ctx)
+}
+
+func (r *TaskRepository) Restore(ctx context.Context, id string) (task *domain.Task, err error) {
+	defer r.observe("restore", time.Now(), &err)
+	return r.repo.Restore(ctx, id)
+}
+
+func (r *TaskRepository) Purge(ctx context.Context, id string) (err error) {
+	defer r.observe("purge", time.Now(), &err)
+	return r.repo.Purge(ctx, id)
+}
+
+func (r *TaskRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (purged int64, err error) {
+	defer r.observe("purge_deleted_before", time.Now(), &err)
+	return r.repo.PurgeDeletedBefore(ctx, cutoff)
+}
+
+func (r *TaskRepository) GetArchivedByID(ctx context.Context, id string) (task *domain.Task, err error) {
+	defer r.observe("get_archived_by_id", time.Now(), &err)
+	return r.repo.GetArchivedByID(ctx, id)
+}
+
+func (r *TaskRepository) ArchiveCompletedBefore(ctx context.Context, cutoff time.Time) (archived int64, err error) {
+	defer r.observe("archive_completed_before", time.Now(), &err)
+	return r.repo.ArchiveCompletedBefore(ctx, cutoff)
+}
+
+func (r *TaskRepository) Unarchive(ctx context.Context, id string) (task *domain.Task, err error) {
+	defer r.observe("unarchive", time.Now(), &err)
+	return r.repo.Unarchive(ctx, id)
+}
+
+// WithinTx runs fn in a transaction of the wrapped repository. Calls made
+// inside fn are timed individually.
+func (r *transactionalTaskRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) (err error) {
+	defer r.observe("within_tx", time.Now(), &err)
+	return r.transactor.WithinTx(ctx, fn)
+}
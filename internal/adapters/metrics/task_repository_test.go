@@ -0,0 +1,92 @@
+package metrics
+
+import (
+	"context"
+	"testing"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// plainRepository hides the transaction support of the repository it embeds
+type plainRepository struct {
+	ports.TaskRepository
+}
+
+// sampleCount reports how many calls to operation with outcome were timed
+func sampleCount(t *testing.T, reg *prometheus.Registry, operation, outcome string) uint64 {
+	t.Helper()
+	families, err := reg.Gather()
+	require.NoError(t, err)
+	for _, family := range families {
+		if family.GetName() != "task_repository_operation_duration_seconds" {
+			continue
+		}
+		for _, metric := range family.GetMetric() {
+			labels := map[string]string{}
+			for _, label := range metric.GetLabel() {
+				labels[label.GetName()] = label.GetValue()
+			}
+			if labels["operation"] == operation && labels["outcome"] == outcome {
+				return metric.GetHistogram().GetSampleCount()
+			}
+		}
+	}
+	return 0
+}
+
+func TestTaskRepository(t *testing.T) {
+	reg := prometheus.NewRegistry()
+	repo, err := NewTaskRepository(memory.NewTaskRepository(), reg)
+	require.NoError(t, err)
+	ctx := context.Background()
+
+	task := &domain.Task{Title: "Instrumented"}
+	require.NoError(t, repo.Create(ctx, task))
+	_, err = repo.GetByID(ctx, task.ID)
+	require.NoError(t, err)
+	_, err = repo.GetByID(ctx, "missing")
+	require.Error(t, err)
+
+	t.Run("operations are timed by outcome", func(t *testing.T) {
+		expected := map[[2]string]int{
+			{"create", "success"}:    1,
+			{"get_by_id", "success"}: 1,
+			{"get_by_id", "error"}:   1,
+		}
+		for labels, count := range expected {
+			assert.Equal(t, uint64(count), sampleCount(t, reg, labels[0], labels[1]), labels)
+		}
+	})
+
+	t.Run("transactions are still available", func(t *testing.T) {
+		transactor, ok := repo.(ports.Transactor)
+		require.True(t, ok)
+
+		err := transactor.WithinTx(ctx, func(ctx context.Context) error {
+			return repo.Delete(ctx, task.ID, task.Version)
+		})
+		require.NoError(t, err)
+
+		_, err = repo.GetDeletedByID(ctx, task.ID)
+		assert.NoError(t, err)
+	})
+
+	t.Run("repositories without transactions stay without them", func(t *testing.T) {
+		plain, err := NewTaskRepository(plainRepository{memory.NewTaskRepository()}, prometheus.NewRegistry())
+		require.NoError(t, err)
+
+		_, ok := plain.(ports.Transactor)
+		assert.False(t, ok)
+	})
+
+	t.Run("metrics cannot be registered twice", func(t *testing.T) {
+		_, err := NewTaskRepository(memory.NewTaskRepository(), reg)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,64 @@
+package metrics
+
+import (
+	"context"
+	"time"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// statuses are always reported, so that a status with no tasks reads 0
+// rather than disappearing
+var statuses = []domain.TaskStatus{domain.StatusPending, domain.StatusInProgress, domain.StatusCompleted}
+
+// TaskStatsCollector reports task counts each time it is scraped
+type TaskStatsCollector struct {
+	stats   ports.TaskStatistics
+	timeout time.Duration
+
+	tasks   *prometheus.Desc
+	overdue *prometheus.Desc
+	up      *prometheus.Desc
+}
+
+// NewTaskStatsCollector reads counts from stats, giving up after timeout
+func NewTaskStatsCollector(stats ports.TaskStatistics, timeout time.Duration) *TaskStatsCollector {
+	return &TaskStatsCollector{
+		stats:   stats,
+		timeout: timeout,
+		tasks: prometheus.NewDesc("tasks",
+			"Number of tasks, excluding deleted and archived ones, by status.", []string{"status"}, nil),
+		overdue: prometheus.NewDesc("tasks_overdue",
+			"Number of tasks past their due date that are not completed.", nil, nil),
+		up: prometheus.NewDesc("tasks_stats_up",
+			"Whether the last task count succeeded.", nil, nil),
+	}
+}
+
+func (c *TaskStatsCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.tasks
+	ch <- c.overdue
+	ch <- c.up
+}
+
+// Collect counts the tasks. A failed count is reported through
+// tasks_stats_up instead of failing the whole scrape.
+func (c *TaskStatsCollector) Collect(ch chan<- prometheus.Metric) {
+	ctx, cancel := context.WithTimeout(context.Background(), c.timeout)
+	defer cancel()
+
+	stats, err := c.stats.TaskStats(ctx, time.Now())
+	if err != nil {
+		ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 0)
+		return
+	}
+
+	for _, status := range statuses {
+		ch <- prometheus.MustNewConstMetric(c.tasks, prometheus.GaugeValue, float64(stats.ByStatus[status]), string(status))
+	}
+	ch <- prometheus.MustNewConstMetric(c.overdue, prometheus.GaugeValue, float64(stats.Overdue))
+	ch <- prometheus.MustNewConstMetric(c.up, prometheus.GaugeValue, 1)
+}
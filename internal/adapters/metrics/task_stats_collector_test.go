@@ -0,0 +1,60 @@
+package metrics
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+)
+
+// statsFunc adapts a function to ports.TaskStatistics
+type statsFunc func(ctx context.Context, now time.Time) (domain.TaskStats, error)
+
+func (f statsFunc) TaskStats(ctx context.Context, now time.Time) (domain.TaskStats, error) {
+	return f(ctx, now)
+}
+
+func TestTaskStatsCollector(t *testing.T) {
+	t.Run("reports every status and the overdue count", func(t *testing.T) {
+		collector := NewTaskStatsCollector(statsFunc(func(ctx context.Context, now time.Time) (domain.TaskStats, error) {
+			return domain.TaskStats{
+				ByStatus: map[domain.TaskStatus]int64{domain.StatusPending: 3, domain.StatusCompleted: 1},
+				Overdue:  2,
+			}, nil
+		}), time.Second)
+
+		expected := `
+# HELP tasks Number of tasks, excluding deleted and archived ones, by status.
+# TYPE tasks gauge
+tasks{status="completed"} 1
+tasks{status="in_progress"} 0
+tasks{status="pending"} 3
+# HELP tasks_overdue Number of tasks past their due date that are not completed.
+# TYPE tasks_overdue gauge
+tasks_overdue 2
+# HELP tasks_stats_up Whether the last task count succeeded.
+# TYPE tasks_stats_up gauge
+tasks_stats_up 1
+`
+		assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+	})
+
+	t.Run("failed counts are reported as down", func(t *testing.T) {
+		collector := NewTaskStatsCollector(statsFunc(func(ctx context.Context, now time.Time) (domain.TaskStats, error) {
+			return domain.TaskStats{}, errors.New("connection refused")
+		}), time.Second)
+
+		expected := `
+# HELP tasks_stats_up Whether the last task count succeeded.
+# TYPE tasks_stats_up gauge
+tasks_stats_up 0
+`
+		assert.NoError(t, testutil.CollectAndCompare(collector, strings.NewReader(expected)))
+	})
+}
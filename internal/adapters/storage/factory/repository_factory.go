@@ -1,16 +1,25 @@
 package factory
 
 import (
+	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
 
 	"task-tracking-service/internal/adapters/storage/memory"
 	"task-tracking-service/internal/adapters/storage/postgres"
 	"task-tracking-service/internal/adapters/storage/postgres/migrations"
 	"task-tracking-service/internal/config"
 	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/internal/health"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 )
 
+// migrationsPath is where the PostgreSQL migrations are read from
+const migrationsPath = "internal/adapters/storage/postgres/migrations"
+
 // RepositoryType defines the available repository implementations
 type RepositoryType string
 
@@ -22,13 +31,15 @@ const (
 // RepositoryFactory creates and configures repositories
 type RepositoryFactory struct {
 	config *config.Config
+	logger *slog.Logger
 	db     *sql.DB
 }
 
 // NewRepositoryFactory creates a new repository factory
-func NewRepositoryFactory(config *config.Config) *RepositoryFactory {
+func NewRepositoryFactory(config *config.Config, logger *slog.Logger) *RepositoryFactory {
 	return &RepositoryFactory{
 		config: config,
+		logger: logger,
 	}
 }
 
@@ -36,20 +47,11 @@ func NewRepositoryFactory(config *config.Config) *RepositoryFactory {
 func (f *RepositoryFactory) CreateTaskRepository() (ports.TaskRepository, error) {
 	switch f.config.Repository.Type {
 	case "postgres":
-		if f.db == nil {
-			// Initialize database connection
-			db, err := postgres.NewDB(&f.config.Database)
-			if err != nil {
-				return nil, fmt.Errorf("failed to initialize database: %w", err)
-			}
-			f.db = db
-
-			// Run migrations using the internal path
-			if err := migrations.MigrateDB(db, "internal/adapters/storage/postgres/migrations"); err != nil {
-				return nil, fmt.Errorf("failed to run migrations: %w", err)
-			}
+		db, err := f.database()
+		if err != nil {
+			return nil, err
 		}
-		return postgres.NewTaskRepository(f.db), nil
+		return postgres.NewTaskRepository(db, f.logger), nil
 
 	case "memory":
 		return memory.NewTaskRepository(), nil
@@ -59,6 +61,107 @@ func (f *RepositoryFactory) CreateTaskRepository() (ports.TaskRepository, error)
 	}
 }
 
+// CreateRateLimitStore creates the rate limit store selected by configuration
+func (f *RepositoryFactory) CreateRateLimitStore() (ports.RateLimitStore, error) {
+	switch f.config.RateLimit.Store {
+	case "postgres":
+		db, err := f.database()
+		if err != nil {
+			return nil, err
+		}
+		return postgres.NewRateLimitStore(db), nil
+
+	case "memory":
+		return memory.NewRateLimitStore(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown rate limit store: %s", f.config.RateLimit.Store)
+	}
+}
+
+// CreateIdempotencyStore creates the idempotency key store selected by configuration
+func (f *RepositoryFactory) CreateIdempotencyStore() (ports.IdempotencyStore, error) {
+	switch f.config.Idempotency.Store {
+	case "postgres":
+		db, err := f.database()
+		if err != nil {
+			return nil, err
+		}
+		return postgres.NewIdempotencyStore(db), nil
+
+	case "memory":
+		return memory.NewIdempotencyStore(), nil
+
+	default:
+		return nil, fmt.Errorf("unknown idempotency store: %s", f.config.Idempotency.Store)
+	}
+}
+
+// database opens the shared database connection and runs migrations the
+// first time it is needed.
+func (f *RepositoryFactory) database() (*sql.DB, error) {
+	if f.db != nil {
+		return f.db, nil
+	}
+
+	// Initialize database connection
+	db, err := postgres.NewDB(&f.config.Database)
+	if err != nil {
+		return nil, fmt.Errorf("failed to initialize database: %w", err)
+	}
+
+	// Run migrations using the internal path
+	if err := migrations.MigrateDB(db, migrationsPath); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to run migrations: %w", err)
+	}
+
+	f.logger.Info("connected to database",
+		"host", f.config.Database.Host, "name", f.config.Database.Name, "user", f.config.Database.User)
+	f.db = db
+	return f.db, nil
+}
+
+// RegisterHealthChecks adds readiness checks for the storage created so far.
+// When PostgreSQL is in use, the database must answer a ping and have
+// every migration applied.
+func (f *RepositoryFactory) RegisterHealthChecks(registry *health.Registry) error {
+	if f.db == nil {
+		return nil
+	}
+
+	latest, err := migrations.LatestVersion(migrationsPath)
+	if err != nil {
+		return err
+	}
+
+	db := f.db
+	registry.Register("database", health.CheckerFunc(db.PingContext))
+	registry.Register("migrations", health.CheckerFunc(func(ctx context.Context) error {
+		version, dirty, err := migrations.Version(ctx, db)
+		if err != nil {
+			return err
+		}
+		if dirty {
+			return fmt.Errorf("migration %d did not complete", version)
+		}
+		if version != latest {
+			return fmt.Errorf("database is at migration %d, expected %d", version, latest)
+		}
+		return nil
+	}))
+	return nil
+}
+
+// RegisterMetrics adds connection pool metrics for the storage created so
+// far. Memory storage has none.
+func (f *RepositoryFactory) RegisterMetrics(reg prometheus.Registerer) error {
+	if f.db == nil {
+		return nil
+	}
+	return reg.Register(collectors.NewDBStatsCollector(f.db, f.config.Database.Name))
+}
+
 // Close cleans up any resources (like database connections)
 func (f *RepositoryFactory) Close() error {
 	if f.db != nil {
@@ -1,11 +1,16 @@
 package factory
 
 import (
+	"context"
+	"log/slog"
 	"testing"
+	"time"
 
 	"task-tracking-service/internal/adapters/storage/memory"
 	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/health"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -43,7 +48,7 @@ func TestRepositoryFactory_CreateTaskRepository(t *testing.T) {
 			}
 
 			// Create factory
-			factory := NewRepositoryFactory(cfg)
+			factory := NewRepositoryFactory(cfg, slog.Default())
 
 			// Create repository
 			repo, err := factory.CreateTaskRepository()
@@ -66,9 +71,89 @@ func TestRepositoryFactory_CreateTaskRepository(t *testing.T) {
 	}
 }
 
+func TestRepositoryFactory_CreateRateLimitStore(t *testing.T) {
+	t.Run("create memory store", func(t *testing.T) {
+		factory := NewRepositoryFactory(&config.Config{
+			RateLimit: config.RateLimitConfig{Store: "memory"},
+		}, slog.Default())
+
+		store, err := factory.CreateRateLimitStore()
+
+		require.NoError(t, err)
+		assert.IsType(t, &memory.RateLimitStore{}, store)
+	})
+
+	t.Run("unknown store type", func(t *testing.T) {
+		factory := NewRepositoryFactory(&config.Config{
+			RateLimit: config.RateLimitConfig{Store: "unknown"},
+		}, slog.Default())
+
+		store, err := factory.CreateRateLimitStore()
+
+		assert.Error(t, err)
+		assert.Nil(t, store)
+	})
+}
+
+func TestRepositoryFactory_CreateIdempotencyStore(t *testing.T) {
+	t.Run("create memory store", func(t *testing.T) {
+		factory := NewRepositoryFactory(&config.Config{
+			Idempotency: config.IdempotencyConfig{Store: "memory"},
+		}, slog.Default())
+
+		store, err := factory.CreateIdempotencyStore()
+
+		require.NoError(t, err)
+		assert.IsType(t, &memory.IdempotencyStore{}, store)
+	})
+
+	t.Run("unknown store type", func(t *testing.T) {
+		factory := NewRepositoryFactory(&config.Config{
+			Idempotency: config.IdempotencyConfig{Store: "unknown"},
+		}, slog.Default())
+
+		store, err := factory.CreateIdempotencyStore()
+
+		assert.Error(t, err)
+		assert.Nil(t, store)
+	})
+}
+
+func TestRepositoryFactory_RegisterHealthChecks(t *testing.T) {
+	t.Run("memory storage has nothing to check", func(t *testing.T) {
+		factory := NewRepositoryFactory(&config.Config{
+			Repository: config.RepositoryConfig{Type: "memory"},
+		}, slog.Default())
+		_, err := factory.CreateTaskRepository()
+		require.NoError(t, err)
+		registry := health.NewRegistry(time.Second)
+
+		require.NoError(t, factory.RegisterHealthChecks(registry))
+
+		assert.Empty(t, registry.Check(context.Background()).Components)
+	})
+}
+
+func TestRepositoryFactory_RegisterMetrics(t *testing.T) {
+	t.Run("memory storage has no pool to report", func(t *testing.T) {
+		factory := NewRepositoryFactory(&config.Config{
+			Repository: config.RepositoryConfig{Type: "memory"},
+		}, slog.Default())
+		_, err := factory.CreateTaskRepository()
+		require.NoError(t, err)
+		reg := prometheus.NewRegistry()
+
+		require.NoError(t, factory.RegisterMetrics(reg))
+
+		families, err := reg.Gather()
+		require.NoError(t, err)
+		assert.Empty(t, families)
+	})
+}
+
 func TestNewRepositoryFactory(t *testing.T) {
 	cfg := &config.Config{}
-	factory := NewRepositoryFactory(cfg)
+	factory := NewRepositoryFactory(cfg, slog.Default())
 
 	assert.NotNil(t, factory)
 	assert.Equal(t, cfg, factory.config)
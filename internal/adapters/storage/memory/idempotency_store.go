@@ -0,0 +1,85 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"task-tracking-service/internal/core/ports"
+	"time"
+)
+
+type idempotencyEntry struct {
+	record    ports.IdempotencyRecord
+	expiresAt time.Time
+}
+
+// IdempotencyStore keeps idempotency keys in process memory. Keys are not
+// shared between replicas.
+type IdempotencyStore struct {
+	entries   map[string]*idempotencyEntry
+	mutex     sync.Mutex
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewIdempotencyStore() *IdempotencyStore {
+	return &IdempotencyStore{
+		entries: make(map[string]*idempotencyEntry),
+		now:     time.Now,
+	}
+}
+
+func (s *IdempotencyStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*ports.IdempotencyRecord, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	if entry, exists := s.entries[key]; exists && now.Before(entry.expiresAt) {
+		record := entry.record
+		return &record, nil
+	}
+
+	s.entries[key] = &idempotencyEntry{
+		record:    ports.IdempotencyRecord{Fingerprint: fingerprint},
+		expiresAt: now.Add(ttl),
+	}
+	return nil, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, response ports.IdempotentResponse, ttl time.Duration) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	entry, exists := s.entries[key]
+	if !exists {
+		return nil
+	}
+	entry.record.Response = &response
+	entry.expiresAt = s.now().Add(ttl)
+	return nil
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if entry, exists := s.entries[key]; exists && entry.record.Response == nil {
+		delete(s.entries, key)
+	}
+	return nil
+}
+
+// sweep drops expired keys. Callers must hold the mutex.
+func (s *IdempotencyStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, entry := range s.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(s.entries, key)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package memory
+
+import (
+	"context"
+	"task-tracking-service/internal/core/ports"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	store := NewIdempotencyStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+	response := ports.IdempotentResponse{StatusCode: 201, Body: []byte(`{"id":"1"}`)}
+
+	t.Run("first request claims the key", func(t *testing.T) {
+		record, err := store.Begin(ctx, "key-1", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, record)
+	})
+
+	t.Run("repeat while in flight sees no response", func(t *testing.T) {
+		record, err := store.Begin(ctx, "key-1", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+		assert.Equal(t, "fingerprint", record.Fingerprint)
+		assert.Nil(t, record.Response)
+	})
+
+	t.Run("repeat after completion sees the response", func(t *testing.T) {
+		require.NoError(t, store.Complete(ctx, "key-1", response, time.Hour))
+
+		record, err := store.Begin(ctx, "key-1", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		require.NotNil(t, record)
+		assert.Equal(t, &response, record.Response)
+	})
+
+	t.Run("completed keys are not released", func(t *testing.T) {
+		require.NoError(t, store.Release(ctx, "key-1"))
+
+		record, err := store.Begin(ctx, "key-1", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		assert.NotNil(t, record)
+	})
+
+	t.Run("released keys can be claimed again", func(t *testing.T) {
+		_, err := store.Begin(ctx, "key-2", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		require.NoError(t, store.Release(ctx, "key-2"))
+
+		record, err := store.Begin(ctx, "key-2", "fingerprint", time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, record)
+	})
+
+	t.Run("keys expire after their TTL", func(t *testing.T) {
+		now = now.Add(time.Hour)
+
+		record, err := store.Begin(ctx, "key-1", "other-fingerprint", time.Minute)
+		require.NoError(t, err)
+		assert.Nil(t, record)
+		assert.Len(t, store.entries, 1, "expired keys are swept")
+	})
+}
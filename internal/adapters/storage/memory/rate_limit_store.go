@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"task-tracking-service/internal/core/ports"
+	"time"
+)
+
+// sweepInterval is how often idle buckets are dropped
+const sweepInterval = time.Minute
+
+type bucket struct {
+	tokens    float64
+	updatedAt time.Time
+	limit     ports.RateLimit
+}
+
+// RateLimitStore keeps token buckets in process memory. Limits are not
+// shared between replicas.
+type RateLimitStore struct {
+	buckets   map[string]*bucket
+	mutex     sync.Mutex
+	now       func() time.Time
+	lastSweep time.Time
+}
+
+func NewRateLimitStore() *RateLimitStore {
+	return &RateLimitStore{
+		buckets: make(map[string]*bucket),
+		now:     time.Now,
+	}
+}
+
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ports.RateLimit) (ports.RateLimitResult, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	b, exists := s.buckets[key]
+	if !exists {
+		b = &bucket{tokens: float64(limit.Burst), updatedAt: now}
+		s.buckets[key] = b
+	}
+
+	tokens, result := limit.Take(b.tokens, now.Sub(b.updatedAt))
+	b.tokens = tokens
+	b.updatedAt = now
+	b.limit = limit
+
+	return result, nil
+}
+
+// sweep drops buckets that have refilled completely, since they are
+// indistinguishable from new ones. Callers must hold the mutex.
+func (s *RateLimitStore) sweep(now time.Time) {
+	if now.Sub(s.lastSweep) < sweepInterval {
+		return
+	}
+	s.lastSweep = now
+
+	for key, b := range s.buckets {
+		refilled := b.tokens + now.Sub(b.updatedAt).Seconds()*b.limit.Rate
+		if refilled >= float64(b.limit.Burst) {
+			delete(s.buckets, key)
+		}
+	}
+}
@@ -0,0 +1,60 @@
+package memory
+
+import (
+	"context"
+	"task-tracking-service/internal/core/ports"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitStore_Take(t *testing.T) {
+	store := NewRateLimitStore()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	store.now = func() time.Time { return now }
+	ctx := context.Background()
+	limit := ports.RateLimit{Rate: 1, Burst: 2}
+
+	t.Run("allows a burst then rejects", func(t *testing.T) {
+		first, err := store.Take(ctx, "caller", limit)
+		require.NoError(t, err)
+		assert.True(t, first.Allowed)
+		assert.Equal(t, 1, first.Remaining)
+
+		second, err := store.Take(ctx, "caller", limit)
+		require.NoError(t, err)
+		assert.True(t, second.Allowed)
+		assert.Equal(t, 0, second.Remaining)
+		assert.Equal(t, 2*time.Second, second.ResetAfter)
+
+		third, err := store.Take(ctx, "caller", limit)
+		require.NoError(t, err)
+		assert.False(t, third.Allowed)
+		assert.Equal(t, time.Second, third.RetryAfter)
+	})
+
+	t.Run("refills over time", func(t *testing.T) {
+		now = now.Add(time.Second)
+
+		result, err := store.Take(ctx, "caller", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	})
+
+	t.Run("keeps separate buckets per key", func(t *testing.T) {
+		result, err := store.Take(ctx, "another-caller", limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+		assert.Equal(t, 1, result.Remaining)
+	})
+
+	t.Run("drops idle buckets", func(t *testing.T) {
+		now = now.Add(sweepInterval)
+
+		_, err := store.Take(ctx, "new-caller", limit)
+		require.NoError(t, err)
+		assert.Len(t, store.buckets, 1)
+	})
+}
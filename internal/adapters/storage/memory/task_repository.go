@@ -3,29 +3,43 @@ package memory
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"sync"
 	"task-tracking-service/internal/core/domain"
 	"task-tracking-service/pkg/errors"
+	"time"
 
 	"github.com/google/uuid"
 )
 
 type TaskRepository struct {
 	tasks map[string]*domain.Task
-	mutex sync.RWMutex
+	// archive holds archived tasks, which are kept apart from live ones
+	archive map[string]*domain.Task
+	mutex   sync.RWMutex
+	// writeMutex is held by each transaction, and by writes made outside
+	// one, so that transactions never interleave with other writes
+	writeMutex sync.Mutex
 }
 
 func NewTaskRepository() *TaskRepository {
 	return &TaskRepository{
-		tasks: make(map[string]*domain.Task),
+		tasks:   make(map[string]*domain.Task),
+		archive: make(map[string]*domain.Task),
 	}
 }
 
+// Create stores the task under the tenant carried by ctx
 func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
+	defer r.lockWrites(ctx)()
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
 	task.ID = uuid.New().String()
+	task.TenantID = domain.TenantFromContext(ctx)
+	task.Version = 1
+	r.journal(ctx, task.ID)
 	taskCopy := *task
 	r.tasks[task.ID] = &taskCopy
 
@@ -36,7 +50,7 @@ func (r *TaskRepository) GetByID(ctx context.Context, id string) (*domain.Task,
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	task, exists := r.tasks[id]
+	task, exists := r.find(ctx, id)
 	if !exists {
 		return nil, errors.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
 	}
@@ -45,41 +59,281 @@ func (r *TaskRepository) GetByID(ctx context.Context, id string) (*domain.Task,
 	return &taskCopy, nil
 }
 
-func (r *TaskRepository) List(ctx context.Context) ([]*domain.Task, error) {
+func (r *TaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
 	r.mutex.RLock()
 	defer r.mutex.RUnlock()
 
-	tasks := make([]*domain.Task, 0, len(r.tasks))
-	for _, task := range r.tasks {
-		taskCopy := *task
-		tasks = append(tasks, &taskCopy)
+	tasks := r.list(ctx, false)
+	if filter.IncludeArchived {
+		tenantID := domain.TenantFromContext(ctx)
+		for _, task := range r.archive {
+			if task.TenantID == tenantID {
+				taskCopy := *task
+				tasks = append(tasks, &taskCopy)
+			}
+		}
 	}
 
+	tasks = slices.DeleteFunc(tasks, func(task *domain.Task) bool {
+		return !filter.Matches(task)
+	})
+	sort.Slice(tasks, func(i, j int) bool {
+		return tasks[i].Cursor().Precedes(tasks[j].Cursor())
+	})
+	if filter.After != nil {
+		start := sort.Search(len(tasks), func(i int) bool {
+			return filter.After.Precedes(tasks[i].Cursor())
+		})
+		tasks = tasks[start:]
+	}
+	if filter.LimitPerProject > 0 {
+		perProject := make(map[string]int)
+		tasks = slices.DeleteFunc(tasks, func(task *domain.Task) bool {
+			perProject[task.ProjectID]++
+			return perProject[task.ProjectID] > filter.LimitPerProject
+		})
+	}
+	if filter.Limit > 0 && len(tasks) > filter.Limit {
+		tasks = tasks[:filter.Limit]
+	}
 	return tasks, nil
 }
 
+// Update replaces the task if it is still at task.Version, then bumps the version
 func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	defer r.lockWrites(ctx)()
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if _, exists := r.tasks[task.ID]; !exists {
+	stored, exists := r.find(ctx, task.ID)
+	if !exists {
 		return errors.NewNotFoundError(fmt.Sprintf("task with ID %s not found", task.ID))
 	}
+	if stored.Version != task.Version {
+		return errors.ErrTaskVersionConflict
+	}
 
+	task.TenantID = domain.TenantFromContext(ctx)
+	task.Version++
+	task.DeletedAt = nil
+	r.journal(ctx, task.ID)
 	taskCopy := *task
 	r.tasks[task.ID] = &taskCopy
 
 	return nil
 }
 
-func (r *TaskRepository) Delete(ctx context.Context, id string) error {
+// Delete moves the task to the trash if it is still at the given version
+func (r *TaskRepository) Delete(ctx context.Context, id string, version int64) error {
+	defer r.lockWrites(ctx)()
 	r.mutex.Lock()
 	defer r.mutex.Unlock()
 
-	if _, exists := r.tasks[id]; !exists {
+	stored, exists := r.find(ctx, id)
+	if !exists {
 		return errors.NewNotFoundError(fmt.Sprintf("task with ID %s not found", id))
 	}
+	if stored.Version != version {
+		return errors.ErrTaskVersionConflict
+	}
+
+	deletedAt := time.Now()
+	taskCopy := *stored
+	taskCopy.DeletedAt = &deletedAt
+	taskCopy.Version++
+	r.journal(ctx, id)
+	r.tasks[id] = &taskCopy
+	return nil
+}
+
+func (r *TaskRepository) GetDeletedByID(ctx context.Context, id string) (*domain.Task, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	task, exists := r.findDeleted(ctx, id)
+	if !exists {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("deleted task with ID %s not found", id))
+	}
+
+	taskCopy := *task
+	return &taskCopy, nil
+}
 
+func (r *TaskRepository) ListDeleted(ctx context.Context) ([]*domain.Task, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	// Most recently deleted first, as in the PostgreSQL repository
+	tasks := r.list(ctx, true)
+	sort.Slice(tasks, func(i, j int) bool {
+		if !tasks[i].DeletedAt.Equal(*tasks[j].DeletedAt) {
+			return tasks[i].DeletedAt.After(*tasks[j].DeletedAt)
+		}
+		return tasks[i].ID < tasks[j].ID
+	})
+	return tasks, nil
+}
+
+// Restore takes the task out of the trash and bumps its version
+func (r *TaskRepository) Restore(ctx context.Context, id string) (*domain.Task, error) {
+	defer r.lockWrites(ctx)()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.findDeleted(ctx, id)
+	if !exists {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("deleted task with ID %s not found", id))
+	}
+
+	taskCopy := *stored
+	taskCopy.DeletedAt = nil
+	taskCopy.Version++
+	r.journal(ctx, id)
+	r.tasks[id] = &taskCopy
+
+	restored := taskCopy
+	return &restored, nil
+}
+
+// Purge permanently removes the task from the trash
+func (r *TaskRepository) Purge(ctx context.Context, id string) error {
+	defer r.lockWrites(ctx)()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.findDeleted(ctx, id); !exists {
+		return errors.NewNotFoundError(fmt.Sprintf("deleted task with ID %s not found", id))
+	}
+
+	r.journal(ctx, id)
 	delete(r.tasks, id)
 	return nil
 }
+
+// PurgeDeletedBefore removes tasks of every tenant deleted before cutoff
+func (r *TaskRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	defer r.lockWrites(ctx)()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	var purged int64
+	for id, task := range r.tasks {
+		if task.DeletedAt != nil && task.DeletedAt.Before(cutoff) {
+			r.journal(ctx, id)
+			delete(r.tasks, id)
+			purged++
+		}
+	}
+	return purged, nil
+}
+
+func (r *TaskRepository) GetArchivedByID(ctx context.Context, id string) (*domain.Task, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	task, exists := r.archive[id]
+	if !exists || task.TenantID != domain.TenantFromContext(ctx) {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("archived task with ID %s not found", id))
+	}
+
+	taskCopy := *task
+	return &taskCopy, nil
+}
+
+// ArchiveCompletedBefore moves tasks of every tenant that were completed
+// before cutoff into the archive
+func (r *TaskRepository) ArchiveCompletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	defer r.lockWrites(ctx)()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	archivedAt := time.Now()
+	var archived int64
+	for id, task := range r.tasks {
+		if task.Status != domain.StatusCompleted || task.DeletedAt != nil || !task.UpdatedAt.Before(cutoff) {
+			continue
+		}
+		r.journal(ctx, id)
+		taskCopy := *task
+		taskCopy.ArchivedAt = &archivedAt
+		r.archive[id] = &taskCopy
+		delete(r.tasks, id)
+		archived++
+	}
+	return archived, nil
+}
+
+// Unarchive moves the task out of the archive and bumps its version
+func (r *TaskRepository) Unarchive(ctx context.Context, id string) (*domain.Task, error) {
+	defer r.lockWrites(ctx)()
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	stored, exists := r.archive[id]
+	if !exists || stored.TenantID != domain.TenantFromContext(ctx) {
+		return nil, errors.NewNotFoundError(fmt.Sprintf("archived task with ID %s not found", id))
+	}
+
+	taskCopy := *stored
+	taskCopy.ArchivedAt = nil
+	taskCopy.Version++
+	r.journal(ctx, id)
+	r.tasks[id] = &taskCopy
+	delete(r.archive, id)
+
+	unarchived := taskCopy
+	return &unarchived, nil
+}
+
+// TaskStats counts the live tasks of every tenant by status
+func (r *TaskRepository) TaskStats(ctx context.Context, now time.Time) (domain.TaskStats, error) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	stats := domain.TaskStats{ByStatus: make(map[domain.TaskStatus]int64)}
+	for _, task := range r.tasks {
+		if task.DeletedAt != nil {
+			continue
+		}
+		stats.ByStatus[task.Status]++
+		if task.Status != domain.StatusCompleted && task.DueDate.Before(now) {
+			stats.Overdue++
+		}
+	}
+	return stats, nil
+}
+
+// find looks up a task that is not in the trash within the tenant carried
+// by ctx. Tasks owned by other tenants are reported as missing. Callers
+// must hold the mutex.
+func (r *TaskRepository) find(ctx context.Context, id string) (*domain.Task, bool) {
+	task, exists := r.tasks[id]
+	if !exists || task.TenantID != domain.TenantFromContext(ctx) || task.DeletedAt != nil {
+		return nil, false
+	}
+	return task, true
+}
+
+// findDeleted is find for tasks in the trash
+func (r *TaskRepository) findDeleted(ctx context.Context, id string) (*domain.Task, bool) {
+	task, exists := r.tasks[id]
+	if !exists || task.TenantID != domain.TenantFromContext(ctx) || task.DeletedAt == nil {
+		return nil, false
+	}
+	return task, true
+}
+
+// list copies the tenant's tasks that are, or are not, in the trash.
+// Callers must hold the mutex.
+func (r *TaskRepository) list(ctx context.Context, deleted bool) []*domain.Task {
+	tenantID := domain.TenantFromContext(ctx)
+	tasks := make([]*domain.Task, 0, len(r.tasks))
+	for _, task := range r.tasks {
+		if task.TenantID != tenantID || (task.DeletedAt != nil) != deleted {
+			continue
+		}
+		taskCopy := *task
+		tasks = append(tasks, &taskCopy)
+	}
+	return tasks
+}
@@ -2,12 +2,14 @@ package memory
 
 import (
 	"context"
+	"fmt"
 	"task-tracking-service/internal/core/domain"
 	"task-tracking-service/pkg/errors"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestTaskRepository_Create(t *testing.T) {
@@ -138,7 +140,7 @@ func TestTaskRepository_List(t *testing.T) {
 	ctx := context.Background()
 
 	t.Run("returns empty list when no tasks exist", func(t *testing.T) {
-		tasks, err := repo.List(ctx)
+		tasks, err := repo.List(ctx, domain.TaskFilter{})
 		assert.NoError(t, err)
 		assert.Empty(t, tasks)
 	})
@@ -153,12 +155,86 @@ func TestTaskRepository_List(t *testing.T) {
 		err = repo.Create(ctx, task2)
 		assert.NoError(t, err)
 
-		tasks, err := repo.List(ctx)
+		tasks, err := repo.List(ctx, domain.TaskFilter{})
 		assert.NoError(t, err)
 		assert.Len(t, tasks, 2)
 	})
 }
 
+func TestTaskRepository_ListPages(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+	start := time.Now()
+	for i := 0; i < 5; i++ {
+		task := &domain.Task{Title: fmt.Sprintf("Task %d", i), Status: domain.StatusPending, CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+		require.NoError(t, repo.Create(ctx, task))
+	}
+
+	var titles []string
+	filter := domain.TaskFilter{Limit: 2}
+	for {
+		page, err := repo.List(ctx, filter)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		for _, task := range page {
+			titles = append(titles, task.Title)
+		}
+		cursor := page[len(page)-1].Cursor()
+		filter.After = &cursor
+	}
+
+	assert.Equal(t, []string{"Task 4", "Task 3", "Task 2", "Task 1", "Task 0"}, titles)
+}
+
+func TestTaskRepository_ListFilters(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+	start := time.Now()
+	dueAt := start.Add(24 * time.Hour)
+	var ids []string
+	for i, projectID := range []string{"proj-1", "proj-2", "", "proj-1"} {
+		task := &domain.Task{Title: fmt.Sprintf("Task %d", i), ProjectID: projectID, Status: domain.StatusPending, CreatedAt: start.Add(time.Duration(i) * time.Minute)}
+		// Odd tasks are completed; even ones are due before dueAt
+		task.DueDate = dueAt.Add(-time.Hour)
+		if i%2 == 1 {
+			task.Status = domain.StatusCompleted
+			task.DueDate = dueAt
+		}
+		require.NoError(t, repo.Create(ctx, task))
+		ids = append(ids, task.ID)
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.TaskFilter
+		want   []string
+	}{
+		{name: "by project", filter: domain.TaskFilter{ProjectIDs: []string{"proj-1"}}, want: []string{"Task 3", "Task 0"}},
+		{name: "by several projects", filter: domain.TaskFilter{ProjectIDs: []string{"proj-2", ""}}, want: []string{"Task 2", "Task 1"}},
+		{name: "limit applies after filtering", filter: domain.TaskFilter{ProjectIDs: []string{"proj-1", "proj-2"}, Limit: 2}, want: []string{"Task 3", "Task 1"}},
+		{name: "by status", filter: domain.TaskFilter{Statuses: []domain.TaskStatus{domain.StatusCompleted}}, want: []string{"Task 3", "Task 1"}},
+		{name: "by due date", filter: domain.TaskFilter{DueBefore: &dueAt, DueAfter: &start}, want: []string{"Task 2", "Task 0"}},
+		{name: "by ID", filter: domain.TaskFilter{IDs: []string{ids[0], ids[2], "missing"}}, want: []string{"Task 2", "Task 0"}},
+		{name: "limit per project", filter: domain.TaskFilter{LimitPerProject: 1}, want: []string{"Task 3", "Task 2", "Task 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := repo.List(ctx, tt.filter)
+			require.NoError(t, err)
+
+			var titles []string
+			for _, task := range tasks {
+				titles = append(titles, task.Title)
+			}
+			assert.Equal(t, tt.want, titles)
+		})
+	}
+}
+
 func TestTaskRepository_Update(t *testing.T) {
 	repo := NewTaskRepository()
 	ctx := context.Background()
@@ -183,6 +259,7 @@ func TestTaskRepository_Update(t *testing.T) {
 			DueDate:     task.DueDate,
 			CreatedAt:   task.CreatedAt,
 			UpdatedAt:   time.Now(),
+			Version:     task.Version,
 		}
 
 		err = repo.Update(ctx, updatedTask)
@@ -217,8 +294,9 @@ func TestTaskRepository_Update(t *testing.T) {
 
 		// Create update with modifications
 		updateTask := &domain.Task{
-			ID:    task.ID,
-			Title: "Updated Task",
+			ID:      task.ID,
+			Title:   "Updated Task",
+			Version: task.Version,
 		}
 		err = repo.Update(ctx, updateTask)
 		assert.NoError(t, err)
@@ -231,6 +309,30 @@ func TestTaskRepository_Update(t *testing.T) {
 		assert.NoError(t, err)
 		assert.Equal(t, "Updated Task", stored.Title)
 	})
+
+	t.Run("compares and swaps on version", func(t *testing.T) {
+		task := &domain.Task{Title: "Versioned Task"}
+		err := repo.Create(ctx, task)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(1), task.Version)
+
+		first := *task
+		first.Title = "First Writer"
+		err = repo.Update(ctx, &first)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), first.Version)
+
+		// A second writer still holding version 1 must not overwrite the first
+		second := *task
+		second.Title = "Second Writer"
+		err = repo.Update(ctx, &second)
+		assert.ErrorIs(t, err, errors.ErrTaskVersionConflict)
+
+		stored, err := repo.GetByID(ctx, task.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "First Writer", stored.Title)
+		assert.Equal(t, int64(2), stored.Version)
+	})
 }
 
 func TestTaskRepository_Delete(t *testing.T) {
@@ -242,7 +344,7 @@ func TestTaskRepository_Delete(t *testing.T) {
 		err := repo.Create(ctx, task)
 		assert.NoError(t, err)
 
-		err = repo.Delete(ctx, task.ID)
+		err = repo.Delete(ctx, task.ID, task.Version)
 		assert.NoError(t, err)
 
 		// Verify deletion
@@ -252,8 +354,307 @@ func TestTaskRepository_Delete(t *testing.T) {
 	})
 
 	t.Run("returns error when deleting non-existent task", func(t *testing.T) {
-		err := repo.Delete(ctx, "non-existent-id")
+		err := repo.Delete(ctx, "non-existent-id", 1)
 		assert.Error(t, err)
 		assert.IsType(t, &errors.NotFoundError{}, err)
 	})
+
+	t.Run("refuses to delete a task at another version", func(t *testing.T) {
+		task := &domain.Task{Title: "Task to Keep"}
+		err := repo.Create(ctx, task)
+		assert.NoError(t, err)
+
+		err = repo.Delete(ctx, task.ID, task.Version+1)
+		assert.ErrorIs(t, err, errors.ErrTaskVersionConflict)
+
+		_, err = repo.GetByID(ctx, task.ID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTaskRepository_Trash(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+
+	task := &domain.Task{Title: "Deleted by mistake"}
+	assert.NoError(t, repo.Create(ctx, task))
+	assert.NoError(t, repo.Delete(ctx, task.ID, task.Version))
+
+	t.Run("deleted tasks are only listed in the trash", func(t *testing.T) {
+		tasks, err := repo.List(ctx, domain.TaskFilter{})
+		assert.NoError(t, err)
+		assert.Empty(t, tasks)
+
+		trash, err := repo.ListDeleted(ctx)
+		assert.NoError(t, err)
+		assert.Len(t, trash, 1)
+		assert.NotNil(t, trash[0].DeletedAt)
+		assert.Equal(t, int64(2), trash[0].Version)
+
+		_, err = repo.GetDeletedByID(domain.ContextWithTenant(ctx, "other"), task.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+
+	t.Run("the most recently deleted tasks are listed first", func(t *testing.T) {
+		repo := NewTaskRepository()
+		var deleted []string
+		for _, title := range []string{"First", "Second", "Third"} {
+			task := &domain.Task{Title: title}
+			assert.NoError(t, repo.Create(ctx, task))
+			assert.NoError(t, repo.Delete(ctx, task.ID, task.Version))
+			deleted = append([]string{task.ID}, deleted...)
+			// Deletion times must differ for the order to be defined
+			time.Sleep(time.Millisecond)
+		}
+
+		trash, err := repo.ListDeleted(ctx)
+		assert.NoError(t, err)
+		ids := make([]string, len(trash))
+		for i, task := range trash {
+			ids[i] = task.ID
+		}
+		assert.Equal(t, deleted, ids)
+	})
+
+	t.Run("restore brings the task back", func(t *testing.T) {
+		restored, err := repo.Restore(ctx, task.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, restored.DeletedAt)
+		assert.Equal(t, int64(3), restored.Version)
+
+		stored, err := repo.GetByID(ctx, task.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, restored, stored)
+
+		_, err = repo.Restore(ctx, task.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+
+	t.Run("purge only removes tasks in the trash", func(t *testing.T) {
+		assert.True(t, errors.IsNotFoundError(repo.Purge(ctx, task.ID)))
+
+		assert.NoError(t, repo.Delete(ctx, task.ID, 3))
+		assert.NoError(t, repo.Purge(ctx, task.ID))
+
+		_, err := repo.GetDeletedByID(ctx, task.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+
+	t.Run("expired tasks are purged for every tenant", func(t *testing.T) {
+		other := domain.ContextWithTenant(ctx, "other")
+		live := &domain.Task{Title: "Live"}
+		assert.NoError(t, repo.Create(ctx, live))
+		for _, tenantCtx := range []context.Context{ctx, other} {
+			deleted := &domain.Task{Title: "Deleted"}
+			assert.NoError(t, repo.Create(tenantCtx, deleted))
+			assert.NoError(t, repo.Delete(tenantCtx, deleted.ID, deleted.Version))
+		}
+
+		purged, err := repo.PurgeDeletedBefore(ctx, time.Now().Add(-time.Hour))
+		assert.NoError(t, err)
+		assert.Zero(t, purged)
+
+		purged, err = repo.PurgeDeletedBefore(ctx, time.Now().Add(time.Second))
+		assert.NoError(t, err)
+		assert.Equal(t, int64(2), purged)
+
+		_, err = repo.GetByID(ctx, live.ID)
+		assert.NoError(t, err)
+	})
+}
+
+func TestTaskRepository_Archive(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+
+	old := &domain.Task{Title: "Shipped", Status: domain.StatusCompleted, UpdatedAt: time.Now().Add(-48 * time.Hour)}
+	assert.NoError(t, repo.Create(ctx, old))
+	recent := &domain.Task{Title: "Just shipped", Status: domain.StatusCompleted, UpdatedAt: time.Now()}
+	assert.NoError(t, repo.Create(ctx, recent))
+	open := &domain.Task{Title: "Open", Status: domain.StatusPending, UpdatedAt: time.Now().Add(-48 * time.Hour)}
+	assert.NoError(t, repo.Create(ctx, open))
+
+	archived, err := repo.ArchiveCompletedBefore(ctx, time.Now().Add(-24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+
+	t.Run("archived tasks are only listed on request", func(t *testing.T) {
+		_, err := repo.GetByID(ctx, old.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+
+		stored, err := repo.GetArchivedByID(ctx, old.ID)
+		assert.NoError(t, err)
+		assert.NotNil(t, stored.ArchivedAt)
+
+		tasks, err := repo.List(ctx, domain.TaskFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 2)
+
+		tasks, err = repo.List(ctx, domain.TaskFilter{IncludeArchived: true})
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 3)
+	})
+
+	t.Run("unarchive brings the task back", func(t *testing.T) {
+		unarchived, err := repo.Unarchive(ctx, old.ID)
+		assert.NoError(t, err)
+		assert.Nil(t, unarchived.ArchivedAt)
+		assert.Equal(t, int64(2), unarchived.Version)
+
+		_, err = repo.GetByID(ctx, old.ID)
+		assert.NoError(t, err)
+		_, err = repo.Unarchive(ctx, old.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+
+	t.Run("failed transactions undo archival", func(t *testing.T) {
+		err := repo.WithinTx(ctx, func(ctx context.Context) error {
+			_, err := repo.ArchiveCompletedBefore(ctx, time.Now().Add(-24*time.Hour))
+			assert.NoError(t, err)
+			return assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+
+		_, err = repo.GetByID(ctx, old.ID)
+		assert.NoError(t, err)
+		_, err = repo.GetArchivedByID(ctx, old.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+}
+
+func TestTaskRepository_TaskStats(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+	now := time.Now()
+
+	tasks := []*domain.Task{
+		{Title: "Overdue", Status: domain.StatusPending, DueDate: now.Add(-time.Hour)},
+		{Title: "Upcoming", Status: domain.StatusInProgress, DueDate: now.Add(time.Hour)},
+		{Title: "Done late", Status: domain.StatusCompleted, DueDate: now.Add(-time.Hour)},
+	}
+	for _, task := range tasks {
+		assert.NoError(t, repo.Create(ctx, task))
+	}
+	other := &domain.Task{Title: "Other tenant", Status: domain.StatusPending, DueDate: now.Add(-time.Hour)}
+	assert.NoError(t, repo.Create(domain.ContextWithTenant(ctx, "other"), other))
+	deleted := &domain.Task{Title: "Deleted", Status: domain.StatusPending, DueDate: now.Add(-time.Hour)}
+	assert.NoError(t, repo.Create(ctx, deleted))
+	assert.NoError(t, repo.Delete(ctx, deleted.ID, deleted.Version))
+
+	stats, err := repo.TaskStats(ctx, now)
+	assert.NoError(t, err)
+	assert.Equal(t, map[domain.TaskStatus]int64{
+		domain.StatusPending:    2,
+		domain.StatusInProgress: 1,
+		domain.StatusCompleted:  1,
+	}, stats.ByStatus)
+	assert.Equal(t, int64(2), stats.Overdue)
+}
+
+func TestTaskRepository_TenantIsolation(t *testing.T) {
+	repo := NewTaskRepository()
+	tenantA := domain.ContextWithTenant(context.Background(), "tenant-a")
+	tenantB := domain.ContextWithTenant(context.Background(), "tenant-b")
+
+	task := &domain.Task{Title: "Tenant A Task", Status: domain.StatusPending}
+	err := repo.Create(tenantA, task)
+	assert.NoError(t, err)
+	assert.Equal(t, "tenant-a", task.TenantID)
+
+	t.Run("other tenants cannot read the task", func(t *testing.T) {
+		_, err := repo.GetByID(tenantB, task.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+
+		tasks, err := repo.List(tenantB, domain.TaskFilter{})
+		assert.NoError(t, err)
+		assert.Empty(t, tasks)
+	})
+
+	t.Run("other tenants cannot modify the task", func(t *testing.T) {
+		err := repo.Update(tenantB, &domain.Task{ID: task.ID, Title: "Hijacked"})
+		assert.True(t, errors.IsNotFoundError(err))
+
+		err = repo.Delete(tenantB, task.ID, task.Version)
+		assert.True(t, errors.IsNotFoundError(err))
+
+		stored, err := repo.GetByID(tenantA, task.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Tenant A Task", stored.Title)
+	})
+
+	t.Run("requests without a tenant use the default tenant", func(t *testing.T) {
+		_, err := repo.GetByID(context.Background(), task.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+
+		tasks, err := repo.List(tenantA, domain.TaskFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 1)
+	})
+}
+
+func TestTaskRepository_WithinTx(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+
+	kept := &domain.Task{Title: "Kept"}
+	assert.NoError(t, repo.Create(ctx, kept))
+	removed := &domain.Task{Title: "Removed"}
+	assert.NoError(t, repo.Create(ctx, removed))
+
+	t.Run("failed transactions undo their writes", func(t *testing.T) {
+		err := repo.WithinTx(ctx, func(ctx context.Context) error {
+			assert.NoError(t, repo.Create(ctx, &domain.Task{Title: "Created"}))
+			assert.NoError(t, repo.Update(ctx, &domain.Task{ID: kept.ID, Title: "Changed", Version: kept.Version}))
+			assert.NoError(t, repo.Delete(ctx, removed.ID, removed.Version))
+			return assert.AnError
+		})
+		assert.ErrorIs(t, err, assert.AnError)
+
+		tasks, err := repo.List(ctx, domain.TaskFilter{})
+		assert.NoError(t, err)
+		assert.Len(t, tasks, 2)
+		stored, err := repo.GetByID(ctx, kept.ID)
+		assert.NoError(t, err)
+		assert.Equal(t, "Kept", stored.Title)
+		assert.Equal(t, kept.Version, stored.Version)
+	})
+
+	t.Run("successful transactions keep their writes", func(t *testing.T) {
+		err := repo.WithinTx(ctx, func(ctx context.Context) error {
+			return repo.Delete(ctx, removed.ID, removed.Version)
+		})
+		assert.NoError(t, err)
+
+		_, err = repo.GetByID(ctx, removed.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+}
+
+func TestTaskRepository_WithinTxBlocksOtherWrites(t *testing.T) {
+	repo := NewTaskRepository()
+	ctx := context.Background()
+
+	task := &domain.Task{Title: "Contended"}
+	assert.NoError(t, repo.Create(ctx, task))
+
+	written := make(chan error)
+	err := repo.WithinTx(ctx, func(txCtx context.Context) error {
+		go func() {
+			written <- repo.Update(ctx, &domain.Task{ID: task.ID, Title: "Outside", Version: 1})
+		}()
+
+		select {
+		case <-written:
+			t.Error("write outside the transaction did not wait for it")
+		case <-time.After(50 * time.Millisecond):
+		}
+		return repo.Update(txCtx, &domain.Task{ID: task.ID, Title: "Inside", Version: 1})
+	})
+	assert.NoError(t, err)
+
+	// The waiting write now sees the version the transaction committed
+	assert.ErrorIs(t, <-written, errors.ErrTaskVersionConflict)
+	stored, err := repo.GetByID(ctx, task.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "Inside", stored.Title)
 }
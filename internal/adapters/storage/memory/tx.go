@@ -0,0 +1,73 @@
+package memory
+
+import (
+	"context"
+	"task-tracking-service/internal/core/domain"
+)
+
+type txContextKey struct{}
+
+// txJournal records how to undo each write made within a transaction
+type txJournal struct {
+	undo []func()
+}
+
+// WithinTx runs fn while holding the repository's write lock, and undoes
+// its writes if it fails. Other writers wait for the transaction to finish,
+// but readers may see its writes before it does. Calls nested inside an
+// existing transaction join it.
+func (r *TaskRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if _, ok := ctx.Value(txContextKey{}).(*txJournal); ok {
+		return fn(ctx)
+	}
+
+	r.writeMutex.Lock()
+	defer r.writeMutex.Unlock()
+
+	journal := &txJournal{}
+	if err := fn(context.WithValue(ctx, txContextKey{}, journal)); err != nil {
+		r.mutex.Lock()
+		defer r.mutex.Unlock()
+		for i := len(journal.undo) - 1; i >= 0; i-- {
+			journal.undo[i]()
+		}
+		return err
+	}
+	return nil
+}
+
+// lockWrites takes the write lock for a single write made outside a
+// transaction, and returns the function that releases it. Writes within a
+// transaction already hold it.
+func (r *TaskRepository) lockWrites(ctx context.Context) func() {
+	if _, ok := ctx.Value(txContextKey{}).(*txJournal); ok {
+		return func() {}
+	}
+	r.writeMutex.Lock()
+	return r.writeMutex.Unlock
+}
+
+// journal remembers the current state of the task so that a failing
+// transaction can restore it. Callers must hold the mutex.
+func (r *TaskRepository) journal(ctx context.Context, id string) {
+	journal, ok := ctx.Value(txContextKey{}).(*txJournal)
+	if !ok {
+		return
+	}
+
+	previous, existed := r.tasks[id]
+	archived, wasArchived := r.archive[id]
+	journal.undo = append(journal.undo, func() {
+		restoreEntry(r.tasks, id, previous, existed)
+		restoreEntry(r.archive, id, archived, wasArchived)
+	})
+}
+
+// restoreEntry puts back, or removes, one entry of a task map
+func restoreEntry(tasks map[string]*domain.Task, id string, task *domain.Task, existed bool) {
+	if existed {
+		tasks[id] = task
+	} else {
+		delete(tasks, id)
+	}
+}
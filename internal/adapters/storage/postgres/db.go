@@ -10,15 +10,21 @@ import (
 	_ "github.com/lib/pq"
 )
 
+// applicationName identifies the service's sessions in pg_stat_activity,
+// unless PGAPPNAME says otherwise
+const applicationName = "task-tracking-service"
+
 func NewDB(cfg *config.DatabaseConfig) (*sql.DB, error) {
 	connStr := fmt.Sprintf(
-		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s",
+		"host=%s port=%s user=%s password=%s dbname=%s sslmode=%s fallback_application_name=%s",
 		cfg.Host,
 		cfg.Port,
 		cfg.User,
-		cfg.Password,
+		// SensitiveValue redacts itself when formatted
+		string(cfg.Password),
 		cfg.Name,
 		cfg.SSLMode,
+		applicationName,
 	)
 
 	db, err := sql.Open("postgres", connStr)
@@ -0,0 +1,119 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"sync"
+	"time"
+
+	"task-tracking-service/internal/core/ports"
+)
+
+// IdempotencyStore keeps idempotency keys in PostgreSQL so that a retry is
+// recognised whichever replica it reaches. Expiry uses the database clock.
+type IdempotencyStore struct {
+	db *sql.DB
+
+	mutex     sync.Mutex
+	lastSweep time.Time
+}
+
+func NewIdempotencyStore(db *sql.DB) *IdempotencyStore {
+	return &IdempotencyStore{
+		db: db,
+	}
+}
+
+// Begin claims the key with a single upsert, which only takes over an
+// existing row once it has expired, so concurrent requests cannot both win.
+func (s *IdempotencyStore) Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*ports.IdempotencyRecord, error) {
+	if err := s.sweep(ctx); err != nil {
+		return nil, err
+	}
+
+	var claimed bool
+	err := conn(ctx, s.db).QueryRowContext(ctx, `
+		INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, $2, now() + make_interval(secs => $3))
+		ON CONFLICT (key) DO UPDATE
+		SET fingerprint = EXCLUDED.fingerprint, status_code = NULL, header = NULL, body = NULL,
+			expires_at = EXCLUDED.expires_at
+		WHERE idempotency_keys.expires_at <= now()
+		RETURNING true`,
+		key, fingerprint, ttl.Seconds(),
+	).Scan(&claimed)
+	if err == nil {
+		return nil, nil
+	}
+	if err != sql.ErrNoRows {
+		return nil, fmt.Errorf("failed to claim idempotency key: %w", err)
+	}
+
+	var (
+		record     ports.IdempotencyRecord
+		statusCode sql.NullInt64
+		header     []byte
+		body       []byte
+	)
+	err = conn(ctx, s.db).QueryRowContext(ctx, `
+		SELECT fingerprint, status_code, header, body
+		FROM idempotency_keys
+		WHERE key = $1`,
+		key,
+	).Scan(&record.Fingerprint, &statusCode, &header, &body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+
+	if statusCode.Valid {
+		record.Response = &ports.IdempotentResponse{StatusCode: int(statusCode.Int64), Body: body}
+		if err := json.Unmarshal(header, &record.Response.Header); err != nil {
+			return nil, fmt.Errorf("failed to decode stored response header: %w", err)
+		}
+	}
+	return &record, nil
+}
+
+func (s *IdempotencyStore) Complete(ctx context.Context, key string, response ports.IdempotentResponse, ttl time.Duration) error {
+	header, err := json.Marshal(response.Header)
+	if err != nil {
+		return fmt.Errorf("failed to encode response header: %w", err)
+	}
+
+	_, err = conn(ctx, s.db).ExecContext(ctx, `
+		UPDATE idempotency_keys
+		SET status_code = $1, header = $2, body = $3, expires_at = now() + make_interval(secs => $4)
+		WHERE key = $5`,
+		response.StatusCode, header, response.Body, ttl.Seconds(), key,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to store idempotent response: %w", err)
+	}
+	return nil
+}
+
+func (s *IdempotencyStore) Release(ctx context.Context, key string) error {
+	_, err := conn(ctx, s.db).ExecContext(ctx, `DELETE FROM idempotency_keys WHERE key = $1 AND status_code IS NULL`, key)
+	if err != nil {
+		return fmt.Errorf("failed to release idempotency key: %w", err)
+	}
+	return nil
+}
+
+// sweep deletes expired keys, at most once per sweepInterval
+func (s *IdempotencyStore) sweep(ctx context.Context) error {
+	s.mutex.Lock()
+	if time.Since(s.lastSweep) < sweepInterval {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.lastSweep = time.Now()
+	s.mutex.Unlock()
+
+	if _, err := conn(ctx, s.db).ExecContext(ctx, `DELETE FROM idempotency_keys WHERE expires_at <= now()`); err != nil {
+		return fmt.Errorf("failed to sweep idempotency keys: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,104 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestIdempotencyStore(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			key TEXT PRIMARY KEY,
+			fingerprint TEXT NOT NULL,
+			status_code INTEGER,
+			header JSONB,
+			body BYTEA,
+			expires_at TIMESTAMPTZ NOT NULL
+		)
+	`)
+	require.NoError(t, err, "Failed to create idempotency_keys table")
+
+	store := NewIdempotencyStore(db)
+	ctx := context.Background()
+	key := "test:" + uuid.New().String()
+
+	record, err := store.Begin(ctx, key, "fingerprint", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, record, "first request claims the key")
+
+	record, err = store.Begin(ctx, key, "fingerprint", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Nil(t, record.Response, "repeat while in flight sees no response")
+
+	response := ports.IdempotentResponse{
+		StatusCode: 201,
+		Header:     map[string][]string{"Content-Type": {"application/json"}},
+		Body:       []byte(`{"id":"1"}`),
+	}
+	require.NoError(t, store.Complete(ctx, key, response, time.Hour))
+
+	record, err = store.Begin(ctx, key, "fingerprint", time.Minute)
+	require.NoError(t, err)
+	require.NotNil(t, record)
+	assert.Equal(t, "fingerprint", record.Fingerprint)
+	assert.Equal(t, &response, record.Response)
+
+	released := "test:" + uuid.New().String()
+	_, err = store.Begin(ctx, released, "fingerprint", time.Minute)
+	require.NoError(t, err)
+	require.NoError(t, store.Release(ctx, released))
+	record, err = store.Begin(ctx, released, "fingerprint", time.Minute)
+	require.NoError(t, err)
+	assert.Nil(t, record, "released keys can be claimed again")
+}
+
+func TestIdempotencyStore_SweepsExpiredKeys(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS idempotency_keys (
+			key TEXT PRIMARY KEY,
+			fingerprint TEXT NOT NULL,
+			status_code INTEGER,
+			header JSONB,
+			body BYTEA,
+			expires_at TIMESTAMPTZ NOT NULL
+		)
+	`)
+	require.NoError(t, err, "Failed to create idempotency_keys table")
+
+	expired, live := "test:"+uuid.New().String(), "test:"+uuid.New().String()
+	_, err = db.Exec(`
+		INSERT INTO idempotency_keys (key, fingerprint, expires_at)
+		VALUES ($1, 'fingerprint', now() - interval '1 minute'), ($2, 'fingerprint', now() + interval '1 hour')`,
+		expired, live,
+	)
+	require.NoError(t, err)
+
+	_, err = NewIdempotencyStore(db).Begin(context.Background(), "test:"+uuid.New().String(), "fingerprint", time.Minute)
+	require.NoError(t, err)
+
+	var remaining []string
+	rows, err := db.Query(`SELECT key FROM idempotency_keys WHERE key IN ($1, $2)`, expired, live)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		require.NoError(t, rows.Scan(&key))
+		remaining = append(remaining, key)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{live}, remaining)
+}
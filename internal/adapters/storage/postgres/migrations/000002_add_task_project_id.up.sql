@@ -0,0 +1,3 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id VARCHAR(255);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks(project_id);
@@ -0,0 +1,7 @@
+CREATE INDEX IF NOT EXISTS idx_tasks_project_id ON tasks(project_id);
+
+DROP INDEX IF EXISTS idx_tasks_tenant_id_project_id;
+DROP INDEX IF EXISTS idx_tasks_tenant_id_created_at;
+DROP INDEX IF EXISTS idx_tasks_tenant_id_id;
+
+ALTER TABLE tasks DROP COLUMN IF EXISTS tenant_id;
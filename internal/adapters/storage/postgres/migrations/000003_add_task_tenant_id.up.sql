@@ -0,0 +1,8 @@
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tenant_id VARCHAR(64) NOT NULL DEFAULT 'default';
+
+-- Every query is scoped by tenant, so lead the indexes with tenant_id
+CREATE UNIQUE INDEX IF NOT EXISTS idx_tasks_tenant_id_id ON tasks(tenant_id, id);
+CREATE INDEX IF NOT EXISTS idx_tasks_tenant_id_created_at ON tasks(tenant_id, created_at DESC);
+CREATE INDEX IF NOT EXISTS idx_tasks_tenant_id_project_id ON tasks(tenant_id, project_id);
+
+DROP INDEX IF EXISTS idx_tasks_project_id;
@@ -0,0 +1,2 @@
+-- version is bumped on every update so writers can compare-and-swap
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1;
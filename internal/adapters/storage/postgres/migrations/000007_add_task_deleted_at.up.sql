@@ -0,0 +1,5 @@
+-- Deleted tasks stay in the table, marked with deleted_at, until purged
+ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+-- The trash listing and the scheduled purge only look at deleted tasks
+CREATE INDEX IF NOT EXISTS idx_tasks_deleted_at ON tasks(deleted_at) WHERE deleted_at IS NOT NULL;
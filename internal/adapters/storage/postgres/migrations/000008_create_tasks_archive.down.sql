@@ -0,0 +1,9 @@
+DROP INDEX IF EXISTS idx_tasks_completed_updated_at;
+
+-- Move archived tasks back before the table goes
+INSERT INTO tasks (id, tenant_id, project_id, title, description, status, created_at, updated_at, due_date, version, deleted_at)
+SELECT id, tenant_id, project_id, title, description, status, created_at, updated_at, due_date, version, deleted_at
+FROM tasks_archive
+ON CONFLICT (id) DO NOTHING;
+
+DROP TABLE IF EXISTS tasks_archive;
@@ -0,0 +1,9 @@
+-- Archived tasks are moved out of tasks so they no longer slow down listing
+CREATE TABLE IF NOT EXISTS tasks_archive (LIKE tasks INCLUDING DEFAULTS);
+ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP;
+ALTER TABLE tasks_archive ADD PRIMARY KEY (id);
+
+CREATE INDEX IF NOT EXISTS idx_tasks_archive_tenant_id_created_at ON tasks_archive(tenant_id, created_at DESC);
+
+-- Archival looks for tasks completed before a cutoff
+CREATE INDEX IF NOT EXISTS idx_tasks_completed_updated_at ON tasks(updated_at) WHERE status = 'completed';
@@ -1,12 +1,15 @@
 package migrations
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"fmt"
+	"os"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/postgres"
+	"github.com/golang-migrate/migrate/v4/source"
 	_ "github.com/golang-migrate/migrate/v4/source/file"
 )
 
@@ -34,3 +37,44 @@ func MigrateDB(db *sql.DB, migrationsPath string) error {
 
 	return nil
 }
+
+// Version returns the migration the database is at, and whether that
+// migration failed part way through
+func Version(ctx context.Context, db *sql.DB) (uint, bool, error) {
+	var (
+		version int64
+		dirty   bool
+	)
+	err := db.QueryRowContext(ctx, `SELECT version, dirty FROM schema_migrations LIMIT 1`).Scan(&version, &dirty)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, false, nil
+	}
+	if err != nil {
+		return 0, false, fmt.Errorf("could not read migration version: %w", err)
+	}
+	return uint(version), dirty, nil
+}
+
+// LatestVersion returns the highest migration version in migrationsPath
+func LatestVersion(migrationsPath string) (uint, error) {
+	migrations, err := source.Open(fmt.Sprintf("file://%s", migrationsPath))
+	if err != nil {
+		return 0, fmt.Errorf("could not open migrations: %w", err)
+	}
+	defer migrations.Close()
+
+	version, err := migrations.First()
+	if err != nil {
+		return 0, fmt.Errorf("could not read migrations: %w", err)
+	}
+	for {
+		next, err := migrations.Next(version)
+		if errors.Is(err, os.ErrNotExist) {
+			return version, nil
+		}
+		if err != nil {
+			return 0, fmt.Errorf("could not read migrations: %w", err)
+		}
+		version = next
+	}
+}
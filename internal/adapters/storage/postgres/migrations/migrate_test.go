@@ -0,0 +1,19 @@
+package migrations
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestLatestVersion(t *testing.T) {
+	ups, err := filepath.Glob("*.up.sql")
+	require.NoError(t, err)
+
+	version, err := LatestVersion(".")
+
+	require.NoError(t, err)
+	assert.Equal(t, uint(len(ups)), version)
+}
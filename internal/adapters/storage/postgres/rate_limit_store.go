@@ -0,0 +1,113 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+
+	"task-tracking-service/internal/core/ports"
+)
+
+const (
+	// sweepInterval is how often each replica deletes stale rows
+	sweepInterval = time.Minute
+	// idleBucketTTL is how long a bucket is kept after its last use. Buckets
+	// refill well within it, after which they are indistinguishable from
+	// new ones.
+	idleBucketTTL = time.Hour
+)
+
+// RateLimitStore keeps token buckets in PostgreSQL so that every replica
+// enforces the same limits. Elapsed time is measured with the database
+// clock to avoid skew between replicas.
+type RateLimitStore struct {
+	db *sql.DB
+
+	mutex     sync.Mutex
+	lastSweep time.Time
+}
+
+func NewRateLimitStore(db *sql.DB) *RateLimitStore {
+	return &RateLimitStore{
+		db: db,
+	}
+}
+
+// Take refills and takes a token from the bucket inside a transaction,
+// holding a row lock so that concurrent requests cannot overspend it.
+func (s *RateLimitStore) Take(ctx context.Context, key string, limit ports.RateLimit) (ports.RateLimitResult, error) {
+	if err := s.sweep(ctx); err != nil {
+		return ports.RateLimitResult{}, err
+	}
+
+	tx, err := s.db.BeginTx(ctx, nil)
+	if err != nil {
+		return ports.RateLimitResult{}, fmt.Errorf("failed to begin rate limit transaction: %w", err)
+	}
+	defer tx.Rollback()
+	q := instrument(tx)
+
+	_, err = q.ExecContext(ctx, `
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (key) DO NOTHING`,
+		key, limit.Burst,
+	)
+	if err != nil {
+		return ports.RateLimitResult{}, fmt.Errorf("failed to create rate limit bucket: %w", err)
+	}
+
+	var tokens, elapsedSeconds float64
+	err = q.QueryRowContext(ctx, `
+		SELECT tokens, EXTRACT(EPOCH FROM (now() - updated_at))
+		FROM rate_limit_buckets
+		WHERE key = $1
+		FOR UPDATE`,
+		key,
+	).Scan(&tokens, &elapsedSeconds)
+	if err != nil {
+		return ports.RateLimitResult{}, fmt.Errorf("failed to read rate limit bucket: %w", err)
+	}
+
+	tokens, result := limit.Take(tokens, time.Duration(elapsedSeconds*float64(time.Second)))
+
+	_, err = q.ExecContext(ctx, `
+		UPDATE rate_limit_buckets
+		SET tokens = $1, updated_at = now()
+		WHERE key = $2`,
+		tokens, key,
+	)
+	if err != nil {
+		return ports.RateLimitResult{}, fmt.Errorf("failed to update rate limit bucket: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return ports.RateLimitResult{}, fmt.Errorf("failed to commit rate limit transaction: %w", err)
+	}
+
+	return result, nil
+}
+
+// sweep deletes buckets left idle for idleBucketTTL, at most once per
+// sweepInterval
+func (s *RateLimitStore) sweep(ctx context.Context) error {
+	s.mutex.Lock()
+	if time.Since(s.lastSweep) < sweepInterval {
+		s.mutex.Unlock()
+		return nil
+	}
+	s.lastSweep = time.Now()
+	s.mutex.Unlock()
+
+	_, err := conn(ctx, s.db).ExecContext(ctx, `
+		DELETE FROM rate_limit_buckets
+		WHERE updated_at < now() - make_interval(secs => $1)`,
+		idleBucketTTL.Seconds(),
+	)
+	if err != nil {
+		return fmt.Errorf("failed to sweep rate limit buckets: %w", err)
+	}
+	return nil
+}
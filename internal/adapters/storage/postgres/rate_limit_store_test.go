@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"task-tracking-service/internal/core/ports"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRateLimitStore_Take(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+			key TEXT PRIMARY KEY,
+			tokens DOUBLE PRECISION NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		)
+	`)
+	require.NoError(t, err, "Failed to create rate_limit_buckets table")
+
+	store := NewRateLimitStore(db)
+	ctx := context.Background()
+	key := "test:" + uuid.New().String()
+	limit := ports.RateLimit{Rate: 0.001, Burst: 2}
+
+	for i := 0; i < 2; i++ {
+		result, err := store.Take(ctx, key, limit)
+		require.NoError(t, err)
+		assert.True(t, result.Allowed)
+	}
+
+	result, err := store.Take(ctx, key, limit)
+	require.NoError(t, err)
+	assert.False(t, result.Allowed)
+	assert.Positive(t, result.RetryAfter)
+}
+
+func TestRateLimitStore_SweepsIdleBuckets(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	_, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS rate_limit_buckets (
+			key TEXT PRIMARY KEY,
+			tokens DOUBLE PRECISION NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		)
+	`)
+	require.NoError(t, err, "Failed to create rate_limit_buckets table")
+
+	idle, recent := "test:"+uuid.New().String(), "test:"+uuid.New().String()
+	_, err = db.Exec(`
+		INSERT INTO rate_limit_buckets (key, tokens, updated_at)
+		VALUES ($1, 0, now() - interval '2 hours'), ($2, 0, now() - interval '1 minute')`,
+		idle, recent,
+	)
+	require.NoError(t, err)
+
+	_, err = NewRateLimitStore(db).Take(context.Background(), "test:"+uuid.New().String(), ports.RateLimit{Rate: 1, Burst: 1})
+	require.NoError(t, err)
+
+	var remaining []string
+	rows, err := db.Query(`SELECT key FROM rate_limit_buckets WHERE key IN ($1, $2)`, idle, recent)
+	require.NoError(t, err)
+	defer rows.Close()
+	for rows.Next() {
+		var key string
+		require.NoError(t, rows.Scan(&key))
+		remaining = append(remaining, key)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{recent}, remaining)
+}
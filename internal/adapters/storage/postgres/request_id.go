@@ -0,0 +1,37 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+
+	"task-tracking-service/internal/core/domain"
+)
+
+// requestIDConn prefixes each query with a comment naming the API request
+// it serves, so that statements seen in pg_stat_activity and the slow
+// query log can be traced back to the request's logs
+type requestIDConn struct {
+	conn dbtx
+}
+
+func (c requestIDConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return c.conn.ExecContext(ctx, withRequestID(ctx, query), args...)
+}
+
+func (c requestIDConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return c.conn.QueryContext(ctx, withRequestID(ctx, query), args...)
+}
+
+func (c requestIDConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return c.conn.QueryRowContext(ctx, withRequestID(ctx, query), args...)
+}
+
+// withRequestID prefixes query with the request ID carried by ctx. IDs
+// that could end the comment early are left out.
+func withRequestID(ctx context.Context, query string) string {
+	id := domain.RequestIDFromContext(ctx)
+	if !domain.IsValidRequestID(id) {
+		return query
+	}
+	return "/* request_id=" + id + " */ " + query
+}
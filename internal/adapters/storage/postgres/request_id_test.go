@@ -0,0 +1,35 @@
+package postgres
+
+import (
+	"context"
+	"testing"
+
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRequestID(t *testing.T) {
+	const query = "SELECT 1"
+
+	tests := []struct {
+		name      string
+		requestID string
+		expected  string
+	}{
+		{name: "no request", expected: query},
+		{name: "request ID is prefixed", requestID: "req-42", expected: "/* request_id=req-42 */ SELECT 1"},
+		{name: "IDs that would end the comment are left out", requestID: "x */ DROP TABLE tasks; --", expected: query},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tt.requestID != "" {
+				ctx = domain.ContextWithRequestID(ctx, tt.requestID)
+			}
+
+			assert.Equal(t, tt.expected, withRequestID(ctx, query))
+		})
+	}
+}
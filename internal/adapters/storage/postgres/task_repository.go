@@ -4,30 +4,99 @@ import (
 	"context"
 	"database/sql"
 	"fmt"
+	"log/slog"
+	"slices"
 	"time"
 
 	"task-tracking-service/internal/core/domain"
 	customerrors "task-tracking-service/pkg/errors"
 
 	"github.com/google/uuid"
+	"github.com/lib/pq"
 )
 
+// taskColumns is the column list shared by every query that returns tasks,
+// in the order expected by scanTask. archivedTaskColumns is the same list
+// for tasks_archive.
+const (
+	taskColumns         = `id, tenant_id, COALESCE(project_id, ''), title, description, status, created_at, updated_at, due_date, version, deleted_at, NULL::timestamp`
+	archivedTaskColumns = `id, tenant_id, COALESCE(project_id, ''), title, description, status, created_at, updated_at, due_date, version, deleted_at, archived_at`
+
+	// rankedTaskColumns name the columns of a listing wrapped in another
+	// query
+	rankedTaskColumns = `id, tenant_id, project_id, title, description, status, created_at, updated_at, due_date, version, deleted_at, archived_at`
+
+	// movedTaskColumns are copied between tasks and tasks_archive
+	movedTaskColumns = `id, tenant_id, project_id, title, description, status, created_at, updated_at, due_date, version`
+)
+
+// rowScanner is implemented by both *sql.Row and *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+func scanTask(row rowScanner) (*domain.Task, error) {
+	task := &domain.Task{}
+	var deletedAt, archivedAt sql.NullTime
+	err := row.Scan(
+		&task.ID,
+		&task.TenantID,
+		&task.ProjectID,
+		&task.Title,
+		&task.Description,
+		&task.Status,
+		&task.CreatedAt,
+		&task.UpdatedAt,
+		&task.DueDate,
+		&task.Version,
+		&deletedAt,
+		&archivedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	if deletedAt.Valid {
+		task.DeletedAt = &deletedAt.Time
+	}
+	if archivedAt.Valid {
+		task.ArchivedAt = &archivedAt.Time
+	}
+	return task, nil
+}
+
+// isValidID reports whether id can be a task ID. Anything else cannot
+// exist, and would otherwise surface as a UUID syntax error from the database.
+func isValidID(id string) bool {
+	_, err := uuid.Parse(id)
+	return err == nil
+}
+
+// TaskRepository stores tasks in PostgreSQL. Every query is scoped to the
+// tenant carried by the request context.
 type TaskRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	logger *slog.Logger
 }
 
-func NewTaskRepository(db *sql.DB) *TaskRepository {
+func NewTaskRepository(db *sql.DB, logger *slog.Logger) *TaskRepository {
 	return &TaskRepository{
-		db: db,
+		db:     db,
+		logger: logger,
 	}
 }
 
+// WithinTx runs fn in a database transaction. Repository calls made with
+// the context passed to fn use that transaction.
+func (r *TaskRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return withinTx(ctx, r.db, r.logger, fn)
+}
+
 // Create stores a new task in the database
 func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
 	query := `
-		INSERT INTO tasks (id, title, description, status, created_at, updated_at, due_date)
-		VALUES ($1, $2, $3, $4, $5, $6, $7)
-		RETURNING id, title, description, status, created_at, updated_at, due_date`
+		INSERT INTO tasks (id, tenant_id, project_id, title, description, status, created_at, updated_at, due_date)
+		VALUES ($1, $2, NULLIF($3, ''), $4, $5, $6, $7, $8, $9)
+		RETURNING ` + taskColumns
 
 	id := uuid.New()
 	now := time.Now()
@@ -35,10 +104,12 @@ func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
 	task.CreatedAt = now
 	task.UpdatedAt = now
 
-	row := r.db.QueryRowContext(
+	row := conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
 		task.ID,
+		domain.TenantFromContext(ctx),
+		task.ProjectID,
 		task.Title,
 		task.Description,
 		task.Status,
@@ -47,41 +118,32 @@ func (r *TaskRepository) Create(ctx context.Context, task *domain.Task) error {
 		task.DueDate,
 	)
 
-	err := row.Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-		&task.DueDate,
-	)
-
+	created, err := scanTask(row)
 	if err != nil {
 		return fmt.Errorf("failed to create task: %w", err)
 	}
+	*task = *created
 
 	return nil
 }
 
 // GetByID retrieves a task by ID from the database
 func (r *TaskRepository) GetByID(ctx context.Context, id string) (*domain.Task, error) {
+	if !isValidID(id) {
+		return nil, customerrors.ErrTaskNotFound
+	}
+
 	query := `
-		SELECT id, title, description, status, created_at, updated_at, due_date
+		SELECT ` + taskColumns + `
 		FROM tasks
-		WHERE id = $1`
-
-	task := &domain.Task{}
-	err := r.db.QueryRowContext(ctx, query, id).Scan(
-		&task.ID,
-		&task.Title,
-		&task.Description,
-		&task.Status,
-		&task.CreatedAt,
-		&task.UpdatedAt,
-		&task.DueDate,
-	)
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL`
+	// Inside a transaction the caller is about to act on what it reads, so
+	// hold the row until the transaction ends
+	if inTx(ctx) {
+		query += ` FOR UPDATE`
+	}
 
+	task, err := scanTask(conn(ctx, r.db).QueryRowContext(ctx, query, id, domain.TenantFromContext(ctx)))
 	if err != nil {
 		if err == sql.ErrNoRows {
 			return nil, customerrors.ErrTaskNotFound
@@ -92,14 +154,91 @@ func (r *TaskRepository) GetByID(ctx context.Context, id string) (*domain.Task,
 	return task, nil
 }
 
-// List retrieves all tasks from the database
-func (r *TaskRepository) List(ctx context.Context) ([]*domain.Task, error) {
+// List retrieves tasks not in the trash from the database, and archived
+// tasks if the filter asks for them, a page at a time if it sets a limit
+func (r *TaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	conditions, args, ok := taskConditions(filter)
+	if !ok {
+		return []*domain.Task{}, nil
+	}
+
 	query := `
-		SELECT id, title, description, status, created_at, updated_at, due_date
+		SELECT ` + taskColumns + `
 		FROM tasks
-		ORDER BY created_at DESC`
+		WHERE tenant_id = $1 AND deleted_at IS NULL` + conditions
+	if filter.IncludeArchived {
+		query += `
+		UNION ALL
+		SELECT ` + archivedTaskColumns + `
+		FROM tasks_archive
+		WHERE tenant_id = $1` + conditions
+	}
+	if filter.LimitPerProject > 0 {
+		query = `
+		SELECT ` + rankedTaskColumns + `
+		FROM (
+			SELECT listed.*, ROW_NUMBER() OVER (PARTITION BY project_id ORDER BY created_at DESC, id DESC) AS project_rank
+			FROM (` + query + `
+			) AS listed(` + rankedTaskColumns + `)
+		) AS ranked
+		WHERE project_rank <= ` + fmt.Sprint(filter.LimitPerProject)
+	}
+	query += `
+		ORDER BY created_at DESC, id DESC`
+	if filter.Limit > 0 {
+		query += fmt.Sprintf(`
+		LIMIT %d`, filter.Limit)
+	}
+
+	return r.list(ctx, query, args...)
+}
+
+// taskConditions turns filter into conditions on the columns shared by
+// tasks and tasks_archive, with arguments numbered from $2. ok is false when
+// no task can match.
+func taskConditions(filter domain.TaskFilter) (conditions string, args []any, ok bool) {
+	arg := func(value any) string {
+		args = append(args, value)
+		return fmt.Sprintf("$%d", len(args)+1)
+	}
+
+	if len(filter.IDs) > 0 {
+		ids := slices.DeleteFunc(slices.Clone(filter.IDs), func(id string) bool { return !isValidID(id) })
+		if len(ids) == 0 {
+			return "", nil, false
+		}
+		conditions += ` AND id = ANY(` + arg(pq.Array(ids)) + `::uuid[])`
+	}
+	if len(filter.ProjectIDs) > 0 {
+		conditions += ` AND COALESCE(project_id, '') = ANY(` + arg(pq.Array(filter.ProjectIDs)) + `)`
+	}
+	if len(filter.Statuses) > 0 {
+		statuses := make([]string, len(filter.Statuses))
+		for i, status := range filter.Statuses {
+			statuses[i] = string(status)
+		}
+		conditions += ` AND status = ANY(` + arg(pq.Array(statuses)) + `)`
+	}
+	if filter.DueBefore != nil {
+		conditions += ` AND due_date < ` + arg(*filter.DueBefore)
+	}
+	if filter.DueAfter != nil {
+		conditions += ` AND due_date > ` + arg(*filter.DueAfter)
+	}
+	if filter.After != nil {
+		if !isValidID(filter.After.ID) {
+			return "", nil, false
+		}
+		conditions += ` AND (created_at, id) < (` + arg(filter.After.CreatedAt) + `, ` + arg(filter.After.ID) + `::uuid)`
+	}
+	return conditions, args, true
+}
 
-	rows, err := r.db.QueryContext(ctx, query)
+// list runs a query for the tasks of the tenant carried by ctx. The tenant
+// is passed as $1, followed by args.
+func (r *TaskRepository) list(ctx context.Context, query string, args ...any) ([]*domain.Task, error) {
+	args = append([]any{domain.TenantFromContext(ctx)}, args...)
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, args...)
 	if err != nil {
 		return nil, fmt.Errorf("failed to list tasks: %w", err)
 	}
@@ -107,16 +246,7 @@ func (r *TaskRepository) List(ctx context.Context) ([]*domain.Task, error) {
 
 	var tasks []*domain.Task
 	for rows.Next() {
-		task := &domain.Task{}
-		err := rows.Scan(
-			&task.ID,
-			&task.Title,
-			&task.Description,
-			&task.Status,
-			&task.CreatedAt,
-			&task.UpdatedAt,
-			&task.DueDate,
-		)
+		task, err := scanTask(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan task: %w", err)
 		}
@@ -130,46 +260,143 @@ func (r *TaskRepository) List(ctx context.Context) ([]*domain.Task, error) {
 	return tasks, nil
 }
 
-// Update modifies an existing task in the database
+// Update modifies an existing task in the database. The row is only written
+// while its version still equals task.Version, so concurrent writers cannot
+// silently overwrite each other.
 func (r *TaskRepository) Update(ctx context.Context, task *domain.Task) error {
+	if !isValidID(task.ID) {
+		return customerrors.ErrTaskNotFound
+	}
+
 	query := `
 		UPDATE tasks
-		SET title = $1, description = $2, status = $3, updated_at = $4, due_date = $5
-		WHERE id = $6`
+		SET project_id = NULLIF($1, ''), title = $2, description = $3, status = $4, updated_at = $5, due_date = $6,
+			version = version + 1
+		WHERE id = $7 AND tenant_id = $8 AND version = $9 AND deleted_at IS NULL
+		RETURNING version`
 
-	result, err := r.db.ExecContext(
+	tenantID := domain.TenantFromContext(ctx)
+	var version int64
+	err := conn(ctx, r.db).QueryRowContext(
 		ctx,
 		query,
+		task.ProjectID,
 		task.Title,
 		task.Description,
 		task.Status,
 		time.Now(),
 		task.DueDate,
 		task.ID,
-	)
+		tenantID,
+		task.Version,
+	).Scan(&version)
+	if err == sql.ErrNoRows {
+		return r.versionMismatch(ctx, task.ID)
+	}
 	if err != nil {
 		return fmt.Errorf("failed to update task: %w", err)
 	}
 
+	task.TenantID = tenantID
+	task.Version = version
+	return nil
+}
+
+// Delete moves a task to the trash if it is still at the given version
+func (r *TaskRepository) Delete(ctx context.Context, id string, version int64) error {
+	if !isValidID(id) {
+		return customerrors.ErrTaskNotFound
+	}
+
+	query := `
+		UPDATE tasks
+		SET deleted_at = $1, version = version + 1
+		WHERE id = $2 AND tenant_id = $3 AND version = $4 AND deleted_at IS NULL`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, time.Now(), id, domain.TenantFromContext(ctx), version)
+	if err != nil {
+		return fmt.Errorf("failed to delete task: %w", err)
+	}
+
 	rowsAffected, err := result.RowsAffected()
 	if err != nil {
 		return fmt.Errorf("failed to get rows affected: %w", err)
 	}
 
 	if rowsAffected == 0 {
-		return customerrors.ErrTaskNotFound
+		return r.versionMismatch(ctx, id)
 	}
 
 	return nil
 }
 
-// Delete removes a task from the database
-func (r *TaskRepository) Delete(ctx context.Context, id string) error {
-	query := `DELETE FROM tasks WHERE id = $1`
+// GetDeletedByID retrieves a task in the trash
+func (r *TaskRepository) GetDeletedByID(ctx context.Context, id string) (*domain.Task, error) {
+	if !isValidID(id) {
+		return nil, customerrors.ErrTaskNotFound
+	}
+
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL`
 
-	result, err := r.db.ExecContext(ctx, query, id)
+	task, err := scanTask(conn(ctx, r.db).QueryRowContext(ctx, query, id, domain.TenantFromContext(ctx)))
 	if err != nil {
-		return fmt.Errorf("failed to delete task: %w", err)
+		if err == sql.ErrNoRows {
+			return nil, customerrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get deleted task: %w", err)
+	}
+
+	return task, nil
+}
+
+// ListDeleted retrieves the tasks in the trash, most recently deleted first
+func (r *TaskRepository) ListDeleted(ctx context.Context) ([]*domain.Task, error) {
+	query := `
+		SELECT ` + taskColumns + `
+		FROM tasks
+		WHERE tenant_id = $1 AND deleted_at IS NOT NULL
+		ORDER BY deleted_at DESC, id`
+
+	return r.list(ctx, query)
+}
+
+// Restore takes a task out of the trash and bumps its version
+func (r *TaskRepository) Restore(ctx context.Context, id string) (*domain.Task, error) {
+	if !isValidID(id) {
+		return nil, customerrors.ErrTaskNotFound
+	}
+
+	query := `
+		UPDATE tasks
+		SET deleted_at = NULL, version = version + 1
+		WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL
+		RETURNING ` + taskColumns
+
+	task, err := scanTask(conn(ctx, r.db).QueryRowContext(ctx, query, id, domain.TenantFromContext(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to restore task: %w", err)
+	}
+
+	return task, nil
+}
+
+// Purge permanently removes a task from the trash
+func (r *TaskRepository) Purge(ctx context.Context, id string) error {
+	if !isValidID(id) {
+		return customerrors.ErrTaskNotFound
+	}
+
+	query := `DELETE FROM tasks WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NOT NULL`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, id, domain.TenantFromContext(ctx))
+	if err != nil {
+		return fmt.Errorf("failed to purge task: %w", err)
 	}
 
 	rowsAffected, err := result.RowsAffected()
@@ -183,3 +410,142 @@ func (r *TaskRepository) Delete(ctx context.Context, id string) error {
 
 	return nil
 }
+
+// PurgeDeletedBefore permanently removes tasks of every tenant that were
+// deleted before cutoff
+func (r *TaskRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `DELETE FROM tasks WHERE deleted_at < $1`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, cutoff)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge deleted tasks: %w", err)
+	}
+
+	purged, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return purged, nil
+}
+
+// GetArchivedByID retrieves an archived task
+func (r *TaskRepository) GetArchivedByID(ctx context.Context, id string) (*domain.Task, error) {
+	if !isValidID(id) {
+		return nil, customerrors.ErrTaskNotFound
+	}
+
+	query := `
+		SELECT ` + archivedTaskColumns + `
+		FROM tasks_archive
+		WHERE id = $1 AND tenant_id = $2`
+
+	task, err := scanTask(conn(ctx, r.db).QueryRowContext(ctx, query, id, domain.TenantFromContext(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to get archived task: %w", err)
+	}
+
+	return task, nil
+}
+
+// ArchiveCompletedBefore moves tasks of every tenant that were completed
+// before cutoff into tasks_archive
+func (r *TaskRepository) ArchiveCompletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	query := `
+		WITH archived AS (
+			DELETE FROM tasks
+			WHERE status = $1 AND updated_at < $2 AND deleted_at IS NULL
+			RETURNING ` + movedTaskColumns + `
+		)
+		INSERT INTO tasks_archive (` + movedTaskColumns + `, archived_at)
+		SELECT ` + movedTaskColumns + `, $3 FROM archived`
+
+	result, err := conn(ctx, r.db).ExecContext(ctx, query, domain.StatusCompleted, cutoff, time.Now())
+	if err != nil {
+		return 0, fmt.Errorf("failed to archive tasks: %w", err)
+	}
+
+	archived, err := result.RowsAffected()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+
+	return archived, nil
+}
+
+// Unarchive moves a task from tasks_archive back into tasks and bumps its
+// version
+func (r *TaskRepository) Unarchive(ctx context.Context, id string) (*domain.Task, error) {
+	if !isValidID(id) {
+		return nil, customerrors.ErrTaskNotFound
+	}
+
+	query := `
+		WITH unarchived AS (
+			DELETE FROM tasks_archive
+			WHERE id = $1 AND tenant_id = $2
+			RETURNING ` + movedTaskColumns + `
+		)
+		INSERT INTO tasks (` + movedTaskColumns + `)
+		SELECT id, tenant_id, project_id, title, description, status, created_at, updated_at, due_date, version + 1
+		FROM unarchived
+		RETURNING ` + taskColumns
+
+	task, err := scanTask(conn(ctx, r.db).QueryRowContext(ctx, query, id, domain.TenantFromContext(ctx)))
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, customerrors.ErrTaskNotFound
+		}
+		return nil, fmt.Errorf("failed to unarchive task: %w", err)
+	}
+
+	return task, nil
+}
+
+// TaskStats counts the live tasks of every tenant by status
+func (r *TaskRepository) TaskStats(ctx context.Context, now time.Time) (domain.TaskStats, error) {
+	query := `
+		SELECT status, COUNT(*), COUNT(*) FILTER (WHERE status <> $1 AND due_date < $2)
+		FROM tasks
+		WHERE deleted_at IS NULL
+		GROUP BY status`
+
+	rows, err := conn(ctx, r.db).QueryContext(ctx, query, domain.StatusCompleted, now)
+	if err != nil {
+		return domain.TaskStats{}, fmt.Errorf("failed to count tasks: %w", err)
+	}
+	defer rows.Close()
+
+	stats := domain.TaskStats{ByStatus: make(map[domain.TaskStatus]int64)}
+	for rows.Next() {
+		var status domain.TaskStatus
+		var count, overdue int64
+		if err := rows.Scan(&status, &count, &overdue); err != nil {
+			return domain.TaskStats{}, fmt.Errorf("failed to scan task counts: %w", err)
+		}
+		stats.ByStatus[status] = count
+		stats.Overdue += overdue
+	}
+	if err := rows.Err(); err != nil {
+		return domain.TaskStats{}, fmt.Errorf("error iterating task counts: %w", err)
+	}
+
+	return stats, nil
+}
+
+// versionMismatch explains why a conditional write matched no rows: either
+// the task is gone, or it exists at a different version.
+func (r *TaskRepository) versionMismatch(ctx context.Context, id string) error {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM tasks WHERE id = $1 AND tenant_id = $2 AND deleted_at IS NULL)`
+	if err := conn(ctx, r.db).QueryRowContext(ctx, query, id, domain.TenantFromContext(ctx)).Scan(&exists); err != nil {
+		return fmt.Errorf("failed to check task version: %w", err)
+	}
+	if !exists {
+		return customerrors.ErrTaskNotFound
+	}
+	return customerrors.ErrTaskVersionConflict
+}
@@ -3,7 +3,9 @@ package postgres
 import (
 	"context"
 	"database/sql"
+	"errors"
 	"fmt"
+	"log/slog"
 	"net/url"
 	"os"
 	"strings"
@@ -162,16 +164,29 @@ func setupTestTables(t *testing.T, db *sql.DB) {
 	`)
 	require.NoError(t, err, "Failed to create tasks table")
 
-	// Clear the tasks table for a fresh test
-	_, err = db.Exec("TRUNCATE TABLE tasks")
-	require.NoError(t, err, "Failed to truncate tasks table")
+	// Bring tables created by earlier runs up to the current schema
+	for _, statement := range []string{
+		`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS project_id TEXT`,
+		`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS tenant_id TEXT NOT NULL DEFAULT 'default'`,
+		`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS version BIGINT NOT NULL DEFAULT 1`,
+		`ALTER TABLE tasks ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP`,
+		`CREATE TABLE IF NOT EXISTS tasks_archive (LIKE tasks INCLUDING DEFAULTS)`,
+		`ALTER TABLE tasks_archive ADD COLUMN IF NOT EXISTS archived_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP`,
+	} {
+		_, err = db.Exec(statement)
+		require.NoError(t, err, "Failed to migrate tasks table")
+	}
+
+	// Clear the tasks tables for a fresh test
+	_, err = db.Exec("TRUNCATE TABLE tasks, tasks_archive")
+	require.NoError(t, err, "Failed to truncate tasks tables")
 }
 
 func TestTaskRepository_Create(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, slog.Default())
 	ctx := context.Background()
 
 	task := &domain.Task{
@@ -206,7 +221,7 @@ func TestTaskRepository_Get(t *testing.T) {
 	db := setupTestDB(t)
 	defer db.Close()
 
-	repo := NewTaskRepository(db)
+	repo := NewTaskRepository(db, slog.Default())
 	ctx := context.Background()
 
 	// Create a task first
@@ -230,4 +245,304 @@ func TestTaskRepository_Get(t *testing.T) {
 	assert.ErrorIs(t, err, customerrors.ErrTaskNotFound)
 }
 
+func TestTaskRepository_ListPages(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db, slog.Default())
+	ctx := context.Background()
+	var created []string
+	for i := 0; i < 5; i++ {
+		task := &domain.Task{Title: fmt.Sprintf("Task %d", i), Status: domain.StatusPending}
+		require.NoError(t, repo.Create(ctx, task))
+		created = append([]string{task.ID}, created...)
+	}
+
+	var listed []string
+	filter := domain.TaskFilter{Limit: 2}
+	for {
+		page, err := repo.List(ctx, filter)
+		require.NoError(t, err)
+		require.LessOrEqual(t, len(page), 2)
+		if len(page) == 0 {
+			break
+		}
+		for _, task := range page {
+			listed = append(listed, task.ID)
+		}
+		cursor := page[len(page)-1].Cursor()
+		filter.After = &cursor
+	}
+
+	assert.Equal(t, created, listed)
+}
+
+func TestTaskRepository_ListFilters(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db, slog.Default())
+	ctx := context.Background()
+	start := time.Now().UTC().Truncate(time.Second)
+	dueAt := start.Add(24 * time.Hour)
+	var ids []string
+	for i, projectID := range []string{"proj-1", "proj-2", "", "proj-1"} {
+		task := &domain.Task{Title: fmt.Sprintf("Task %d", i), ProjectID: projectID, Status: domain.StatusPending}
+		// Odd tasks are completed; even ones are due before dueAt
+		task.DueDate = dueAt.Add(-time.Hour)
+		if i%2 == 1 {
+			task.Status = domain.StatusCompleted
+			task.DueDate = dueAt
+		}
+		require.NoError(t, repo.Create(ctx, task))
+		ids = append(ids, task.ID)
+	}
+
+	tests := []struct {
+		name   string
+		filter domain.TaskFilter
+		want   []string
+	}{
+		{name: "by project", filter: domain.TaskFilter{ProjectIDs: []string{"proj-1"}}, want: []string{"Task 3", "Task 0"}},
+		{name: "by several projects", filter: domain.TaskFilter{ProjectIDs: []string{"proj-2", ""}}, want: []string{"Task 2", "Task 1"}},
+		{name: "limit applies after filtering", filter: domain.TaskFilter{ProjectIDs: []string{"proj-1", "proj-2"}, Limit: 2}, want: []string{"Task 3", "Task 1"}},
+		{name: "by status", filter: domain.TaskFilter{Statuses: []domain.TaskStatus{domain.StatusCompleted}}, want: []string{"Task 3", "Task 1"}},
+		{name: "by due date", filter: domain.TaskFilter{DueBefore: &dueAt, DueAfter: &start}, want: []string{"Task 2", "Task 0"}},
+		{name: "by ID", filter: domain.TaskFilter{IDs: []string{ids[0], ids[2], "missing"}}, want: []string{"Task 2", "Task 0"}},
+		{name: "limit per project", filter: domain.TaskFilter{LimitPerProject: 1}, want: []string{"Task 3", "Task 2", "Task 1"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tasks, err := repo.List(ctx, tt.filter)
+			require.NoError(t, err)
+
+			var titles []string
+			for _, task := range tasks {
+				titles = append(titles, task.Title)
+			}
+			assert.Equal(t, tt.want, titles)
+		})
+	}
+}
+
+func TestTaskRepository_TenantIsolation(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db, slog.Default())
+	tenantA := domain.ContextWithTenant(context.Background(), "tenant-a")
+	tenantB := domain.ContextWithTenant(context.Background(), "tenant-b")
+
+	task := &domain.Task{
+		Title:       "Tenant A Task",
+		Description: "Only visible to tenant A",
+		Status:      domain.StatusPending,
+	}
+	require.NoError(t, repo.Create(tenantA, task))
+	assert.Equal(t, "tenant-a", task.TenantID)
+
+	// Reads from another tenant do not see the task
+	_, err := repo.GetByID(tenantB, task.ID)
+	assert.ErrorIs(t, err, customerrors.ErrTaskNotFound)
+
+	tasks, err := repo.List(tenantB, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+
+	// Writes from another tenant do not touch the task
+	hijacked := *task
+	hijacked.Title = "Hijacked"
+	assert.ErrorIs(t, repo.Update(tenantB, &hijacked), customerrors.ErrTaskNotFound)
+	assert.ErrorIs(t, repo.Delete(tenantB, task.ID, task.Version), customerrors.ErrTaskNotFound)
+
+	stored, err := repo.GetByID(tenantA, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Tenant A Task", stored.Title)
+}
+
+func TestTaskRepository_UpdateComparesVersion(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db, slog.Default())
+	ctx := context.Background()
+
+	task := &domain.Task{
+		Title:       "Versioned Task",
+		Description: "Written by two clients",
+		Status:      domain.StatusPending,
+	}
+	require.NoError(t, repo.Create(ctx, task))
+	assert.Equal(t, int64(1), task.Version)
+
+	first := *task
+	first.Title = "First Writer"
+	require.NoError(t, repo.Update(ctx, &first))
+	assert.Equal(t, int64(2), first.Version)
+
+	// A writer still holding version 1 must not overwrite the first write
+	second := *task
+	second.Title = "Second Writer"
+	assert.ErrorIs(t, repo.Update(ctx, &second), customerrors.ErrTaskVersionConflict)
+	assert.ErrorIs(t, repo.Delete(ctx, task.ID, task.Version), customerrors.ErrTaskVersionConflict)
+
+	stored, err := repo.GetByID(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "First Writer", stored.Title)
+
+	require.NoError(t, repo.Delete(ctx, task.ID, stored.Version))
+}
+
+func TestTaskRepository_WithinTx(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db, slog.Default())
+	ctx := context.Background()
+
+	var created domain.Task
+	err := repo.WithinTx(ctx, func(ctx context.Context) error {
+		created = domain.Task{Title: "Rolled Back", Description: "Never committed", Status: domain.StatusPending}
+		require.NoError(t, repo.Create(ctx, &created))
+
+		// Reads within the transaction see its writes
+		_, err := repo.GetByID(ctx, created.ID)
+		require.NoError(t, err)
+		return errors.New("abort")
+	})
+	assert.EqualError(t, err, "abort")
+
+	_, err = repo.GetByID(ctx, created.ID)
+	assert.ErrorIs(t, err, customerrors.ErrTaskNotFound)
+
+	err = repo.WithinTx(ctx, func(ctx context.Context) error {
+		created = domain.Task{Title: "Committed", Description: "Kept", Status: domain.StatusPending}
+		return repo.Create(ctx, &created)
+	})
+	require.NoError(t, err)
+
+	_, err = repo.GetByID(ctx, created.ID)
+	assert.NoError(t, err)
+}
+
 // Add more tests for List, Update, and Delete...
+
+func TestTaskRepository_Trash(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db, slog.Default())
+	ctx := domain.ContextWithTenant(context.Background(), "trash-tenant")
+
+	task := &domain.Task{
+		Title:       "Deleted by mistake",
+		Description: "Restored from the trash",
+		Status:      domain.StatusPending,
+	}
+	require.NoError(t, repo.Create(ctx, task))
+	require.NoError(t, repo.Delete(ctx, task.ID, task.Version))
+
+	// Deleted tasks are hidden from ordinary reads and writes
+	_, err := repo.GetByID(ctx, task.ID)
+	assert.ErrorIs(t, err, customerrors.ErrTaskNotFound)
+	tasks, err := repo.List(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.Empty(t, tasks)
+	assert.ErrorIs(t, repo.Delete(ctx, task.ID, 2), customerrors.ErrTaskNotFound)
+
+	trash, err := repo.ListDeleted(ctx)
+	require.NoError(t, err)
+	require.Len(t, trash, 1)
+	assert.NotNil(t, trash[0].DeletedAt)
+	assert.Equal(t, int64(2), trash[0].Version)
+
+	restored, err := repo.Restore(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.Equal(t, int64(3), restored.Version)
+	_, err = repo.GetByID(ctx, task.ID)
+	require.NoError(t, err)
+
+	// Only tasks in the trash can be purged
+	assert.ErrorIs(t, repo.Purge(ctx, task.ID), customerrors.ErrTaskNotFound)
+	require.NoError(t, repo.Delete(ctx, task.ID, restored.Version))
+
+	purged, err := repo.PurgeDeletedBefore(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	require.NoError(t, repo.Purge(ctx, task.ID))
+
+	_, err = repo.GetDeletedByID(ctx, task.ID)
+	assert.ErrorIs(t, err, customerrors.ErrTaskNotFound)
+}
+
+func TestTaskRepository_Archive(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db, slog.Default())
+	ctx := domain.ContextWithTenant(context.Background(), "archive-tenant")
+
+	completed := &domain.Task{Title: "Shipped", Description: "Done long ago", Status: domain.StatusCompleted}
+	require.NoError(t, repo.Create(ctx, completed))
+	pending := &domain.Task{Title: "Open", Description: "Still to do", Status: domain.StatusPending}
+	require.NoError(t, repo.Create(ctx, pending))
+
+	archived, err := repo.ArchiveCompletedBefore(ctx, time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), archived)
+
+	// Archived tasks leave the live table but can still be read
+	_, err = repo.GetByID(ctx, completed.ID)
+	assert.ErrorIs(t, err, customerrors.ErrTaskNotFound)
+	stored, err := repo.GetArchivedByID(ctx, completed.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored.ArchivedAt)
+
+	tasks, err := repo.List(ctx, domain.TaskFilter{})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 1)
+	tasks, err = repo.List(ctx, domain.TaskFilter{IncludeArchived: true})
+	require.NoError(t, err)
+	assert.Len(t, tasks, 2)
+
+	unarchived, err := repo.Unarchive(ctx, completed.ID)
+	require.NoError(t, err)
+	assert.Nil(t, unarchived.ArchivedAt)
+	assert.Equal(t, completed.Version+1, unarchived.Version)
+
+	_, err = repo.GetArchivedByID(ctx, completed.ID)
+	assert.ErrorIs(t, err, customerrors.ErrTaskNotFound)
+	_, err = repo.GetByID(ctx, completed.ID)
+	require.NoError(t, err)
+}
+
+func TestTaskRepository_TaskStats(t *testing.T) {
+	db := setupTestDB(t)
+	defer db.Close()
+
+	repo := NewTaskRepository(db, slog.Default())
+	ctx := domain.ContextWithTenant(context.Background(), "stats-tenant")
+	now := time.Now()
+
+	tasks := []*domain.Task{
+		{Title: "Overdue", Description: "Late", Status: domain.StatusPending, DueDate: now.Add(-time.Hour)},
+		{Title: "Upcoming", Description: "On time", Status: domain.StatusInProgress, DueDate: now.Add(time.Hour)},
+		{Title: "Done late", Description: "Completed", Status: domain.StatusCompleted, DueDate: now.Add(-time.Hour)},
+		{Title: "Deleted", Description: "In the trash", Status: domain.StatusPending, DueDate: now.Add(-time.Hour)},
+	}
+	for _, task := range tasks {
+		require.NoError(t, repo.Create(ctx, task))
+	}
+	require.NoError(t, repo.Delete(ctx, tasks[3].ID, tasks[3].Version))
+
+	stats, err := repo.TaskStats(ctx, now)
+	require.NoError(t, err)
+	assert.Equal(t, map[domain.TaskStatus]int64{
+		domain.StatusPending:    1,
+		domain.StatusInProgress: 1,
+		domain.StatusCompleted:  1,
+	}, stats.ByStatus)
+	assert.Equal(t, int64(1), stats.Overdue)
+}
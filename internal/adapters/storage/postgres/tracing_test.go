@@ -0,0 +1,71 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// stubConn answers every query with err
+type stubConn struct {
+	err error
+}
+
+func (c stubConn) ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error) {
+	return nil, c.err
+}
+
+func (c stubConn) QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error) {
+	return nil, c.err
+}
+
+func (c stubConn) QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row {
+	return nil
+}
+
+func TestTracedConn(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter)))
+	defer otel.SetTracerProvider(previous)
+
+	t.Run("queries are recorded with their SQL", func(t *testing.T) {
+		exporter.Reset()
+
+		_, err := tracedConn{conn: stubConn{}}.ExecContext(context.Background(), `
+			UPDATE tasks
+			SET title = $1
+			WHERE id = $2`, "secret title", "id")
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "UPDATE", spans[0].Name)
+		assert.Equal(t, trace.SpanKindClient, spans[0].SpanKind)
+		assert.Contains(t, spans[0].Attributes, semconv.DBSystemPostgreSQL)
+		assert.Contains(t, spans[0].Attributes, semconv.DBOperationName("UPDATE"))
+		assert.Contains(t, spans[0].Attributes, semconv.DBQueryText("UPDATE tasks SET title = $1 WHERE id = $2"))
+	})
+
+	t.Run("failed queries are marked as errors", func(t *testing.T) {
+		exporter.Reset()
+
+		_, err := tracedConn{conn: stubConn{err: errors.New("connection reset")}}.QueryContext(context.Background(), "select 1")
+		require.Error(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, "SELECT", spans[0].Name)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+	})
+}
@@ -0,0 +1,68 @@
+package postgres
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+)
+
+type txContextKey struct{}
+
+// dbtx is the part of *sql.DB and *sql.Tx used by repositories, so that
+// queries run inside a transaction when the context carries one.
+type dbtx interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// conn returns the transaction carried by ctx, or db when there is none.
+// Queries run through it are traced and tagged with the request ID.
+func conn(ctx context.Context, db *sql.DB) dbtx {
+	if tx, ok := ctx.Value(txContextKey{}).(*sql.Tx); ok {
+		return instrument(tx)
+	}
+	return instrument(db)
+}
+
+// instrument traces the queries run through c and tags them with the
+// request ID, for stores managing a transaction of their own
+func instrument(c dbtx) dbtx {
+	return tracedConn{conn: requestIDConn{conn: c}}
+}
+
+// inTx reports whether ctx carries a transaction
+func inTx(ctx context.Context) bool {
+	_, ok := ctx.Value(txContextKey{}).(*sql.Tx)
+	return ok
+}
+
+// withinTx runs fn in a transaction, committing if it succeeds and rolling
+// back otherwise. Calls nested inside an existing transaction join it. A
+// failed rollback is logged, as fn's error is the one worth returning.
+func withinTx(ctx context.Context, db *sql.DB, logger *slog.Logger, fn func(ctx context.Context) error) error {
+	if inTx(ctx) {
+		return fn(ctx)
+	}
+
+	tx, err := db.BeginTx(ctx, nil)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer func() {
+		if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logger.WarnContext(ctx, "failed to roll back transaction", "error", err)
+		}
+	}()
+
+	if err := fn(context.WithValue(ctx, txContextKey{}, tx)); err != nil {
+		return err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"time"
+
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+)
+
+// AuthMethodAPIKey marks principals authenticated with a static API key
+const AuthMethodAPIKey = "api_key"
+
+type apiKey struct {
+	name      string
+	digest    [sha256.Size]byte
+	expiresAt time.Time
+	tenantID  string
+}
+
+// APIKeyAuthenticator validates credentials against a fixed set of API keys
+type APIKeyAuthenticator struct {
+	keys []apiKey
+	now  func() time.Time
+}
+
+// NewAPIKeyAuthenticator creates an authenticator accepting any of the given keys
+func NewAPIKeyAuthenticator(keys []config.APIKey) *APIKeyAuthenticator {
+	a := &APIKeyAuthenticator{
+		keys: make([]apiKey, 0, len(keys)),
+		now:  time.Now,
+	}
+	for _, key := range keys {
+		a.keys = append(a.keys, apiKey{
+			name:      key.Name,
+			digest:    sha256.Sum256([]byte(key.Key)),
+			expiresAt: key.ExpiresAt,
+			tenantID:  key.TenantID,
+		})
+	}
+	return a
+}
+
+// Authenticate checks the credential against every configured key. Keys are
+// hashed before comparison so that each check is constant time regardless
+// of the credential's length, and the loop never exits early.
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, credential string) (*domain.Principal, error) {
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	digest := sha256.Sum256([]byte(credential))
+	var matched *apiKey
+	for i := range a.keys {
+		if subtle.ConstantTimeCompare(digest[:], a.keys[i].digest[:]) == 1 {
+			matched = &a.keys[i]
+		}
+	}
+
+	if matched == nil {
+		return nil, ErrInvalidCredentials
+	}
+	if !matched.expiresAt.IsZero() && !a.now().Before(matched.expiresAt) {
+		return nil, ErrExpiredCredentials
+	}
+
+	return &domain.Principal{
+		ID:         "apikey:" + matched.name,
+		AuthMethod: AuthMethodAPIKey,
+		TenantID:   matched.tenantID,
+	}, nil
+}
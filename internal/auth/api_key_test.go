@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	primaryKey = "primary-key-0123456789abcdefghijklmnop"
+	rotatedKey = "rotated-key-0123456789abcdefghijklmnop"
+	tenantKey  = "tenant-key-0123456789abcdefghijklmnopq"
+)
+
+func TestAPIKeyAuthenticator_Authenticate(t *testing.T) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	authenticator := NewAPIKeyAuthenticator([]config.APIKey{
+		{Name: "primary", Key: primaryKey},
+		{Name: "old", Key: rotatedKey, ExpiresAt: now.Add(time.Hour)},
+		{Name: "acme", Key: tenantKey, TenantID: "acme"},
+	})
+	authenticator.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	t.Run("accepts the primary key", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(ctx, primaryKey)
+
+		require.NoError(t, err)
+		assert.Equal(t, "apikey:primary", principal.ID)
+		assert.Equal(t, AuthMethodAPIKey, principal.AuthMethod)
+		assert.Empty(t, principal.TenantID)
+	})
+
+	t.Run("binds principals to the key's tenant", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(ctx, tenantKey)
+
+		require.NoError(t, err)
+		assert.Equal(t, "apikey:acme", principal.ID)
+		assert.Equal(t, "acme", principal.TenantID)
+	})
+
+	t.Run("accepts a rotated key before it expires", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(ctx, rotatedKey)
+
+		require.NoError(t, err)
+		assert.Equal(t, "apikey:old", principal.ID)
+	})
+
+	t.Run("rejects a rotated key after it expires", func(t *testing.T) {
+		authenticator.now = func() time.Time { return now.Add(2 * time.Hour) }
+		defer func() { authenticator.now = func() time.Time { return now } }()
+
+		_, err := authenticator.Authenticate(ctx, rotatedKey)
+
+		assert.ErrorIs(t, err, ErrExpiredCredentials)
+	})
+
+	t.Run("rejects unknown keys", func(t *testing.T) {
+		_, err := authenticator.Authenticate(ctx, "not-a-key")
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("rejects missing keys", func(t *testing.T) {
+		_, err := authenticator.Authenticate(ctx, "")
+
+		assert.ErrorIs(t, err, ErrMissingCredentials)
+	})
+}
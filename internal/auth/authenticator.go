@@ -0,0 +1,25 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"task-tracking-service/internal/core/domain"
+)
+
+var (
+	// ErrMissingCredentials is returned when a request carries no credentials
+	ErrMissingCredentials = errors.New("missing credentials")
+
+	// ErrInvalidCredentials is returned when credentials are not recognised
+	ErrInvalidCredentials = errors.New("invalid credentials")
+
+	// ErrExpiredCredentials is returned when credentials were valid but have expired
+	ErrExpiredCredentials = errors.New("credentials have expired")
+)
+
+// Authenticator resolves a raw credential, such as an API key or bearer
+// token, into the principal making the request.
+type Authenticator interface {
+	Authenticate(ctx context.Context, credential string) (*domain.Principal, error)
+}
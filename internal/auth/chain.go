@@ -0,0 +1,39 @@
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"task-tracking-service/internal/core/domain"
+)
+
+// ErrUnsupportedCredential is returned by an authenticator that does not
+// understand the credential's format, letting the next one in a chain try.
+var ErrUnsupportedCredential = errors.New("unsupported credential")
+
+// Chain tries each authenticator in turn until one accepts or rejects the
+// credential.
+type Chain []Authenticator
+
+// NewChain creates an authenticator that delegates to the given authenticators
+func NewChain(authenticators ...Authenticator) Chain {
+	return Chain(authenticators)
+}
+
+// Authenticate returns the result of the first authenticator that supports
+// the credential.
+func (c Chain) Authenticate(ctx context.Context, credential string) (*domain.Principal, error) {
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+
+	for _, authenticator := range c {
+		principal, err := authenticator.Authenticate(ctx, credential)
+		if errors.Is(err, ErrUnsupportedCredential) {
+			continue
+		}
+		return principal, err
+	}
+
+	return nil, ErrInvalidCredentials
+}
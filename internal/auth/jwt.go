@@ -0,0 +1,87 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// AuthMethodJWT marks principals authenticated with an identity provider token
+const AuthMethodJWT = "jwt"
+
+// tenantClaim binds a token to a single tenant
+const tenantClaim = "tenant_id"
+
+// clockSkew tolerates small clock differences between us and the identity provider
+const clockSkew = 30 * time.Second
+
+// KeySource resolves the public key used to verify a token
+type KeySource interface {
+	Key(ctx context.Context, kid string) (any, error)
+}
+
+// JWTAuthenticator validates RS256 and ES256 bearer tokens issued by an
+// identity provider.
+type JWTAuthenticator struct {
+	keys   KeySource
+	parser *jwt.Parser
+}
+
+// NewJWTAuthenticator creates an authenticator that accepts tokens signed by
+// keys from the given source for the expected issuer and audience.
+func NewJWTAuthenticator(keys KeySource, issuer, audience string) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		keys: keys,
+		parser: jwt.NewParser(
+			jwt.WithValidMethods([]string{jwt.SigningMethodRS256.Alg(), jwt.SigningMethodES256.Alg()}),
+			jwt.WithIssuer(issuer),
+			jwt.WithAudience(audience),
+			jwt.WithExpirationRequired(),
+			jwt.WithLeeway(clockSkew),
+		),
+	}
+}
+
+// Authenticate verifies the token's signature and registered claims. Values
+// that are not shaped like a JWT are left for other authenticators.
+func (a *JWTAuthenticator) Authenticate(ctx context.Context, credential string) (*domain.Principal, error) {
+	if credential == "" {
+		return nil, ErrMissingCredentials
+	}
+	if strings.Count(credential, ".") != 2 {
+		return nil, ErrUnsupportedCredential
+	}
+
+	claims := jwt.MapClaims{}
+	_, err := a.parser.ParseWithClaims(credential, claims, func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		return a.keys.Key(ctx, kid)
+	})
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredCredentials
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidCredentials, err)
+	}
+
+	subject, err := claims.GetSubject()
+	if err != nil || subject == "" {
+		return nil, fmt.Errorf("%w: token has no subject", ErrInvalidCredentials)
+	}
+	issuer, _ := claims.GetIssuer()
+	tenantID, _ := claims[tenantClaim].(string)
+
+	return &domain.Principal{
+		ID:         subject,
+		AuthMethod: AuthMethodJWT,
+		Issuer:     issuer,
+		TenantID:   tenantID,
+		Claims:     claims,
+	}, nil
+}
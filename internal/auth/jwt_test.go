@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testIssuer   = "https://idp.example.com/"
+	testAudience = "task-tracking-service"
+)
+
+// testKey is a locally generated signing key published through a test JWKS
+type testKey struct {
+	kid    string
+	method jwt.SigningMethod
+	signer crypto.Signer
+}
+
+func newRSAKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	return testKey{kid: kid, method: jwt.SigningMethodRS256, signer: key}
+}
+
+func newECKey(t *testing.T, kid string) testKey {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	return testKey{kid: kid, method: jwt.SigningMethodES256, signer: key}
+}
+
+func (k testKey) jwk() map[string]string {
+	encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+
+	switch pub := k.signer.Public().(type) {
+	case *rsa.PublicKey:
+		return map[string]string{
+			"kty": "RSA", "kid": k.kid, "use": "sig", "alg": "RS256",
+			"n": encode(pub.N), "e": encode(big.NewInt(int64(pub.E))),
+		}
+	case *ecdsa.PublicKey:
+		return map[string]string{
+			"kty": "EC", "kid": k.kid, "use": "sig", "alg": "ES256", "crv": "P-256",
+			"x": encode(pub.X), "y": encode(pub.Y),
+		}
+	}
+	return nil
+}
+
+func (k testKey) sign(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(k.method, claims)
+	token.Header["kid"] = k.kid
+	signed, err := token.SignedString(k.signer)
+	require.NoError(t, err)
+	return signed
+}
+
+func jwksJSON(t *testing.T, keys ...testKey) []byte {
+	t.Helper()
+	set := map[string][]map[string]string{"keys": {}}
+	for _, key := range keys {
+		set["keys"] = append(set["keys"], key.jwk())
+	}
+	data, err := json.Marshal(set)
+	require.NoError(t, err)
+	return data
+}
+
+func validClaims() jwt.MapClaims {
+	return jwt.MapClaims{
+		"sub":   "user-123",
+		"iss":   testIssuer,
+		"aud":   testAudience,
+		"exp":   time.Now().Add(time.Hour).Unix(),
+		"email": "user@example.com",
+	}
+}
+
+func TestJWTAuthenticator_Authenticate(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	ecKey := newECKey(t, "ec-1")
+
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(t, rsaKey, ecKey), 0o600))
+	authenticator := NewJWTAuthenticator(NewFileJWKS(path, time.Hour), testIssuer, testAudience)
+	ctx := context.Background()
+
+	t.Run("accepts RS256 tokens", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(ctx, rsaKey.sign(t, validClaims()))
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", principal.ID)
+		assert.Equal(t, AuthMethodJWT, principal.AuthMethod)
+		assert.Equal(t, testIssuer, principal.Issuer)
+		assert.Equal(t, "user@example.com", principal.StringClaim("email"))
+		assert.Empty(t, principal.TenantID)
+	})
+
+	t.Run("binds principals to the tenant claim", func(t *testing.T) {
+		claims := validClaims()
+		claims["tenant_id"] = "acme"
+
+		principal, err := authenticator.Authenticate(ctx, rsaKey.sign(t, claims))
+
+		require.NoError(t, err)
+		assert.Equal(t, "acme", principal.TenantID)
+	})
+
+	t.Run("accepts ES256 tokens", func(t *testing.T) {
+		principal, err := authenticator.Authenticate(ctx, ecKey.sign(t, validClaims()))
+
+		require.NoError(t, err)
+		assert.Equal(t, "user-123", principal.ID)
+	})
+
+	t.Run("rejects invalid tokens", func(t *testing.T) {
+		tests := []struct {
+			name   string
+			modify func(jwt.MapClaims)
+			key    testKey
+		}{
+			{name: "wrong issuer", modify: func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com/" }, key: rsaKey},
+			{name: "wrong audience", modify: func(c jwt.MapClaims) { c["aud"] = "another-service" }, key: rsaKey},
+			{name: "missing expiry", modify: func(c jwt.MapClaims) { delete(c, "exp") }, key: rsaKey},
+			{name: "missing subject", modify: func(c jwt.MapClaims) { delete(c, "sub") }, key: rsaKey},
+			{name: "unknown key", modify: func(jwt.MapClaims) {}, key: newRSAKey(t, "rsa-unknown")},
+			{name: "untrusted key reusing a kid", modify: func(jwt.MapClaims) {}, key: newECKey(t, "ec-1")},
+		}
+
+		for _, tt := range tests {
+			t.Run(tt.name, func(t *testing.T) {
+				claims := validClaims()
+				tt.modify(claims)
+
+				_, err := authenticator.Authenticate(ctx, tt.key.sign(t, claims))
+
+				assert.ErrorIs(t, err, ErrInvalidCredentials)
+			})
+		}
+	})
+
+	t.Run("rejects expired tokens", func(t *testing.T) {
+		claims := validClaims()
+		claims["exp"] = time.Now().Add(-time.Hour).Unix()
+
+		_, err := authenticator.Authenticate(ctx, rsaKey.sign(t, claims))
+
+		assert.ErrorIs(t, err, ErrExpiredCredentials)
+	})
+
+	t.Run("rejects unsigned tokens", func(t *testing.T) {
+		token := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims())
+		unsigned, err := token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		require.NoError(t, err)
+
+		_, err = authenticator.Authenticate(ctx, unsigned)
+
+		assert.ErrorIs(t, err, ErrInvalidCredentials)
+	})
+
+	t.Run("leaves non-JWT credentials to other authenticators", func(t *testing.T) {
+		_, err := authenticator.Authenticate(ctx, "an-api-key")
+
+		assert.ErrorIs(t, err, ErrUnsupportedCredential)
+	})
+}
+
+func TestRemoteJWKS_KeyRotation(t *testing.T) {
+	oldKey := newRSAKey(t, "old")
+	newKey := newECKey(t, "new")
+
+	var mutex sync.Mutex
+	published := jwksJSON(t, oldKey)
+	fetches := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		defer mutex.Unlock()
+		fetches++
+		w.Write(published)
+	}))
+	defer server.Close()
+
+	now := time.Now()
+	jwks := NewRemoteJWKS(server.URL, time.Hour, server.Client())
+	jwks.now = func() time.Time { return now }
+	authenticator := NewJWTAuthenticator(jwks, testIssuer, testAudience)
+	ctx := context.Background()
+
+	_, err := authenticator.Authenticate(ctx, oldKey.sign(t, validClaims()))
+	require.NoError(t, err)
+
+	// Keys are served from the cache until they go stale
+	_, err = authenticator.Authenticate(ctx, oldKey.sign(t, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, 1, fetches)
+
+	// The provider rotates to a new key
+	mutex.Lock()
+	published = jwksJSON(t, newKey)
+	mutex.Unlock()
+
+	// An unknown kid triggers a refresh once the minimum interval has passed
+	now = now.Add(minRefreshInterval)
+	_, err = authenticator.Authenticate(ctx, newKey.sign(t, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, 2, fetches)
+
+	// Tokens signed with the retired key are no longer accepted
+	_, err = authenticator.Authenticate(ctx, oldKey.sign(t, validClaims()))
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+}
+
+func TestChain_Authenticate(t *testing.T) {
+	rsaKey := newRSAKey(t, "rsa-1")
+	path := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(path, jwksJSON(t, rsaKey), 0o600))
+
+	chain := NewChain(
+		NewJWTAuthenticator(NewFileJWKS(path, time.Hour), testIssuer, testAudience),
+		NewAPIKeyAuthenticator(nil),
+	)
+	ctx := context.Background()
+
+	principal, err := chain.Authenticate(ctx, rsaKey.sign(t, validClaims()))
+	require.NoError(t, err)
+	assert.Equal(t, AuthMethodJWT, principal.AuthMethod)
+
+	_, err = chain.Authenticate(ctx, "not-a-known-api-key")
+	assert.ErrorIs(t, err, ErrInvalidCredentials)
+
+	_, err = chain.Authenticate(ctx, "")
+	assert.ErrorIs(t, err, ErrMissingCredentials)
+}
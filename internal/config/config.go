@@ -1,29 +1,57 @@
 package config
 
 import (
+	"encoding/json"
 	"fmt"
+	"log/slog"
+	"net"
 	"os"
+	"strings"
+	"time"
+
+	"task-tracking-service/internal/core/domain"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/spf13/viper"
 )
 
-// SensitiveValue is used for fields that shouldn't be logged
+// SensitiveValue is used for fields that shouldn't be logged. It is
+// redacted when printed, logged with slog or encoded as JSON.
 type SensitiveValue string
 
+const redacted = "[REDACTED]"
+
 func (s SensitiveValue) String() string {
-	return "[REDACTED]"
+	return redacted
+}
+
+func (s SensitiveValue) LogValue() slog.Value {
+	return slog.StringValue(redacted)
+}
+
+func (s SensitiveValue) MarshalJSON() ([]byte, error) {
+	return json.Marshal(redacted)
 }
 
 // Config struct with proper validation tags
 type Config struct {
-	Environment string           `validate:"required,oneof=development staging production"`
-	Server      ServerConfig     `validate:"required"`
-	Database    DatabaseConfig   `validate:"required"`
-	API         APIConfig        `validate:"required"`
-	Logging     LogConfig        `validate:"required"`
-	Features    FeatureConfig    `validate:"required"`
-	Repository  RepositoryConfig `validate:"required"`
+	Environment string            `validate:"required,oneof=development staging production"`
+	Server      ServerConfig      `validate:"required"`
+	GRPC        GRPCConfig        `validate:"required"`
+	GraphQL     GraphQLConfig     `validate:"required"`
+	Database    DatabaseConfig    `validate:"required"`
+	API         APIConfig         `validate:"required"`
+	Auth        AuthConfig        `validate:"required"`
+	RBAC        RBACConfig        `validate:"required"`
+	RateLimit   RateLimitConfig   `validate:"required"`
+	Idempotency IdempotencyConfig `validate:"required"`
+	Batch       BatchConfig       `validate:"required"`
+	Trash       TrashConfig       `validate:"required"`
+	Archive     ArchiveConfig     `validate:"required"`
+	Logging     LogConfig         `validate:"required"`
+	Tracing     TracingConfig     `validate:"required"`
+	Features    FeatureConfig     `validate:"required"`
+	Repository  RepositoryConfig  `validate:"required"`
 }
 
 type ServerConfig struct {
@@ -32,6 +60,45 @@ type ServerConfig struct {
 	ReadTimeout  string `validate:"required"`
 	WriteTimeout string `validate:"required"`
 	BaseURL      string `validate:"required,url"`
+	// AdminPort, if set, serves operational endpoints such as /metrics
+	// on a port of their own instead of the API port
+	AdminPort string `validate:"omitempty,numeric"`
+	// TrustedProxies lists the proxies, as IPs or CIDR ranges separated by
+	// commas, whose X-Forwarded-For header names the client. Without any,
+	// the client is the address the connection comes from.
+	TrustedProxies string
+}
+
+// TrustedProxyRanges parses TrustedProxies. A bare IP is a range of one.
+func (c ServerConfig) TrustedProxyRanges() ([]*net.IPNet, error) {
+	var ranges []*net.IPNet
+	for _, entry := range strings.Split(c.TrustedProxies, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		if !strings.Contains(entry, "/") {
+			ip := net.ParseIP(entry)
+			if ip == nil {
+				return nil, fmt.Errorf("invalid SERVER_TRUSTED_PROXIES entry %q", entry)
+			}
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip, bits = ip.To4(), 8*net.IPv4len
+			}
+			ranges = append(ranges, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+
+		_, ipRange, err := net.ParseCIDR(entry)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SERVER_TRUSTED_PROXIES entry %q: %w", entry, err)
+		}
+		ranges = append(ranges, ipRange)
+	}
+
+	return ranges, nil
 }
 
 type DatabaseConfig struct {
@@ -47,16 +114,220 @@ type DatabaseConfig struct {
 }
 
 type APIConfig struct {
-	BasePath       string         `validate:"required"`
-	APIKey         SensitiveValue `validate:"required,min=32"`
+	BasePath string         `validate:"required"`
+	APIKey   SensitiveValue `validate:"required,min=32"`
+	// APIKeyTenant binds the primary key to a tenant other than the default
+	APIKeyTenant   string
+	APIKeys        SensitiveValue // Optional extra keys: name[@tenant]=key[@RFC3339 expiry], comma separated
 	AllowedOrigins string         `validate:"required"`
 }
 
+// APIKey is a named API key accepted by the service. A zero ExpiresAt
+// means the key never expires. Callers using the key act within TenantID,
+// or the default tenant when it is empty.
+type APIKey struct {
+	Name      string
+	Key       SensitiveValue
+	ExpiresAt time.Time
+	TenantID  string
+}
+
+// minAPIKeyLength mirrors the min=32 rule applied to API_KEY
+const minAPIKeyLength = 32
+
+// Keys returns the primary API key followed by any additional keys from
+// API_KEYS, which allows old keys to keep working while clients rotate.
+func (c APIConfig) Keys() ([]APIKey, error) {
+	if c.APIKeyTenant != "" && !domain.IsValidTenantID(c.APIKeyTenant) {
+		return nil, fmt.Errorf("invalid API_KEY_TENANT %q", c.APIKeyTenant)
+	}
+	keys := []APIKey{{Name: "primary", Key: c.APIKey, TenantID: c.APIKeyTenant}}
+	if strings.TrimSpace(string(c.APIKeys)) == "" {
+		return keys, nil
+	}
+
+	names := map[string]bool{"primary": true}
+	for _, entry := range strings.Split(string(c.APIKeys), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		name, rest, ok := strings.Cut(entry, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid API_KEYS entry: expected name[@tenant]=key[@expiry]")
+		}
+		name, tenantID, bound := strings.Cut(name, "@")
+		if bound && !domain.IsValidTenantID(tenantID) {
+			return nil, fmt.Errorf("invalid tenant for API key %q", name)
+		}
+		if names[name] {
+			return nil, fmt.Errorf("duplicate API key name %q", name)
+		}
+		names[name] = true
+
+		key := APIKey{Name: name, Key: SensitiveValue(rest), TenantID: tenantID}
+		if i := strings.LastIndex(rest, "@"); i >= 0 {
+			expiresAt, err := time.Parse(time.RFC3339, rest[i+1:])
+			if err != nil {
+				return nil, fmt.Errorf("invalid expiry for API key %q: %w", name, err)
+			}
+			key.Key = SensitiveValue(rest[:i])
+			key.ExpiresAt = expiresAt
+		}
+
+		if len(key.Key) < minAPIKeyLength {
+			return nil, fmt.Errorf("API key %q must be at least %d characters", name, minAPIKeyLength)
+		}
+		keys = append(keys, key)
+	}
+
+	return keys, nil
+}
+
+// AuthConfig configures bearer token validation against an identity
+// provider. JWT authentication is enabled when a JWKS URL or file is set.
+type AuthConfig struct {
+	JWKSURL             string `validate:"omitempty,url"`
+	JWKSFile            string
+	Issuer              string `validate:"required_with=JWKSURL JWKSFile"`
+	Audience            string `validate:"required_with=JWKSURL JWKSFile"`
+	JWKSRefreshInterval string `validate:"required"`
+}
+
+// JWTEnabled reports whether a JWKS source has been configured
+func (c AuthConfig) JWTEnabled() bool {
+	return c.JWKSURL != "" || c.JWKSFile != ""
+}
+
+// RBACConfig configures role-based access control on task operations
+type RBACConfig struct {
+	Bindings    string // subject=role[@project], comma separated
+	DefaultRole string `validate:"omitempty,oneof=viewer member admin"`
+}
+
+// RoleBindings parses the configured bindings
+func (c RBACConfig) RoleBindings() ([]domain.RoleBinding, error) {
+	var bindings []domain.RoleBinding
+	for _, entry := range strings.Split(c.Bindings, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		subject, grant, ok := strings.Cut(entry, "=")
+		if !ok || subject == "" {
+			return nil, fmt.Errorf("invalid RBAC_BINDINGS entry %q: expected subject=role[@project]", entry)
+		}
+
+		role, project, _ := strings.Cut(grant, "@")
+		binding := domain.RoleBinding{Subject: subject, Role: domain.Role(role), ProjectID: project}
+		if !binding.Role.IsValid() {
+			return nil, fmt.Errorf("unknown role %q in RBAC_BINDINGS entry %q", role, entry)
+		}
+		bindings = append(bindings, binding)
+	}
+
+	return bindings, nil
+}
+
+// RateLimitConfig configures per-caller token bucket limits, expressed as
+// requests per minute for read (GET/HEAD) and write routes.
+type RateLimitConfig struct {
+	Enabled        bool
+	Store          string `validate:"required,oneof=memory postgres"`
+	ReadPerMinute  int    `validate:"min=1"`
+	WritePerMinute int    `validate:"min=1"`
+	Overrides      string // subject=read/write, comma separated
+}
+
+// RateLimitOverride replaces the default limits for a single caller
+type RateLimitOverride struct {
+	ReadPerMinute  int
+	WritePerMinute int
+}
+
+// KeyOverrides parses the per-caller overrides, keyed by principal ID
+func (c RateLimitConfig) KeyOverrides() (map[string]RateLimitOverride, error) {
+	overrides := make(map[string]RateLimitOverride)
+	for _, entry := range strings.Split(c.Overrides, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		subject, limits, ok := strings.Cut(entry, "=")
+		if !ok || subject == "" {
+			return nil, fmt.Errorf("invalid RATE_LIMIT_OVERRIDES entry %q: expected subject=read/write", entry)
+		}
+
+		var override RateLimitOverride
+		if _, err := fmt.Sscanf(limits, "%d/%d", &override.ReadPerMinute, &override.WritePerMinute); err != nil ||
+			override.ReadPerMinute < 1 || override.WritePerMinute < 1 {
+			return nil, fmt.Errorf("invalid limits in RATE_LIMIT_OVERRIDES entry %q", entry)
+		}
+		overrides[subject] = override
+	}
+
+	return overrides, nil
+}
+
+// GRPCConfig configures the gRPC API, served on the API host next to the
+// REST API. An empty port disables it.
+type GRPCConfig struct {
+	Port string `validate:"omitempty,numeric"`
+	// WatchInterval is how often WatchTasks looks for changes
+	WatchInterval string `validate:"required"`
+}
+
+// GraphQLConfig bounds the operations accepted by the GraphQL endpoint
+type GraphQLConfig struct {
+	// MaxDepth caps how deeply fields may be nested
+	MaxDepth int `validate:"min=1"`
+	// MaxComplexity caps the estimated number of fields an operation resolves
+	MaxComplexity int `validate:"min=1"`
+}
+
+// IdempotencyConfig configures where responses to requests carrying an
+// Idempotency-Key are kept, and for how long.
+type IdempotencyConfig struct {
+	Store string `validate:"required,oneof=memory postgres"`
+	TTL   string `validate:"required"`
+}
+
+// BatchConfig limits POST /task:batch
+type BatchConfig struct {
+	MaxSize int `validate:"min=1"`
+}
+
+// TrashConfig controls how long deleted tasks stay restorable. A retention
+// of 0 keeps them until an admin purges them.
+type TrashConfig struct {
+	Retention     string `validate:"required"`
+	PurgeInterval string `validate:"required"`
+}
+
+// ArchiveConfig controls when completed tasks move into the archive. An
+// After of 0 leaves archiving to the archive command.
+type ArchiveConfig struct {
+	After    string `validate:"required"`
+	Interval string `validate:"required"`
+}
+
 type LogConfig struct {
 	Level  string `validate:"required,oneof=debug info warn error"`
 	Format string `validate:"required,oneof=text json"`
 }
 
+// TracingConfig selects where OpenTelemetry spans are exported. Without an
+// endpoint, the OTLP exporter follows the standard OTEL_EXPORTER_OTLP_*
+// variables.
+type TracingConfig struct {
+	Exporter     string `validate:"required,oneof=none stdout otlp"`
+	OTLPEndpoint string `validate:"omitempty,url"`
+	ServiceName  string `validate:"required"`
+}
+
 type FeatureConfig struct {
 	EnableSwagger bool
 	EnableMetrics bool
@@ -90,6 +361,22 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("validation error: %w", err)
 	}
 
+	if _, err := c.Server.TrustedProxyRanges(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	if _, err := c.API.Keys(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	if _, err := c.RBAC.RoleBindings(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
+	if _, err := c.RateLimit.KeyOverrides(); err != nil {
+		return fmt.Errorf("validation error: %w", err)
+	}
+
 	// Then perform environment-specific validation
 	if c.Environment == "production" {
 		// Validate SSL mode in production
@@ -137,6 +424,14 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("SERVER_READ_TIMEOUT", "60s")
 	v.SetDefault("SERVER_WRITE_TIMEOUT", "60s")
 	v.SetDefault("SERVER_BASE_URL", "http://localhost:8080")
+	v.SetDefault("SERVER_ADMIN_PORT", "")
+	v.SetDefault("SERVER_TRUSTED_PROXIES", "")
+
+	v.SetDefault("GRPC_PORT", "9090")
+	v.SetDefault("GRPC_WATCH_INTERVAL", "2s")
+
+	v.SetDefault("GRAPHQL_MAX_DEPTH", 10)
+	v.SetDefault("GRAPHQL_MAX_COMPLEXITY", 1000)
 
 	v.SetDefault("DB_HOST", "localhost")
 	v.SetDefault("DB_PORT", "5432")
@@ -145,9 +440,34 @@ func setDefaults(v *viper.Viper) {
 	v.SetDefault("DB_MAX_IDLE_CONNS", 5)
 	v.SetDefault("DB_CONN_MAX_LIFETIME", "5m")
 
+	v.SetDefault("AUTH_JWKS_REFRESH_INTERVAL", "15m")
+
+	// The primary API key keeps full access unless bindings are configured
+	v.SetDefault("RBAC_BINDINGS", "apikey:primary=admin")
+
+	v.SetDefault("RATE_LIMIT_ENABLED", true)
+	v.SetDefault("RATE_LIMIT_STORE", "memory")
+	v.SetDefault("RATE_LIMIT_READ_PER_MINUTE", 600)
+	v.SetDefault("RATE_LIMIT_WRITE_PER_MINUTE", 120)
+
+	v.SetDefault("IDEMPOTENCY_STORE", "memory")
+	v.SetDefault("IDEMPOTENCY_TTL", "24h")
+
+	v.SetDefault("BATCH_MAX_SIZE", 100)
+
+	v.SetDefault("TRASH_RETENTION", "720h")
+	v.SetDefault("TRASH_PURGE_INTERVAL", "1h")
+
+	v.SetDefault("ARCHIVE_AFTER", "2160h")
+	v.SetDefault("ARCHIVE_INTERVAL", "24h")
+
 	v.SetDefault("LOG_LEVEL", "info")
 	v.SetDefault("LOG_FORMAT", "text")
 
+	v.SetDefault("TRACING_EXPORTER", "none")
+	v.SetDefault("TRACING_OTLP_ENDPOINT", "")
+	v.SetDefault("TRACING_SERVICE_NAME", "task-tracking-service")
+
 	v.SetDefault("ENABLE_SWAGGER", true)
 	v.SetDefault("ENABLE_METRICS", true)
 
@@ -186,6 +506,14 @@ func Load() (*Config, error) {
 	config.Server.ReadTimeout = v.GetString("SERVER_READ_TIMEOUT")
 	config.Server.WriteTimeout = v.GetString("SERVER_WRITE_TIMEOUT")
 	config.Server.BaseURL = v.GetString("SERVER_BASE_URL")
+	config.Server.AdminPort = v.GetString("SERVER_ADMIN_PORT")
+	config.Server.TrustedProxies = v.GetString("SERVER_TRUSTED_PROXIES")
+
+	config.GRPC.Port = v.GetString("GRPC_PORT")
+	config.GRPC.WatchInterval = v.GetString("GRPC_WATCH_INTERVAL")
+
+	config.GraphQL.MaxDepth = v.GetInt("GRAPHQL_MAX_DEPTH")
+	config.GraphQL.MaxComplexity = v.GetInt("GRAPHQL_MAX_COMPLEXITY")
 
 	config.Database.Host = v.GetString("DB_HOST")
 	config.Database.Port = v.GetString("DB_PORT")
@@ -199,11 +527,43 @@ func Load() (*Config, error) {
 
 	config.API.BasePath = v.GetString("API_BASE_PATH")
 	config.API.APIKey = SensitiveValue(v.GetString("API_KEY"))
+	config.API.APIKeyTenant = v.GetString("API_KEY_TENANT")
+	config.API.APIKeys = SensitiveValue(v.GetString("API_KEYS"))
 	config.API.AllowedOrigins = v.GetString("CORS_ALLOWED_ORIGINS")
 
+	config.Auth.JWKSURL = v.GetString("AUTH_JWKS_URL")
+	config.Auth.JWKSFile = v.GetString("AUTH_JWKS_FILE")
+	config.Auth.Issuer = v.GetString("AUTH_ISSUER")
+	config.Auth.Audience = v.GetString("AUTH_AUDIENCE")
+	config.Auth.JWKSRefreshInterval = v.GetString("AUTH_JWKS_REFRESH_INTERVAL")
+
+	config.RBAC.Bindings = v.GetString("RBAC_BINDINGS")
+	config.RBAC.DefaultRole = v.GetString("RBAC_DEFAULT_ROLE")
+
+	config.RateLimit.Enabled = v.GetBool("RATE_LIMIT_ENABLED")
+	config.RateLimit.Store = v.GetString("RATE_LIMIT_STORE")
+	config.RateLimit.ReadPerMinute = v.GetInt("RATE_LIMIT_READ_PER_MINUTE")
+	config.RateLimit.WritePerMinute = v.GetInt("RATE_LIMIT_WRITE_PER_MINUTE")
+	config.RateLimit.Overrides = v.GetString("RATE_LIMIT_OVERRIDES")
+
+	config.Idempotency.Store = v.GetString("IDEMPOTENCY_STORE")
+	config.Idempotency.TTL = v.GetString("IDEMPOTENCY_TTL")
+
+	config.Batch.MaxSize = v.GetInt("BATCH_MAX_SIZE")
+
+	config.Trash.Retention = v.GetString("TRASH_RETENTION")
+	config.Trash.PurgeInterval = v.GetString("TRASH_PURGE_INTERVAL")
+
+	config.Archive.After = v.GetString("ARCHIVE_AFTER")
+	config.Archive.Interval = v.GetString("ARCHIVE_INTERVAL")
+
 	config.Logging.Level = v.GetString("LOG_LEVEL")
 	config.Logging.Format = v.GetString("LOG_FORMAT")
 
+	config.Tracing.Exporter = v.GetString("TRACING_EXPORTER")
+	config.Tracing.OTLPEndpoint = v.GetString("TRACING_OTLP_ENDPOINT")
+	config.Tracing.ServiceName = v.GetString("TRACING_SERVICE_NAME")
+
 	config.Features.EnableSwagger = v.GetBool("ENABLE_SWAGGER")
 	config.Features.EnableMetrics = v.GetBool("ENABLE_METRICS")
 
@@ -1,10 +1,15 @@
 package config
 
 import (
+	"bytes"
+	"encoding/json"
 	"fmt"
+	"log/slog"
 	"os"
 	"testing"
 
+	"task-tracking-service/internal/core/domain"
+
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -264,6 +269,47 @@ func TestConfig_Validation(t *testing.T) {
 			expectedError: true,
 			errorMessage:  "min",
 		},
+		{
+			name: "unknown tracing exporter",
+			modifications: map[string]string{
+				"TRACING_EXPORTER": "jaeger",
+			},
+			expectedError: true,
+			errorMessage:  "oneof",
+		},
+		{
+			name: "non-numeric gRPC port",
+			modifications: map[string]string{
+				"GRPC_PORT": "grpc",
+			},
+			expectedError: true,
+			errorMessage:  "numeric",
+		},
+		{
+			name: "zero GraphQL depth",
+			modifications: map[string]string{
+				"GRAPHQL_MAX_DEPTH": "0",
+			},
+			expectedError: true,
+			errorMessage:  "min",
+		},
+		{
+			name: "JWKS without issuer and audience",
+			modifications: map[string]string{
+				"AUTH_JWKS_URL": "https://idp.example.com/.well-known/jwks.json",
+			},
+			expectedError: true,
+			errorMessage:  "required_with",
+		},
+		{
+			name: "JWKS with issuer and audience",
+			modifications: map[string]string{
+				"AUTH_JWKS_URL": "https://idp.example.com/.well-known/jwks.json",
+				"AUTH_ISSUER":   "https://idp.example.com/",
+				"AUTH_AUDIENCE": "task-tracking-service",
+			},
+			expectedError: false,
+		},
 	}
 
 	for _, tt := range tests {
@@ -290,9 +336,146 @@ func TestConfig_Validation(t *testing.T) {
 	}
 }
 
+func TestAPIConfig_Keys(t *testing.T) {
+	const primary = "dev_12345678901234567890123456789012"
+
+	t.Run("primary key only", func(t *testing.T) {
+		cfg := APIConfig{APIKey: primary}
+
+		keys, err := cfg.Keys()
+
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.Equal(t, "primary", keys[0].Name)
+		assert.True(t, keys[0].ExpiresAt.IsZero())
+	})
+
+	t.Run("additional keys with expiry", func(t *testing.T) {
+		cfg := APIConfig{
+			APIKey:  primary,
+			APIKeys: "old=old_1234567890123456789012345678901@2026-01-01T00:00:00Z, ci=ci_12345678901234567890123456789012",
+		}
+
+		keys, err := cfg.Keys()
+
+		require.NoError(t, err)
+		require.Len(t, keys, 3)
+		assert.Equal(t, "old", keys[1].Name)
+		assert.Equal(t, SensitiveValue("old_1234567890123456789012345678901"), keys[1].Key)
+		assert.Equal(t, 2026, keys[1].ExpiresAt.Year())
+		assert.Equal(t, "ci", keys[2].Name)
+		assert.True(t, keys[2].ExpiresAt.IsZero())
+	})
+
+	t.Run("keys bound to tenants", func(t *testing.T) {
+		cfg := APIConfig{
+			APIKey:       primary,
+			APIKeyTenant: "acme",
+			APIKeys:      "globex@globex=gx_12345678901234567890123456789012@2026-01-01T00:00:00Z",
+		}
+
+		keys, err := cfg.Keys()
+
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, "acme", keys[0].TenantID)
+		assert.Equal(t, "globex", keys[1].Name)
+		assert.Equal(t, "globex", keys[1].TenantID)
+		assert.Equal(t, SensitiveValue("gx_12345678901234567890123456789012"), keys[1].Key)
+		assert.Equal(t, 2026, keys[1].ExpiresAt.Year())
+	})
+
+	t.Run("rejects malformed entries", func(t *testing.T) {
+		for _, keys := range []string{
+			"no-name-separator",
+			"short=tooshort",
+			"bad=old_1234567890123456789012345678901@yesterday",
+			"primary=ci_12345678901234567890123456789012",
+			"ci@../etc=ci_12345678901234567890123456789012",
+		} {
+			_, err := APIConfig{APIKey: primary, APIKeys: SensitiveValue(keys)}.Keys()
+			assert.Error(t, err, keys)
+		}
+
+		_, err := APIConfig{APIKey: primary, APIKeyTenant: "not a tenant"}.Keys()
+		assert.Error(t, err)
+	})
+}
+
+func TestRBACConfig_RoleBindings(t *testing.T) {
+	t.Run("parses global and project bindings", func(t *testing.T) {
+		cfg := RBACConfig{Bindings: "apikey:primary=admin, alice=member@proj-1,bob=viewer"}
+
+		bindings, err := cfg.RoleBindings()
+
+		require.NoError(t, err)
+		assert.Equal(t, []domain.RoleBinding{
+			{Subject: "apikey:primary", Role: domain.RoleAdmin},
+			{Subject: "alice", Role: domain.RoleMember, ProjectID: "proj-1"},
+			{Subject: "bob", Role: domain.RoleViewer},
+		}, bindings)
+	})
+
+	t.Run("rejects unknown roles", func(t *testing.T) {
+		_, err := RBACConfig{Bindings: "alice=owner"}.RoleBindings()
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects entries without a subject", func(t *testing.T) {
+		_, err := RBACConfig{Bindings: "admin"}.RoleBindings()
+		assert.Error(t, err)
+	})
+}
+
+func TestServerConfig_TrustedProxyRanges(t *testing.T) {
+	ranges, err := ServerConfig{TrustedProxies: "10.0.0.0/8, 192.0.2.7,2001:db8::1"}.TrustedProxyRanges()
+
+	require.NoError(t, err)
+	require.Len(t, ranges, 3)
+	assert.Equal(t, "10.0.0.0/8", ranges[0].String())
+	assert.Equal(t, "192.0.2.7/32", ranges[1].String())
+	assert.Equal(t, "2001:db8::1/128", ranges[2].String())
+
+	for _, invalid := range []string{"proxy.internal", "10.0.0.0/33"} {
+		_, err := ServerConfig{TrustedProxies: invalid}.TrustedProxyRanges()
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestRateLimitConfig_KeyOverrides(t *testing.T) {
+	overrides, err := RateLimitConfig{Overrides: "apikey:ci=1200/300, user-1=60/10"}.KeyOverrides()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]RateLimitOverride{
+		"apikey:ci": {ReadPerMinute: 1200, WritePerMinute: 300},
+		"user-1":    {ReadPerMinute: 60, WritePerMinute: 10},
+	}, overrides)
+
+	for _, invalid := range []string{"apikey:ci", "apikey:ci=fast", "apikey:ci=0/10"} {
+		_, err := RateLimitConfig{Overrides: invalid}.KeyOverrides()
+		assert.Error(t, err, invalid)
+	}
+}
+
 func TestSensitiveValue(t *testing.T) {
 	password := SensitiveValue("secret")
 	assert.Equal(t, "[REDACTED]", password.String())
+
+	t.Run("redacted when logged", func(t *testing.T) {
+		var buf bytes.Buffer
+		logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+		logger.Info("connecting", "password", password, "database", DatabaseConfig{Password: password})
+
+		assert.NotContains(t, buf.String(), "secret")
+		assert.Contains(t, buf.String(), `"password":"[REDACTED]"`)
+	})
+
+	t.Run("redacted when encoded as JSON", func(t *testing.T) {
+		encoded, err := json.Marshal(APIKey{Name: "ci", Key: "secret"})
+		require.NoError(t, err)
+		assert.NotContains(t, string(encoded), "secret")
+	})
 }
 
 func TestMain(m *testing.M) {
@@ -0,0 +1,105 @@
+package domain
+
+import "sort"
+
+type Role string
+
+const (
+	RoleViewer Role = "viewer"
+	RoleMember Role = "member"
+	RoleAdmin  Role = "admin"
+)
+
+type Permission string
+
+const (
+	PermissionTaskRead   Permission = "task:read"
+	PermissionTaskCreate Permission = "task:create"
+	PermissionTaskUpdate Permission = "task:update"
+	PermissionTaskDelete Permission = "task:delete"
+	// PermissionTaskPurge allows permanently removing tasks from the trash
+	PermissionTaskPurge Permission = "task:purge"
+)
+
+// rolePermissions lists what each role may do. Roles are cumulative:
+// members can do everything viewers can, and admins everything members can.
+var rolePermissions = map[Role][]Permission{
+	RoleViewer: {PermissionTaskRead},
+	RoleMember: {PermissionTaskRead, PermissionTaskCreate, PermissionTaskUpdate},
+	RoleAdmin:  {PermissionTaskRead, PermissionTaskCreate, PermissionTaskUpdate, PermissionTaskDelete, PermissionTaskPurge},
+}
+
+// IsValid reports whether the role is one of the known roles
+func (r Role) IsValid() bool {
+	_, ok := rolePermissions[r]
+	return ok
+}
+
+// Permissions returns the permissions granted by the role
+func (r Role) Permissions() []Permission {
+	return rolePermissions[r]
+}
+
+// RoleBinding grants a role to a subject. A binding without a project
+// applies to every project.
+type RoleBinding struct {
+	Subject   string
+	Role      Role
+	ProjectID string
+}
+
+// AppliesTo reports whether the binding grants its role within the project
+func (b RoleBinding) AppliesTo(projectID string) bool {
+	return b.ProjectID == "" || b.ProjectID == projectID
+}
+
+// EffectivePermissions describes what a principal may do within a project
+type EffectivePermissions struct {
+	Subject     string       `json:"subject"`
+	ProjectID   string       `json:"project_id,omitempty"`
+	Roles       []Role       `json:"roles"`
+	Permissions []Permission `json:"permissions"`
+}
+
+// NewEffectivePermissions collapses the given roles into a sorted, de-duplicated
+// set of roles and permissions.
+func NewEffectivePermissions(subject, projectID string, roles []Role) EffectivePermissions {
+	roleSet := make(map[Role]bool)
+	permissionSet := make(map[Permission]bool)
+	for _, role := range roles {
+		if !role.IsValid() {
+			continue
+		}
+		roleSet[role] = true
+		for _, permission := range role.Permissions() {
+			permissionSet[permission] = true
+		}
+	}
+
+	effective := EffectivePermissions{
+		Subject:     subject,
+		ProjectID:   projectID,
+		Roles:       make([]Role, 0, len(roleSet)),
+		Permissions: make([]Permission, 0, len(permissionSet)),
+	}
+	for role := range roleSet {
+		effective.Roles = append(effective.Roles, role)
+	}
+	for permission := range permissionSet {
+		effective.Permissions = append(effective.Permissions, permission)
+	}
+	sort.Slice(effective.Roles, func(i, j int) bool { return effective.Roles[i] < effective.Roles[j] })
+	sort.Slice(effective.Permissions, func(i, j int) bool { return effective.Permissions[i] < effective.Permissions[j] })
+
+	return effective
+}
+
+// Has reports whether the permission is included
+func (e EffectivePermissions) Has(permission Permission) bool {
+	for _, p := range e.Permissions {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
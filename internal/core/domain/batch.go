@@ -0,0 +1,34 @@
+package domain
+
+// BatchAction is the kind of change a batch operation makes
+type BatchAction string
+
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+// BatchOperation is a single change within a batch
+type BatchOperation struct {
+	Action BatchAction
+	// ID and Version identify the task to update or delete. A zero Version
+	// skips the version check.
+	ID      string
+	Version int64
+	// Create holds the new task for create operations
+	Create CreateTaskInput
+	// Update derives the new state of the task from its current state for
+	// update operations
+	Update func(current *Task) (*Task, error)
+	// Err marks an operation the caller could not build, for example from
+	// invalid input. It fails with Err without running.
+	Err error
+}
+
+// BatchResult is the outcome of one batch operation. Task is the created
+// or updated task, and is nil for deletes and failures.
+type BatchResult struct {
+	Task *Task
+	Err  error
+}
@@ -0,0 +1,34 @@
+package domain
+
+import "context"
+
+// Principal identifies the authenticated caller of a request
+type Principal struct {
+	ID         string
+	AuthMethod string
+	Issuer     string
+	// TenantID is set when the credentials are bound to a single tenant
+	TenantID string
+	// Claims holds the raw token claims for principals authenticated by an
+	// identity provider. It is nil for API key callers.
+	Claims map[string]any
+}
+
+// StringClaim returns the named claim if it is present and a string
+func (p *Principal) StringClaim(name string) string {
+	value, _ := p.Claims[name].(string)
+	return value
+}
+
+type principalContextKey struct{}
+
+// ContextWithPrincipal returns a copy of ctx carrying the given principal
+func ContextWithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalContextKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal stored in ctx, if any
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalContextKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
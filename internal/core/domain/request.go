@@ -0,0 +1,29 @@
+package domain
+
+import (
+	"context"
+	"regexp"
+)
+
+var requestIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]{0,127}$`)
+
+// IsValidRequestID reports whether id is acceptable as a request ID. IDs
+// are copied into logs and SQL comments, so only a small alphabet is
+// allowed.
+func IsValidRequestID(id string) bool {
+	return requestIDPattern.MatchString(id)
+}
+
+type requestIDContextKey struct{}
+
+// ContextWithRequestID returns a copy of ctx carrying the ID of the request
+// it serves, so that logs, queries and outgoing calls can be correlated
+func ContextWithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDContextKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID carried by ctx, if any
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
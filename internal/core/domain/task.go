@@ -1,6 +1,14 @@
 package domain
 
-import "time"
+import (
+	"encoding/base64"
+	"errors"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
 
 type TaskStatus string
 
@@ -10,12 +18,140 @@ const (
 	StatusCompleted  TaskStatus = "completed"
 )
 
+// MaxTitleLength is the longest title, in characters, a task may have
+const MaxTitleLength = 255
+
+// IsValid reports whether s is one of the known task statuses
+func (s TaskStatus) IsValid() bool {
+	switch s {
+	case StatusPending, StatusInProgress, StatusCompleted:
+		return true
+	}
+	return false
+}
+
 type Task struct {
 	ID          string     `json:"id"`
+	TenantID    string     `json:"-"`
+	ProjectID   string     `json:"project_id,omitempty"`
 	Title       string     `json:"title"`
 	Description string     `json:"description"`
 	Status      TaskStatus `json:"status"`
 	CreatedAt   time.Time  `json:"created_at"`
 	UpdatedAt   time.Time  `json:"updated_at"`
 	DueDate     time.Time  `json:"due_date"`
+	// Version starts at 1 and increases with every update
+	Version int64 `json:"version"`
+	// DeletedAt is set while the task is in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// ArchivedAt is set while the task is archived. Archived tasks can be
+	// read but not changed.
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// TaskStats summarises the live tasks of every tenant
+type TaskStats struct {
+	ByStatus map[TaskStatus]int64
+	// Overdue counts tasks that are past their due date but not completed
+	Overdue int64
+}
+
+// TaskFilter narrows the tasks returned by a listing. Listings run from
+// the newest task to the oldest.
+type TaskFilter struct {
+	// IncludeArchived also returns archived tasks
+	IncludeArchived bool
+	// IDs, when not empty, only returns the tasks with these IDs
+	IDs []string
+	// ProjectIDs, when not empty, only returns tasks in one of these
+	// projects; an empty ID stands for tasks outside any project
+	ProjectIDs []string
+	// Statuses, when not empty, only returns tasks in one of these statuses
+	Statuses []TaskStatus
+	// DueBefore and DueAfter, when set, only return tasks due strictly
+	// before or after them
+	DueBefore *time.Time
+	DueAfter  *time.Time
+	// After, when set, skips tasks up to and including the one it points at
+	After *TaskCursor
+	// Limit caps the number of tasks returned; zero means no limit
+	Limit int
+	// LimitPerProject caps the number of tasks returned from each project,
+	// counting tasks outside any project as one; zero means no limit
+	LimitPerProject int
+}
+
+// Matches reports whether task passes the filter's conditions on task
+// fields. IncludeArchived, After and the limits are left to the listing.
+func (f TaskFilter) Matches(task *Task) bool {
+	switch {
+	case len(f.IDs) > 0 && !slices.Contains(f.IDs, task.ID):
+		return false
+	case len(f.ProjectIDs) > 0 && !slices.Contains(f.ProjectIDs, task.ProjectID):
+		return false
+	case len(f.Statuses) > 0 && !slices.Contains(f.Statuses, task.Status):
+		return false
+	case f.DueBefore != nil && !task.DueDate.Before(*f.DueBefore):
+		return false
+	case f.DueAfter != nil && !task.DueDate.After(*f.DueAfter):
+		return false
+	}
+	return true
+}
+
+// TaskCursor marks a position in a task listing
+type TaskCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+// Cursor returns the position of the task in a listing
+func (t *Task) Cursor() TaskCursor {
+	return TaskCursor{CreatedAt: t.CreatedAt, ID: t.ID}
+}
+
+// Precedes reports whether a task at c is listed before one at other
+func (c TaskCursor) Precedes(other TaskCursor) bool {
+	if !c.CreatedAt.Equal(other.CreatedAt) {
+		return c.CreatedAt.After(other.CreatedAt)
+	}
+	return c.ID > other.ID
+}
+
+// ErrInvalidPageToken is returned for page tokens that were not produced
+// by TaskCursor.Token
+var ErrInvalidPageToken = errors.New("invalid page token")
+
+// Token encodes the cursor as an opaque page token. Every API hands out
+// the same tokens, so a listing can be continued through any of them.
+func (c TaskCursor) Token() string {
+	return base64.RawURLEncoding.EncodeToString([]byte(c.CreatedAt.UTC().Format(time.RFC3339Nano) + " " + c.ID))
+}
+
+// ParseTaskCursor decodes a page token made by TaskCursor.Token
+func ParseTaskCursor(token string) (*TaskCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	createdAt, id, ok := strings.Cut(string(raw), " ")
+	if !ok {
+		return nil, ErrInvalidPageToken
+	}
+	cursor := TaskCursor{ID: id}
+	if cursor.CreatedAt, err = time.Parse(time.RFC3339Nano, createdAt); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	if _, err := uuid.Parse(id); err != nil {
+		return nil, ErrInvalidPageToken
+	}
+	return &cursor, nil
+}
+
+// CreateTaskInput holds the caller-supplied fields of a new task
+type CreateTaskInput struct {
+	ProjectID   string
+	Title       string
+	Description string
+	DueDate     time.Time
 }
@@ -0,0 +1,29 @@
+package domain
+
+// TaskEventType is the kind of change a TaskEvent reports
+type TaskEventType string
+
+const (
+	TaskCreated TaskEventType = "created"
+	TaskUpdated TaskEventType = "updated"
+	// TaskRemoved reports a task leaving the watched tasks: it was deleted,
+	// or archived while archived tasks are not watched
+	TaskRemoved TaskEventType = "removed"
+)
+
+// TaskEvent reports a change to a task. For removals, Task is the last
+// state the watcher saw.
+type TaskEvent struct {
+	Type TaskEventType
+	Task *Task
+}
+
+// TaskWatchOptions chooses what a watch reports
+type TaskWatchOptions struct {
+	// IncludeArchived also watches archived tasks, so that archiving is
+	// reported as an update rather than a removal
+	IncludeArchived bool
+	// IncludeExisting first reports every task that already exists as
+	// created, least recently changed first
+	IncludeExisting bool
+}
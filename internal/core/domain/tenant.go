@@ -0,0 +1,63 @@
+package domain
+
+import (
+	"context"
+	"errors"
+	"regexp"
+)
+
+// DefaultTenantID owns all data in single-tenant deployments and requests
+// that do not identify a tenant.
+const DefaultTenantID = "default"
+
+var tenantIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9_-]{0,62}$`)
+
+// IsValidTenantID reports whether id is an acceptable tenant identifier
+func IsValidTenantID(id string) bool {
+	return tenantIDPattern.MatchString(id)
+}
+
+var (
+	// ErrTenantMismatch is returned when credentials ask to act within a
+	// tenant they are not bound to
+	ErrTenantMismatch = errors.New("credentials are not valid for the requested tenant")
+
+	// ErrInvalidTenantID is returned when the requested tenant ID is malformed
+	ErrInvalidTenantID = errors.New("invalid tenant ID")
+)
+
+// ResolveTenant decides which tenant a request acts within. Credentials
+// bound to a tenant act within it and all others within the default
+// tenant, so that no caller can reach another tenant's data by naming it.
+// The requested tenant, if any, must be that one. principal may be nil.
+func ResolveTenant(principal *Principal, requested string) (string, error) {
+	tenantID := DefaultTenantID
+	if principal != nil && principal.TenantID != "" {
+		tenantID = principal.TenantID
+	}
+
+	switch {
+	case requested == "" || requested == tenantID:
+		return tenantID, nil
+	case !IsValidTenantID(requested):
+		return "", ErrInvalidTenantID
+	default:
+		return "", ErrTenantMismatch
+	}
+}
+
+type tenantContextKey struct{}
+
+// ContextWithTenant returns a copy of ctx scoped to the given tenant
+func ContextWithTenant(ctx context.Context, tenantID string) context.Context {
+	return context.WithValue(ctx, tenantContextKey{}, tenantID)
+}
+
+// TenantFromContext returns the tenant that ctx is scoped to, falling back
+// to DefaultTenantID.
+func TenantFromContext(ctx context.Context) string {
+	if tenantID, ok := ctx.Value(tenantContextKey{}).(string); ok && tenantID != "" {
+		return tenantID
+	}
+	return DefaultTenantID
+}
@@ -0,0 +1,37 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// IdempotentResponse is a response stored so it can be replayed to a client
+// that repeats a request with the same idempotency key.
+type IdempotentResponse struct {
+	StatusCode int
+	Header     map[string][]string
+	Body       []byte
+}
+
+// IdempotencyRecord is the state of an idempotency key that is already in use
+type IdempotencyRecord struct {
+	// Fingerprint identifies the request that first used the key
+	Fingerprint string
+	// Response is nil while that request is still in flight
+	Response *IdempotentResponse
+}
+
+// IdempotencyStore remembers the outcome of requests by idempotency key.
+// Keys expire after the TTL they were last given, after which they can be
+// claimed again.
+type IdempotencyStore interface {
+	// Begin claims key for the request identified by fingerprint, holding it
+	// for ttl. It returns nil if the key was claimed, or the existing record
+	// if the key is already in use.
+	Begin(ctx context.Context, key, fingerprint string, ttl time.Duration) (*IdempotencyRecord, error)
+	// Complete stores the response for a claimed key and keeps it for ttl
+	Complete(ctx context.Context, key string, response IdempotentResponse, ttl time.Duration) error
+	// Release gives up a claimed key that has no response, so that the
+	// request can be retried.
+	Release(ctx context.Context, key string) error
+}
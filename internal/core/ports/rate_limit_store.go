@@ -0,0 +1,115 @@
+package ports
+
+import (
+	"context"
+	"time"
+)
+
+// RateLimit describes a token bucket: it holds at most Burst tokens and
+// refills at Rate tokens per second.
+type RateLimit struct {
+	Rate  float64
+	Burst int
+}
+
+// RateLimitResult reports the outcome of taking a token from a bucket
+type RateLimitResult struct {
+	Allowed   bool
+	Remaining int
+	// ResetAfter is how long until the bucket is full again
+	ResetAfter time.Duration
+	// RetryAfter is how long until a token is available when not allowed
+	RetryAfter time.Duration
+}
+
+// ResetSeconds is ResetAfter in whole seconds, rounded up, as transports
+// report it
+func (r RateLimitResult) ResetSeconds() int {
+	return ceilSeconds(r.ResetAfter)
+}
+
+// RetryAfterSeconds is RetryAfter in whole seconds, rounded up so that a
+// caller waiting that long finds a token
+func (r RateLimitResult) RetryAfterSeconds() int {
+	return ceilSeconds(r.RetryAfter)
+}
+
+func ceilSeconds(d time.Duration) int {
+	return int((d + time.Second - 1) / time.Second)
+}
+
+// RateLimits holds the limits applied to each class of request
+type RateLimits struct {
+	Read  RateLimit
+	Write RateLimit
+}
+
+// RateLimitPolicy decides which limits apply to a caller. Every transport
+// applies the same policy, so a caller's budget does not depend on how it
+// reaches the service.
+type RateLimitPolicy struct {
+	Default RateLimits
+	// PerKey overrides the default limits for specific principals
+	PerKey map[string]RateLimits
+}
+
+// LimitsFor returns the limits of the principal with the given ID, or the
+// default limits for anonymous callers
+func (p RateLimitPolicy) LimitsFor(principalID string) RateLimits {
+	if override, ok := p.PerKey[principalID]; ok {
+		return override
+	}
+	return p.Default
+}
+
+// Ceiling returns the most generous limits the policy grants any caller
+func (p RateLimitPolicy) Ceiling() RateLimits {
+	ceiling := p.Default
+	for _, override := range p.PerKey {
+		ceiling.Read = ceiling.Read.atLeast(override.Read)
+		ceiling.Write = ceiling.Write.atLeast(override.Write)
+	}
+	return ceiling
+}
+
+func (l RateLimit) atLeast(other RateLimit) RateLimit {
+	return RateLimit{Rate: max(l.Rate, other.Rate), Burst: max(l.Burst, other.Burst)}
+}
+
+// PerMinute returns a limit allowing n requests per minute, all of which
+// may be spent in a burst.
+func PerMinute(n int) RateLimit {
+	return RateLimit{Rate: float64(n) / 60, Burst: n}
+}
+
+// RateLimitStore keeps token buckets so that limits can be shared between
+// requests, and between replicas when the store is shared.
+type RateLimitStore interface {
+	Take(ctx context.Context, key string, limit RateLimit) (RateLimitResult, error)
+}
+
+// Take refills a bucket currently holding tokens, last updated elapsed ago,
+// and tries to remove one token from it. It returns the new token count.
+// Stores use it so that every backend applies the same arithmetic.
+func (l RateLimit) Take(tokens float64, elapsed time.Duration) (float64, RateLimitResult) {
+	burst := float64(l.Burst)
+	if elapsed > 0 {
+		tokens += elapsed.Seconds() * l.Rate
+	}
+	if tokens > burst {
+		tokens = burst
+	}
+
+	result := RateLimitResult{Allowed: tokens >= 1}
+	if result.Allowed {
+		tokens--
+	} else if l.Rate > 0 {
+		result.RetryAfter = time.Duration((1 - tokens) / l.Rate * float64(time.Second))
+	}
+
+	result.Remaining = int(tokens)
+	if l.Rate > 0 {
+		result.ResetAfter = time.Duration((burst - tokens) / l.Rate * float64(time.Second))
+	}
+	return tokens, result
+}
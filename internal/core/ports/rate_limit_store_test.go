@@ -0,0 +1,26 @@
+package ports
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRateLimitResult_Seconds(t *testing.T) {
+	tests := []struct {
+		after    time.Duration
+		expected int
+	}{
+		{after: 0, expected: 0},
+		{after: time.Nanosecond, expected: 1},
+		{after: time.Second, expected: 1},
+		{after: 1500 * time.Millisecond, expected: 2},
+	}
+
+	for _, tt := range tests {
+		result := RateLimitResult{ResetAfter: tt.after, RetryAfter: tt.after}
+		assert.Equal(t, tt.expected, result.ResetSeconds(), tt.after)
+		assert.Equal(t, tt.expected, result.RetryAfterSeconds(), tt.after)
+	}
+}
@@ -3,12 +3,47 @@ package ports
 import (
 	"context"
 	"task-tracking-service/internal/core/domain"
+	"time"
 )
 
+// TaskRepository stores tasks. Update and Delete only succeed while the
+// stored task is still at the given version, and fail with
+// errors.ErrTaskVersionConflict otherwise; Update bumps task.Version.
+//
+// Delete moves a task to the trash rather than removing it. GetByID, List
+// and Update ignore tasks in the trash, which can be restored until they
+// are purged.
+//
+// Completed tasks are eventually archived into cold storage. Only
+// GetArchivedByID and List with IncludeArchived see them until they are
+// unarchived.
+//
+// Repositories that can group writes also implement Transactor. Within a
+// transaction, GetByID locks the task until the transaction ends.
 type TaskRepository interface {
 	Create(ctx context.Context, task *domain.Task) error
 	GetByID(ctx context.Context, id string) (*domain.Task, error)
-	List(ctx context.Context) ([]*domain.Task, error)
+	List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error)
 	Update(ctx context.Context, task *domain.Task) error
-	Delete(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string, version int64) error
+
+	// GetDeletedByID and ListDeleted read tasks in the trash
+	GetDeletedByID(ctx context.Context, id string) (*domain.Task, error)
+	ListDeleted(ctx context.Context) ([]*domain.Task, error)
+	// Restore takes a task out of the trash and bumps its version
+	Restore(ctx context.Context, id string) (*domain.Task, error)
+	// Purge permanently removes a task from the trash
+	Purge(ctx context.Context, id string) error
+	// PurgeDeletedBefore permanently removes tasks of every tenant that were
+	// deleted before cutoff, and returns how many it removed
+	PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+
+	GetArchivedByID(ctx context.Context, id string) (*domain.Task, error)
+	// ArchiveCompletedBefore archives tasks of every tenant that were
+	// completed, and have not changed, since before cutoff. It returns how
+	// many it archived.
+	ArchiveCompletedBefore(ctx context.Context, cutoff time.Time) (int64, error)
+	// Unarchive moves an archived task back into the live tasks and bumps
+	// its version
+	Unarchive(ctx context.Context, id string) (*domain.Task, error)
 }
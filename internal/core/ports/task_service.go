@@ -0,0 +1,35 @@
+package ports
+
+import (
+	"context"
+	"task-tracking-service/internal/core/domain"
+)
+
+// TaskService is the application's task use cases as seen by driving
+// adapters such as the HTTP handlers.
+type TaskService interface {
+	CreateTask(ctx context.Context, input domain.CreateTaskInput) (*domain.Task, error)
+	// GetTask returns a live or archived task
+	GetTask(ctx context.Context, id string) (*domain.Task, error)
+	ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error)
+	// UpdateTask replaces a task. A non-zero task.Version must match the
+	// stored version.
+	UpdateTask(ctx context.Context, task *domain.Task) (*domain.Task, error)
+	// DeleteTask moves a task to the trash. A non-zero version must match
+	// the stored version.
+	DeleteTask(ctx context.Context, id string, version int64) error
+	GetDeletedTask(ctx context.Context, id string) (*domain.Task, error)
+	ListDeletedTasks(ctx context.Context) ([]*domain.Task, error)
+	// RestoreTask takes a task out of the trash
+	RestoreTask(ctx context.Context, id string) (*domain.Task, error)
+	// PurgeTask permanently removes a task from the trash
+	PurgeTask(ctx context.Context, id string) error
+	// UnarchiveTask makes an archived task editable again
+	UnarchiveTask(ctx context.Context, id string) (*domain.Task, error)
+}
+
+// TaskBatchService applies many task changes in one call. In atomic mode
+// either every operation is applied or none is.
+type TaskBatchService interface {
+	ExecuteBatch(ctx context.Context, operations []domain.BatchOperation, atomic bool) ([]domain.BatchResult, error)
+}
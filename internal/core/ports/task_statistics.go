@@ -0,0 +1,14 @@
+package ports
+
+import (
+	"context"
+	"task-tracking-service/internal/core/domain"
+	"time"
+)
+
+// TaskStatistics is implemented by repositories that can summarise the
+// tasks of every tenant, for monitoring
+type TaskStatistics interface {
+	// TaskStats counts live tasks, treating those due before now as overdue
+	TaskStats(ctx context.Context, now time.Time) (domain.TaskStats, error)
+}
@@ -0,0 +1,12 @@
+package ports
+
+import "context"
+
+// Transactor is implemented by repositories that can group several calls
+// into a unit of work, so that its writes all apply or none do. Repository
+// calls made with the context passed to fn take part in the transaction,
+// which commits if fn returns nil and rolls back otherwise. Nested calls
+// join the transaction already in progress.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
@@ -0,0 +1,315 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/pkg/errors"
+)
+
+// rolesClaim is the token claim through which an identity provider can
+// grant global roles.
+const rolesClaim = "roles"
+
+// Policy resolves the roles, and therefore permissions, of a principal
+type Policy struct {
+	bindings    []domain.RoleBinding
+	defaultRole domain.Role
+}
+
+// NewPolicy creates a policy from static role bindings. The default role,
+// if set, is granted to every authenticated principal.
+func NewPolicy(bindings []domain.RoleBinding, defaultRole domain.Role) *Policy {
+	return &Policy{
+		bindings:    bindings,
+		defaultRole: defaultRole,
+	}
+}
+
+// EffectivePermissions returns what the principal may do within a project.
+// An empty project ID only considers global roles.
+func (p *Policy) EffectivePermissions(principal *domain.Principal, projectID string) domain.EffectivePermissions {
+	var roles []domain.Role
+	if p.defaultRole != "" {
+		roles = append(roles, p.defaultRole)
+	}
+
+	for _, binding := range p.bindings {
+		if binding.Subject == principal.ID && binding.AppliesTo(projectID) {
+			roles = append(roles, binding.Role)
+		}
+	}
+
+	if claimed, ok := principal.Claims[rolesClaim].([]any); ok {
+		for _, role := range claimed {
+			if name, ok := role.(string); ok {
+				roles = append(roles, domain.Role(name))
+			}
+		}
+	}
+
+	return domain.NewEffectivePermissions(principal.ID, projectID, roles)
+}
+
+// allowedAnywhere reports whether the principal holds the permission
+// globally or in at least one project.
+func (p *Policy) allowedAnywhere(principal *domain.Principal, permission domain.Permission) bool {
+	if p.EffectivePermissions(principal, "").Has(permission) {
+		return true
+	}
+
+	for _, binding := range p.bindings {
+		if binding.Subject == principal.ID && binding.ProjectID != "" &&
+			p.EffectivePermissions(principal, binding.ProjectID).Has(permission) {
+			return true
+		}
+	}
+	return false
+}
+
+// readableProjects returns the projects in which the principal may read
+// tasks. restricted is false when they may read tasks in every project.
+func (p *Policy) readableProjects(principal *domain.Principal) (projectIDs []string, restricted bool) {
+	if p.EffectivePermissions(principal, "").Has(domain.PermissionTaskRead) {
+		return nil, false
+	}
+
+	for _, binding := range p.bindings {
+		if binding.Subject == principal.ID && binding.ProjectID != "" &&
+			!slices.Contains(projectIDs, binding.ProjectID) &&
+			p.EffectivePermissions(principal, binding.ProjectID).Has(domain.PermissionTaskRead) {
+			projectIDs = append(projectIDs, binding.ProjectID)
+		}
+	}
+	return projectIDs, true
+}
+
+// AuthorizedTaskService enforces the policy in front of another TaskService
+type AuthorizedTaskService struct {
+	next   ports.TaskService
+	policy *Policy
+	logger *slog.Logger
+}
+
+var _ ports.TaskService = (*AuthorizedTaskService)(nil)
+
+func NewAuthorizedTaskService(next ports.TaskService, policy *Policy, logger *slog.Logger) *AuthorizedTaskService {
+	return &AuthorizedTaskService{
+		next:   next,
+		policy: policy,
+		logger: logger,
+	}
+}
+
+func (s *AuthorizedTaskService) CreateTask(ctx context.Context, input domain.CreateTaskInput) (*domain.Task, error) {
+	if err := s.authorize(ctx, domain.PermissionTaskCreate, input.ProjectID); err != nil {
+		return nil, err
+	}
+	return s.next.CreateTask(ctx, input)
+}
+
+func (s *AuthorizedTaskService) GetTask(ctx context.Context, id string) (*domain.Task, error) {
+	task, err := s.next.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(ctx, domain.PermissionTaskRead, task.ProjectID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// ListTasks returns only the tasks in projects the caller may read. The
+// restriction travels down with the filter, so that pages are cut after it
+// rather than coming back short.
+func (s *AuthorizedTaskService) ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	filter, ok, err := s.ScopeFilter(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return []*domain.Task{}, nil
+	}
+	return s.next.ListTasks(ctx, filter)
+}
+
+// ScopeFilter narrows filter to the projects the caller may read. ok is
+// false when the caller may read none of the projects filter asks for.
+func (s *AuthorizedTaskService) ScopeFilter(ctx context.Context, filter domain.TaskFilter) (scoped domain.TaskFilter, ok bool, err error) {
+	principal, err := s.reader(ctx)
+	if err != nil {
+		return filter, false, err
+	}
+
+	if readable, restricted := s.policy.readableProjects(principal); restricted {
+		if len(filter.ProjectIDs) == 0 {
+			filter.ProjectIDs = readable
+		} else {
+			filter.ProjectIDs = slices.DeleteFunc(slices.Clone(filter.ProjectIDs), func(projectID string) bool {
+				return !slices.Contains(readable, projectID)
+			})
+			if len(filter.ProjectIDs) == 0 {
+				return filter, false, nil
+			}
+		}
+	}
+	return filter, true, nil
+}
+
+func (s *AuthorizedTaskService) UpdateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
+	existing, err := s.GetTask(ctx, task.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(ctx, domain.PermissionTaskUpdate, existing.ProjectID); err != nil {
+		return nil, err
+	}
+	// Moving a task also requires permission in the destination project
+	if task.ProjectID != existing.ProjectID {
+		if err := s.authorize(ctx, domain.PermissionTaskUpdate, task.ProjectID); err != nil {
+			return nil, err
+		}
+	}
+
+	return s.next.UpdateTask(ctx, task)
+}
+
+func (s *AuthorizedTaskService) DeleteTask(ctx context.Context, id string, version int64) error {
+	existing, err := s.GetTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorize(ctx, domain.PermissionTaskDelete, existing.ProjectID); err != nil {
+		return err
+	}
+	return s.next.DeleteTask(ctx, id, version)
+}
+
+func (s *AuthorizedTaskService) GetDeletedTask(ctx context.Context, id string) (*domain.Task, error) {
+	task, err := s.next.GetDeletedTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(ctx, domain.PermissionTaskRead, task.ProjectID); err != nil {
+		return nil, err
+	}
+	return task, nil
+}
+
+// ListDeletedTasks returns only the trashed tasks in projects the caller may read
+func (s *AuthorizedTaskService) ListDeletedTasks(ctx context.Context) ([]*domain.Task, error) {
+	return s.listReadable(ctx, s.next.ListDeletedTasks)
+}
+
+// RestoreTask requires the permission that deleting the task did
+func (s *AuthorizedTaskService) RestoreTask(ctx context.Context, id string) (*domain.Task, error) {
+	existing, err := s.GetDeletedTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(ctx, domain.PermissionTaskDelete, existing.ProjectID); err != nil {
+		return nil, err
+	}
+	return s.next.RestoreTask(ctx, id)
+}
+
+func (s *AuthorizedTaskService) PurgeTask(ctx context.Context, id string) error {
+	existing, err := s.GetDeletedTask(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authorize(ctx, domain.PermissionTaskPurge, existing.ProjectID); err != nil {
+		return err
+	}
+	return s.next.PurgeTask(ctx, id)
+}
+
+// UnarchiveTask requires the permission to update the task
+func (s *AuthorizedTaskService) UnarchiveTask(ctx context.Context, id string) (*domain.Task, error) {
+	existing, err := s.GetTask(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authorize(ctx, domain.PermissionTaskUpdate, existing.ProjectID); err != nil {
+		return nil, err
+	}
+	return s.next.UnarchiveTask(ctx, id)
+}
+
+// reader returns the caller if they may read tasks in at least one project
+func (s *AuthorizedTaskService) reader(ctx context.Context) (*domain.Principal, error) {
+	principal, err := s.principal(ctx, domain.PermissionTaskRead, "")
+	if err != nil {
+		return nil, err
+	}
+	if !s.policy.allowedAnywhere(principal, domain.PermissionTaskRead) {
+		return nil, s.deny(ctx, principal, domain.PermissionTaskRead, "")
+	}
+	return principal, nil
+}
+
+// listReadable calls list and keeps only the tasks in projects the caller
+// may read
+func (s *AuthorizedTaskService) listReadable(ctx context.Context, list func(context.Context) ([]*domain.Task, error)) ([]*domain.Task, error) {
+	principal, err := s.reader(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	tasks, err := list(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	readable := make(map[string]bool)
+	visible := make([]*domain.Task, 0, len(tasks))
+	for _, task := range tasks {
+		allowed, checked := readable[task.ProjectID]
+		if !checked {
+			allowed = s.policy.EffectivePermissions(principal, task.ProjectID).Has(domain.PermissionTaskRead)
+			readable[task.ProjectID] = allowed
+		}
+		if allowed {
+			visible = append(visible, task)
+		}
+	}
+
+	return visible, nil
+}
+
+func (s *AuthorizedTaskService) authorize(ctx context.Context, permission domain.Permission, projectID string) error {
+	principal, err := s.principal(ctx, permission, projectID)
+	if err != nil {
+		return err
+	}
+
+	if !s.policy.EffectivePermissions(principal, projectID).Has(permission) {
+		return s.deny(ctx, principal, permission, projectID)
+	}
+	return nil
+}
+
+func (s *AuthorizedTaskService) principal(ctx context.Context, permission domain.Permission, projectID string) (*domain.Principal, error) {
+	principal, ok := domain.PrincipalFromContext(ctx)
+	if !ok {
+		return nil, s.deny(ctx, &domain.Principal{ID: "anonymous"}, permission, projectID)
+	}
+	return principal, nil
+}
+
+// deny records the denial in the audit log and returns the error to surface
+func (s *AuthorizedTaskService) deny(ctx context.Context, principal *domain.Principal, permission domain.Permission, projectID string) error {
+	s.logger.WarnContext(ctx, "permission denied", "audit", true,
+		"permission", permission, "principal", principal.ID, "project_id", projectID)
+	return errors.NewForbiddenError(fmt.Sprintf("permission %s denied", permission))
+}
@@ -0,0 +1,155 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func asPrincipal(id string, claims map[string]any) context.Context {
+	return domain.ContextWithPrincipal(context.Background(), &domain.Principal{ID: id, Claims: claims})
+}
+
+func newAuthorizedService(bindings ...domain.RoleBinding) (*AuthorizedTaskService, *TaskService) {
+	inner := NewTaskService(memory.NewTaskRepository(), slog.Default())
+	return NewAuthorizedTaskService(inner, NewPolicy(bindings, ""), slog.Default()), inner
+}
+
+func TestPolicy_EffectivePermissions(t *testing.T) {
+	policy := NewPolicy([]domain.RoleBinding{
+		{Subject: "alice", Role: domain.RoleViewer},
+		{Subject: "alice", Role: domain.RoleAdmin, ProjectID: "proj-1"},
+	}, "")
+
+	t.Run("global bindings", func(t *testing.T) {
+		effective := policy.EffectivePermissions(&domain.Principal{ID: "alice"}, "")
+
+		assert.Equal(t, []domain.Role{domain.RoleViewer}, effective.Roles)
+		assert.Equal(t, []domain.Permission{domain.PermissionTaskRead}, effective.Permissions)
+	})
+
+	t.Run("project bindings add to global bindings", func(t *testing.T) {
+		effective := policy.EffectivePermissions(&domain.Principal{ID: "alice"}, "proj-1")
+
+		assert.Equal(t, []domain.Role{domain.RoleAdmin, domain.RoleViewer}, effective.Roles)
+		assert.True(t, effective.Has(domain.PermissionTaskDelete))
+	})
+
+	t.Run("roles claim grants global roles", func(t *testing.T) {
+		principal := &domain.Principal{ID: "bob", Claims: map[string]any{"roles": []any{"member", "unknown"}}}
+
+		effective := policy.EffectivePermissions(principal, "")
+
+		assert.Equal(t, []domain.Role{domain.RoleMember}, effective.Roles)
+		assert.True(t, effective.Has(domain.PermissionTaskCreate))
+		assert.False(t, effective.Has(domain.PermissionTaskDelete))
+	})
+
+	t.Run("default role applies to everyone", func(t *testing.T) {
+		effective := NewPolicy(nil, domain.RoleViewer).EffectivePermissions(&domain.Principal{ID: "carol"}, "")
+
+		assert.True(t, effective.Has(domain.PermissionTaskRead))
+	})
+}
+
+func TestAuthorizedTaskService(t *testing.T) {
+	dueDate := time.Now().Add(24 * time.Hour)
+
+	t.Run("viewers can read but not create", func(t *testing.T) {
+		service, inner := newAuthorizedService(domain.RoleBinding{Subject: "viewer", Role: domain.RoleViewer})
+		existing, err := inner.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Existing", DueDate: dueDate})
+		require.NoError(t, err)
+		ctx := asPrincipal("viewer", nil)
+
+		task, err := service.GetTask(ctx, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, existing.ID, task.ID)
+
+		_, err = service.CreateTask(ctx, domain.CreateTaskInput{Title: "New", DueDate: dueDate})
+		assert.True(t, errors.IsForbiddenError(err))
+	})
+
+	t.Run("members cannot delete", func(t *testing.T) {
+		service, _ := newAuthorizedService(domain.RoleBinding{Subject: "member", Role: domain.RoleMember})
+		ctx := asPrincipal("member", nil)
+
+		task, err := service.CreateTask(ctx, domain.CreateTaskInput{Title: "Task", DueDate: dueDate})
+		require.NoError(t, err)
+
+		err = service.DeleteTask(ctx, task.ID, 0)
+		assert.True(t, errors.IsForbiddenError(err))
+	})
+
+	t.Run("project bindings only apply inside the project", func(t *testing.T) {
+		service, _ := newAuthorizedService(domain.RoleBinding{Subject: "alice", Role: domain.RoleAdmin, ProjectID: "proj-1"})
+		ctx := asPrincipal("alice", nil)
+
+		inProject, err := service.CreateTask(ctx, domain.CreateTaskInput{ProjectID: "proj-1", Title: "Mine", DueDate: dueDate})
+		require.NoError(t, err)
+
+		_, err = service.CreateTask(ctx, domain.CreateTaskInput{ProjectID: "proj-2", Title: "Theirs", DueDate: dueDate})
+		assert.True(t, errors.IsForbiddenError(err))
+
+		// Moving the task out of the project needs permission in the destination
+		moved := *inProject
+		moved.ProjectID = "proj-2"
+		_, err = service.UpdateTask(ctx, &moved)
+		assert.True(t, errors.IsForbiddenError(err))
+
+		assert.NoError(t, service.DeleteTask(ctx, inProject.ID, 0))
+	})
+
+	t.Run("listing only returns readable projects", func(t *testing.T) {
+		service, inner := newAuthorizedService(domain.RoleBinding{Subject: "alice", Role: domain.RoleViewer, ProjectID: "proj-1"})
+		for _, project := range []string{"proj-1", "proj-2", ""} {
+			_, err := inner.CreateTask(context.Background(), domain.CreateTaskInput{ProjectID: project, Title: "Task in " + project, DueDate: dueDate})
+			require.NoError(t, err)
+		}
+
+		tasks, err := service.ListTasks(asPrincipal("alice", nil), domain.TaskFilter{})
+
+		require.NoError(t, err)
+		require.Len(t, tasks, 1)
+		assert.Equal(t, "proj-1", tasks[0].ProjectID)
+	})
+
+	t.Run("callers without any role are denied", func(t *testing.T) {
+		service, _ := newAuthorizedService()
+
+		_, err := service.ListTasks(asPrincipal("stranger", nil), domain.TaskFilter{})
+		assert.True(t, errors.IsForbiddenError(err))
+
+		_, err = service.ListTasks(context.Background(), domain.TaskFilter{})
+		assert.True(t, errors.IsForbiddenError(err))
+	})
+	t.Run("only admins can restore or purge deleted tasks", func(t *testing.T) {
+		service, inner := newAuthorizedService(
+			domain.RoleBinding{Subject: "member", Role: domain.RoleMember},
+			domain.RoleBinding{Subject: "admin", Role: domain.RoleAdmin},
+		)
+		task, err := inner.CreateTask(context.Background(), domain.CreateTaskInput{Title: "Deleted", DueDate: dueDate})
+		require.NoError(t, err)
+		require.NoError(t, inner.DeleteTask(context.Background(), task.ID, 0))
+		member, admin := asPrincipal("member", nil), asPrincipal("admin", nil)
+
+		trash, err := service.ListDeletedTasks(member)
+		require.NoError(t, err)
+		assert.Len(t, trash, 1)
+
+		_, err = service.RestoreTask(member, task.ID)
+		assert.True(t, errors.IsForbiddenError(err))
+		assert.True(t, errors.IsForbiddenError(service.PurgeTask(member, task.ID)))
+
+		assert.NoError(t, service.PurgeTask(admin, task.ID))
+		_, err = service.GetDeletedTask(admin, task.ID)
+		assert.True(t, errors.IsNotFoundError(err))
+	})
+}
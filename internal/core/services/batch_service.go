@@ -0,0 +1,154 @@
+package services
+
+import (
+	"context"
+	stderrors "errors"
+	"fmt"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/pkg/errors"
+)
+
+// ErrOperationRolledBack is the result of operations in an atomic batch
+// that were undone, or never run, because another operation failed.
+var ErrOperationRolledBack = stderrors.New("not applied because another operation in the batch failed")
+
+// errBatchFailed aborts the transaction of an atomic batch
+var errBatchFailed = stderrors.New("batch operation failed")
+
+// BatchService runs batches of task operations through a TaskService, so
+// that each operation is authorized and validated exactly like a single
+// request, including status transition rules.
+type BatchService struct {
+	tasks      ports.TaskService
+	transactor ports.Transactor
+	maxSize    int
+}
+
+var _ ports.TaskBatchService = (*BatchService)(nil)
+
+// NewBatchService creates a batch service. Atomic batches need a
+// transactor; without one only best-effort batches are accepted.
+func NewBatchService(tasks ports.TaskService, transactor ports.Transactor, maxSize int) *BatchService {
+	return &BatchService{
+		tasks:      tasks,
+		transactor: transactor,
+		maxSize:    maxSize,
+	}
+}
+
+// ExecuteBatch runs the operations in order and reports a result for each.
+// Best-effort batches run every operation independently. Atomic batches
+// stop at the first failure and roll back everything before it.
+func (s *BatchService) ExecuteBatch(ctx context.Context, operations []domain.BatchOperation, atomic bool) ([]domain.BatchResult, error) {
+	switch {
+	case len(operations) == 0:
+		return nil, errors.NewValidationError("invalid batch", errors.FieldError{Field: "operations", Message: "must not be empty"})
+	case len(operations) > s.maxSize:
+		return nil, errors.NewValidationError("invalid batch", errors.FieldError{
+			Field:   "operations",
+			Message: fmt.Sprintf("must contain at most %d operations", s.maxSize),
+		})
+	case atomic && s.transactor == nil:
+		return nil, errors.NewValidationError("invalid batch", errors.FieldError{
+			Field:   "mode",
+			Message: "atomic batches are not supported by this repository",
+		})
+	}
+
+	results := make([]domain.BatchResult, len(operations))
+	if atomic && anyInvalid(operations) {
+		for i, operation := range operations {
+			results[i].Err = operation.Err
+			if results[i].Err == nil {
+				results[i].Err = ErrOperationRolledBack
+			}
+		}
+		return results, nil
+	}
+
+	if !atomic {
+		for i, operation := range operations {
+			results[i] = s.execute(ctx, operation)
+		}
+		return results, nil
+	}
+
+	err := s.transactor.WithinTx(ctx, func(ctx context.Context) error {
+		for i, operation := range operations {
+			results[i] = s.execute(ctx, operation)
+			if results[i].Err != nil {
+				return errBatchFailed
+			}
+		}
+		return nil
+	})
+	if err == nil {
+		return results, nil
+	}
+	if !stderrors.Is(err, errBatchFailed) {
+		return nil, err
+	}
+
+	for i := range results {
+		if results[i].Err == nil {
+			results[i] = domain.BatchResult{Err: ErrOperationRolledBack}
+		}
+	}
+	return results, nil
+}
+
+func (s *BatchService) execute(ctx context.Context, operation domain.BatchOperation) domain.BatchResult {
+	if operation.Err != nil {
+		return domain.BatchResult{Err: operation.Err}
+	}
+
+	switch operation.Action {
+	case domain.BatchCreate:
+		task, err := s.tasks.CreateTask(ctx, operation.Create)
+		return domain.BatchResult{Task: task, Err: err}
+
+	case domain.BatchUpdate:
+		task, err := s.update(ctx, operation)
+		return domain.BatchResult{Task: task, Err: err}
+
+	case domain.BatchDelete:
+		return domain.BatchResult{Err: s.tasks.DeleteTask(ctx, operation.ID, operation.Version)}
+
+	default:
+		return domain.BatchResult{Err: errors.NewValidationError("invalid operation", errors.FieldError{
+			Field:   "op",
+			Message: fmt.Sprintf("must be one of %s, %s, %s", domain.BatchCreate, domain.BatchUpdate, domain.BatchDelete),
+		})}
+	}
+}
+
+// update applies the operation to the task as it is now, so that several
+// updates to the same task within one batch build on each other.
+func (s *BatchService) update(ctx context.Context, operation domain.BatchOperation) (*domain.Task, error) {
+	current, err := s.tasks.GetTask(ctx, operation.ID)
+	if err != nil {
+		return nil, err
+	}
+	if operation.Version != 0 && operation.Version != current.Version {
+		return nil, errors.ErrTaskVersionMismatch
+	}
+
+	updated, err := operation.Update(current)
+	if err != nil {
+		return nil, err
+	}
+	updated.ID = current.ID
+	updated.Version = current.Version
+	return s.tasks.UpdateTask(ctx, updated)
+}
+
+func anyInvalid(operations []domain.BatchOperation) bool {
+	for _, operation := range operations {
+		if operation.Err != nil {
+			return true
+		}
+	}
+	return false
+}
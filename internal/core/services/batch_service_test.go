@@ -0,0 +1,142 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newBatchService(t *testing.T, maxSize int) (*BatchService, *TaskService) {
+	t.Helper()
+	repo := memory.NewTaskRepository()
+	tasks := NewTaskService(repo, slog.Default())
+	return NewBatchService(tasks, repo, maxSize), tasks
+}
+
+func setStatus(status domain.TaskStatus) func(*domain.Task) (*domain.Task, error) {
+	return func(current *domain.Task) (*domain.Task, error) {
+		updated := *current
+		updated.Status = status
+		return &updated, nil
+	}
+}
+
+func TestBatchService_ExecuteBatch(t *testing.T) {
+	ctx := context.Background()
+	dueDate := time.Now().Add(24 * time.Hour)
+
+	t.Run("atomic batch applies every operation", func(t *testing.T) {
+		batch, tasks := newBatchService(t, 10)
+		existing, err := tasks.CreateTask(ctx, domain.CreateTaskInput{Title: "Existing", DueDate: dueDate})
+		require.NoError(t, err)
+		doomed, err := tasks.CreateTask(ctx, domain.CreateTaskInput{Title: "Doomed", DueDate: dueDate})
+		require.NoError(t, err)
+
+		results, err := batch.ExecuteBatch(ctx, []domain.BatchOperation{
+			{Action: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: dueDate}},
+			{Action: domain.BatchUpdate, ID: existing.ID, Update: setStatus(domain.StatusInProgress)},
+			{Action: domain.BatchUpdate, ID: existing.ID, Update: setStatus(domain.StatusCompleted)},
+			{Action: domain.BatchDelete, ID: doomed.ID, Version: doomed.Version},
+		}, true)
+
+		require.NoError(t, err)
+		for _, result := range results {
+			assert.NoError(t, result.Err)
+		}
+		assert.Equal(t, domain.StatusCompleted, results[2].Task.Status)
+		assert.Equal(t, int64(3), results[2].Task.Version)
+
+		all, err := tasks.ListTasks(ctx, domain.TaskFilter{})
+		require.NoError(t, err)
+		assert.Len(t, all, 2)
+	})
+
+	t.Run("atomic batch rolls back when an operation fails", func(t *testing.T) {
+		batch, tasks := newBatchService(t, 10)
+		existing, err := tasks.CreateTask(ctx, domain.CreateTaskInput{Title: "Existing", DueDate: dueDate})
+		require.NoError(t, err)
+
+		results, err := batch.ExecuteBatch(ctx, []domain.BatchOperation{
+			{Action: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: dueDate}},
+			{Action: domain.BatchUpdate, ID: existing.ID, Update: setStatus(domain.StatusInProgress)},
+			{Action: domain.BatchUpdate, ID: existing.ID, Update: setStatus("archived")},
+			{Action: domain.BatchDelete, ID: existing.ID},
+		}, true)
+
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, ErrOperationRolledBack)
+		assert.ErrorIs(t, results[1].Err, ErrOperationRolledBack)
+		assert.True(t, errors.IsValidationError(results[2].Err))
+		assert.ErrorIs(t, results[3].Err, ErrOperationRolledBack)
+
+		all, err := tasks.ListTasks(ctx, domain.TaskFilter{})
+		require.NoError(t, err)
+		require.Len(t, all, 1)
+		assert.Equal(t, domain.StatusPending, all[0].Status)
+		assert.Equal(t, existing.Version, all[0].Version)
+	})
+
+	t.Run("atomic batch with an invalid operation runs nothing", func(t *testing.T) {
+		batch, tasks := newBatchService(t, 10)
+
+		results, err := batch.ExecuteBatch(ctx, []domain.BatchOperation{
+			{Action: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: dueDate}},
+			{Action: domain.BatchCreate, Err: errors.NewValidationError("invalid task")},
+		}, true)
+
+		require.NoError(t, err)
+		assert.ErrorIs(t, results[0].Err, ErrOperationRolledBack)
+		assert.True(t, errors.IsValidationError(results[1].Err))
+		all, err := tasks.ListTasks(ctx, domain.TaskFilter{})
+		require.NoError(t, err)
+		assert.Empty(t, all)
+	})
+
+	t.Run("best-effort batch keeps successful operations", func(t *testing.T) {
+		batch, tasks := newBatchService(t, 10)
+		existing, err := tasks.CreateTask(ctx, domain.CreateTaskInput{Title: "Existing", DueDate: dueDate})
+		require.NoError(t, err)
+
+		results, err := batch.ExecuteBatch(ctx, []domain.BatchOperation{
+			{Action: domain.BatchUpdate, ID: existing.ID, Update: setStatus(domain.StatusCompleted)},
+			{Action: domain.BatchDelete, ID: "missing"},
+			{Action: domain.BatchDelete, ID: existing.ID, Version: existing.Version},
+		}, false)
+
+		require.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.True(t, errors.IsNotFoundError(results[1].Err))
+		assert.ErrorIs(t, results[2].Err, errors.ErrTaskVersionMismatch)
+
+		stored, err := tasks.GetTask(ctx, existing.ID)
+		require.NoError(t, err)
+		assert.Equal(t, domain.StatusCompleted, stored.Status)
+	})
+
+	t.Run("rejects batches that are empty or too large", func(t *testing.T) {
+		batch, _ := newBatchService(t, 1)
+		create := domain.BatchOperation{Action: domain.BatchCreate, Create: domain.CreateTaskInput{Title: "New", DueDate: dueDate}}
+
+		_, err := batch.ExecuteBatch(ctx, nil, false)
+		assert.True(t, errors.IsValidationError(err))
+
+		_, err = batch.ExecuteBatch(ctx, []domain.BatchOperation{create, create}, false)
+		assert.True(t, errors.IsValidationError(err))
+	})
+
+	t.Run("atomic batches need a transactor", func(t *testing.T) {
+		batch := NewBatchService(NewTaskService(memory.NewTaskRepository(), slog.Default()), nil, 10)
+
+		_, err := batch.ExecuteBatch(ctx, []domain.BatchOperation{{Action: domain.BatchDelete, ID: "x"}}, true)
+
+		assert.True(t, errors.IsValidationError(err))
+	})
+}
@@ -0,0 +1,28 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// runEvery calls job every interval until ctx is cancelled, logging how
+// many tasks each run affected
+func runEvery(ctx context.Context, logger *slog.Logger, interval time.Duration, name string, job func(ctx context.Context) (int64, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			affected, err := job(ctx)
+			if err != nil {
+				logger.ErrorContext(ctx, "scheduled job failed", "job", name, "error", err)
+			} else if affected > 0 {
+				logger.InfoContext(ctx, "scheduled job finished", "job", name, "tasks", affected)
+			}
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"task-tracking-service/internal/core/ports"
+	"time"
+)
+
+// TaskArchiver moves tasks that were completed long ago into the archive,
+// keeping the live task list short
+type TaskArchiver struct {
+	repo   ports.TaskRepository
+	after  time.Duration
+	logger *slog.Logger
+	now    func() time.Time
+}
+
+// NewTaskArchiver creates an archiver for tasks that have been completed,
+// and left unchanged, for longer than after
+func NewTaskArchiver(repo ports.TaskRepository, after time.Duration, logger *slog.Logger) *TaskArchiver {
+	return &TaskArchiver{
+		repo:   repo,
+		after:  after,
+		logger: logger,
+		now:    time.Now,
+	}
+}
+
+// ArchiveCompleted archives every eligible task, of any tenant, and returns
+// how many it archived
+func (a *TaskArchiver) ArchiveCompleted(ctx context.Context) (int64, error) {
+	return a.repo.ArchiveCompletedBefore(ctx, a.now().Add(-a.after))
+}
+
+// Run archives eligible tasks every interval until ctx is cancelled
+func (a *TaskArchiver) Run(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, a.logger, interval, "archive completed tasks", a.ArchiveCompleted)
+}
@@ -2,72 +2,176 @@ package services
 
 import (
 	"context"
+	"log/slog"
 	"task-tracking-service/internal/core/domain"
 	"task-tracking-service/internal/core/ports"
+	"task-tracking-service/pkg/errors"
 	"time"
 )
 
 type TaskService struct {
-	repo ports.TaskRepository
+	repo   ports.TaskRepository
+	logger *slog.Logger
 }
 
-func NewTaskService(repo ports.TaskRepository) *TaskService {
+var _ ports.TaskService = (*TaskService)(nil)
+
+// NewTaskService creates a TaskService. Changes to tasks are logged to
+// logger as an audit trail.
+func NewTaskService(repo ports.TaskRepository, logger *slog.Logger) *TaskService {
 	return &TaskService{
-		repo: repo,
+		repo:   repo,
+		logger: logger,
 	}
 }
 
-func (s *TaskService) CreateTask(ctx context.Context, title, description string, dueDate time.Time) (*domain.Task, error) {
+func (s *TaskService) CreateTask(ctx context.Context, input domain.CreateTaskInput) (*domain.Task, error) {
+	if err := validateNewTask(input, time.Now()); err != nil {
+		return nil, err
+	}
+
 	task := &domain.Task{
-		Title:       title,
-		Description: description,
+		ProjectID:   input.ProjectID,
+		Title:       input.Title,
+		Description: input.Description,
 		Status:      domain.StatusPending,
 		CreatedAt:   time.Now(),
 		UpdatedAt:   time.Now(),
-		DueDate:     dueDate,
+		DueDate:     input.DueDate,
 	}
 
 	if err := s.repo.Create(ctx, task); err != nil {
 		return nil, err
 	}
 
+	s.logger.InfoContext(ctx, "task created", "task_id", task.ID, "project_id", task.ProjectID)
 	return task, nil
 }
 
+// GetTask falls back to the archive for tasks that are not live
 func (s *TaskService) GetTask(ctx context.Context, id string) (*domain.Task, error) {
-	return s.repo.GetByID(ctx, id)
+	task, err := s.repo.GetByID(ctx, id)
+	if errors.IsNotFoundError(err) {
+		if archived, archivedErr := s.repo.GetArchivedByID(ctx, id); archivedErr == nil {
+			return archived, nil
+		}
+	}
+	return task, err
 }
 
-func (s *TaskService) ListTasks(ctx context.Context) ([]*domain.Task, error) {
-	return s.repo.List(ctx)
+func (s *TaskService) ListTasks(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	return s.repo.List(ctx, filter)
 }
 
 func (s *TaskService) UpdateTask(ctx context.Context, task *domain.Task) (*domain.Task, error) {
-	existing, err := s.repo.GetByID(ctx, task.ID)
-	if err != nil {
+	if err := validateTask(task); err != nil {
 		return nil, err
 	}
 
-	if err := s.validateStatusTransition(existing.Status, task.Status); err != nil {
+	err := s.withinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByID(ctx, task.ID)
+		if err != nil {
+			return s.archivedOr(ctx, task.ID, err)
+		}
+
+		if task.Version != 0 && task.Version != existing.Version {
+			return errors.ErrTaskVersionMismatch
+		}
+
+		if err := s.validateStatusTransition(existing.Status, task.Status); err != nil {
+			return err
+		}
+
+		task.CreatedAt = existing.CreatedAt
+		task.UpdatedAt = time.Now()
+		task.Version = existing.Version
+
+		if err := s.repo.Update(ctx, task); err != nil {
+			return err
+		}
+		if task.Status != existing.Status {
+			s.logger.InfoContext(ctx, "task status changed", "task_id", task.ID, "from", existing.Status, "to", task.Status)
+		}
+		return nil
+	})
+	if err != nil {
 		return nil, err
 	}
 
-	task.CreatedAt = existing.CreatedAt
-	task.UpdatedAt = time.Now()
+	return task, nil
+}
+
+func (s *TaskService) DeleteTask(ctx context.Context, id string, version int64) error {
+	return s.withinTx(ctx, func(ctx context.Context) error {
+		existing, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return s.archivedOr(ctx, id, err)
+		}
+
+		if version != 0 && version != existing.Version {
+			return errors.ErrTaskVersionMismatch
+		}
+
+		if err := s.repo.Delete(ctx, id, existing.Version); err != nil {
+			return err
+		}
+		s.logger.InfoContext(ctx, "task moved to trash", "task_id", id)
+		return nil
+	})
+}
+
+func (s *TaskService) GetDeletedTask(ctx context.Context, id string) (*domain.Task, error) {
+	return s.repo.GetDeletedByID(ctx, id)
+}
+
+func (s *TaskService) ListDeletedTasks(ctx context.Context) ([]*domain.Task, error) {
+	return s.repo.ListDeleted(ctx)
+}
 
-	if err := s.repo.Update(ctx, task); err != nil {
+func (s *TaskService) RestoreTask(ctx context.Context, id string) (*domain.Task, error) {
+	task, err := s.repo.Restore(ctx, id)
+	if err != nil {
 		return nil, err
 	}
-
+	s.logger.InfoContext(ctx, "task restored from trash", "task_id", id)
 	return task, nil
 }
 
-func (s *TaskService) DeleteTask(ctx context.Context, id string) error {
-	if _, err := s.repo.GetByID(ctx, id); err != nil {
+func (s *TaskService) PurgeTask(ctx context.Context, id string) error {
+	if err := s.repo.Purge(ctx, id); err != nil {
 		return err
 	}
+	s.logger.InfoContext(ctx, "task purged", "task_id", id)
+	return nil
+}
+
+func (s *TaskService) UnarchiveTask(ctx context.Context, id string) (*domain.Task, error) {
+	task, err := s.repo.Unarchive(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	s.logger.InfoContext(ctx, "task unarchived", "task_id", id)
+	return task, nil
+}
+
+// archivedOr explains that a task which could not be found for a change is
+// archived, and returns err for any other task
+func (s *TaskService) archivedOr(ctx context.Context, id string, err error) error {
+	if errors.IsNotFoundError(err) {
+		if _, archivedErr := s.repo.GetArchivedByID(ctx, id); archivedErr == nil {
+			return errors.ErrTaskArchived
+		}
+	}
+	return err
+}
 
-	return s.repo.Delete(ctx, id)
+// withinTx runs fn in a transaction when the repository supports them, so
+// that what fn reads cannot change before it writes
+func (s *TaskService) withinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if transactor, ok := s.repo.(ports.Transactor); ok {
+		return transactor.WithinTx(ctx, fn)
+	}
+	return fn(ctx)
 }
 
 func (s *TaskService) validateStatusTransition(from, to domain.TaskStatus) error {
@@ -116,3 +220,8 @@ func NewInvalidStatusError(message string) *InvalidStatusError {
 func (e *InvalidStatusError) Error() string {
 	return e.message
 }
+
+// Unwrap classifies the error as a validation failure of the status field
+func (e *InvalidStatusError) Unwrap() error {
+	return errors.NewValidationError(e.message, errors.FieldError{Field: "status", Message: e.message})
+}
@@ -2,11 +2,13 @@ package services
 
 import (
 	"context"
+	"log/slog"
 	"testing"
 	"time"
 
 	"task-tracking-service/internal/adapters/storage/memory"
 	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/pkg/errors"
 
 	"github.com/stretchr/testify/suite"
 )
@@ -21,7 +23,7 @@ type TaskServiceIntegrationSuite struct {
 func (s *TaskServiceIntegrationSuite) SetupTest() {
 	s.ctx = context.Background()
 	s.repo = memory.NewTaskRepository()
-	s.service = NewTaskService(s.repo)
+	s.service = NewTaskService(s.repo, slog.Default())
 }
 
 func TestTaskServiceIntegrationSuite(t *testing.T) {
@@ -34,7 +36,7 @@ func (s *TaskServiceIntegrationSuite) TestTaskLifecycle() {
 	desc := "Testing full task lifecycle"
 	dueDate := time.Now().Add(24 * time.Hour)
 
-	task, err := s.service.CreateTask(s.ctx, title, desc, dueDate)
+	task, err := s.service.CreateTask(s.ctx, domain.CreateTaskInput{Title: title, Description: desc, DueDate: dueDate})
 	s.NoError(err)
 	s.NotEmpty(task.ID)
 	s.Equal(title, task.Title)
@@ -56,60 +58,65 @@ func (s *TaskServiceIntegrationSuite) TestTaskLifecycle() {
 	s.Equal(domain.StatusInProgress, updated.Status)
 
 	// List tasks
-	tasks, err := s.service.ListTasks(s.ctx)
+	tasks, err := s.service.ListTasks(s.ctx, domain.TaskFilter{})
 	s.NoError(err)
 	s.Len(tasks, 1)
 	s.Equal(updated.ID, tasks[0].ID)
 
 	// Delete the task
-	err = s.service.DeleteTask(s.ctx, task.ID)
+	err = s.service.DeleteTask(s.ctx, task.ID, 0)
 	s.NoError(err)
 
 	// Verify deletion
-	tasks, err = s.service.ListTasks(s.ctx)
+	tasks, err = s.service.ListTasks(s.ctx, domain.TaskFilter{})
 	s.NoError(err)
 	s.Empty(tasks)
 }
 
 func (s *TaskServiceIntegrationSuite) TestInvalidStatusTransitions() {
 	// Create a task
-	task, err := s.service.CreateTask(s.ctx, "Test Task", "Description", time.Now().Add(24*time.Hour))
+	task, err := s.service.CreateTask(s.ctx, domain.CreateTaskInput{Title: "Test Task", Description: "Description", DueDate: time.Now().Add(24 * time.Hour)})
 	s.NoError(err)
 
 	// Try invalid status transition
 	task.Status = "invalid-status"
 	_, err = s.service.UpdateTask(s.ctx, task)
-	s.Error(err)
-	s.IsType(&InvalidStatusError{}, err)
+	var validationErr *errors.ValidationError
+	s.ErrorAs(err, &validationErr)
+	s.Equal("status", validationErr.Fields[0].Field)
 }
 
 func (s *TaskServiceIntegrationSuite) TestConcurrentOperations() {
 	// Create initial task
-	task, err := s.service.CreateTask(s.ctx, "Concurrent Test", "Description", time.Now().Add(24*time.Hour))
+	task, err := s.service.CreateTask(s.ctx, domain.CreateTaskInput{Title: "Concurrent Test", Description: "Description", DueDate: time.Now().Add(24 * time.Hour)})
 	s.NoError(err)
 
-	// Simulate concurrent updates
-	done := make(chan bool)
-	go func() {
-		task.Title = "Update 1"
-		_, err := s.service.UpdateTask(s.ctx, task)
-		s.NoError(err)
-		done <- true
-	}()
-
-	go func() {
-		task.Title = "Update 2"
-		_, err := s.service.UpdateTask(s.ctx, task)
-		s.NoError(err)
-		done <- true
-	}()
-
-	// Wait for both updates
-	<-done
-	<-done
+	// Simulate two clients updating from the same version
+	results := make(chan error, 2)
+	for _, title := range []string{"Update 1", "Update 2"} {
+		update := *task
+		update.Title = title
+		go func() {
+			_, err := s.service.UpdateTask(s.ctx, &update)
+			results <- err
+		}()
+	}
+
+	// Exactly one update wins; the other is told its version is stale
+	var succeeded int
+	for range 2 {
+		err := <-results
+		if err == nil {
+			succeeded++
+			continue
+		}
+		s.True(errors.IsPreconditionFailedError(err) || errors.IsConflictError(err), "unexpected error: %v", err)
+	}
+	s.Equal(1, succeeded)
 
 	// Verify final state
 	updated, err := s.service.GetTask(s.ctx, task.ID)
 	s.NoError(err)
 	s.NotEqual("Concurrent Test", updated.Title)
+	s.Equal(task.Version+1, updated.Version)
 }
@@ -1,15 +1,21 @@
 package services
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
 	"testing"
 	"time"
 
+	"task-tracking-service/internal/adapters/storage/memory"
 	"task-tracking-service/internal/core/domain"
 	"task-tracking-service/pkg/errors"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockTaskRepository is a mock implementation of ports.TaskRepository
@@ -33,8 +39,8 @@ func (m *MockTaskRepository) GetByID(ctx context.Context, id string) (*domain.Ta
 	return args.Get(0).(*domain.Task), args.Error(1)
 }
 
-func (m *MockTaskRepository) List(ctx context.Context) ([]*domain.Task, error) {
-	args := m.Called(ctx)
+func (m *MockTaskRepository) List(ctx context.Context, filter domain.TaskFilter) ([]*domain.Task, error) {
+	args := m.Called(ctx, filter)
 	if args.Get(0) == nil {
 		return nil, args.Error(1)
 	}
@@ -46,14 +52,69 @@ func (m *MockTaskRepository) Update(ctx context.Context, task *domain.Task) erro
 	return args.Error(0)
 }
 
-func (m *MockTaskRepository) Delete(ctx context.Context, id string) error {
+func (m *MockTaskRepository) Delete(ctx context.Context, id string, version int64) error {
+	args := m.Called(ctx, id, version)
+	return args.Error(0)
+}
+
+func (m *MockTaskRepository) GetDeletedByID(ctx context.Context, id string) (*domain.Task, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) ListDeleted(ctx context.Context) ([]*domain.Task, error) {
+	args := m.Called(ctx)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) Restore(ctx context.Context, id string) (*domain.Task, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) Purge(ctx context.Context, id string) error {
 	args := m.Called(ctx, id)
 	return args.Error(0)
 }
 
+func (m *MockTaskRepository) PurgeDeletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) GetArchivedByID(ctx context.Context, id string) (*domain.Task, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
+func (m *MockTaskRepository) ArchiveCompletedBefore(ctx context.Context, cutoff time.Time) (int64, error) {
+	args := m.Called(ctx, cutoff)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockTaskRepository) Unarchive(ctx context.Context, id string) (*domain.Task, error) {
+	args := m.Called(ctx, id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.Task), args.Error(1)
+}
+
 func TestTaskService_CreateTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, slog.Default())
 	ctx := context.Background()
 
 	t.Run("successfully creates task", func(t *testing.T) {
@@ -63,7 +124,7 @@ func TestTaskService_CreateTask(t *testing.T) {
 
 		mockRepo.On("Create", ctx, mock.AnythingOfType("*domain.Task")).Return(nil)
 
-		task, err := service.CreateTask(ctx, title, description, dueDate)
+		task, err := service.CreateTask(ctx, domain.CreateTaskInput{Title: title, Description: description, DueDate: dueDate})
 
 		assert.NoError(t, err)
 		assert.NotNil(t, task)
@@ -72,11 +133,51 @@ func TestTaskService_CreateTask(t *testing.T) {
 		assert.Equal(t, domain.StatusPending, task.Status)
 		mockRepo.AssertExpectations(t)
 	})
+
+	tests := []struct {
+		name           string
+		input          domain.CreateTaskInput
+		expectedFields []errors.FieldError
+	}{
+		{
+			name:  "rejects a missing title and due date",
+			input: domain.CreateTaskInput{Title: "  "},
+			expectedFields: []errors.FieldError{
+				{Field: "title", Message: "is required"},
+				{Field: "due_date", Message: "is required"},
+			},
+		},
+		{
+			name:  "rejects an overlong title",
+			input: domain.CreateTaskInput{Title: strings.Repeat("x", domain.MaxTitleLength+1), DueDate: time.Now().Add(time.Hour)},
+			expectedFields: []errors.FieldError{
+				{Field: "title", Message: "must be at most 255 characters"},
+			},
+		},
+		{
+			name:  "rejects a due date in the past",
+			input: domain.CreateTaskInput{Title: "Late", DueDate: time.Now().Add(-time.Hour)},
+			expectedFields: []errors.FieldError{
+				{Field: "due_date", Message: "must not be in the past"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			task, err := service.CreateTask(ctx, tt.input)
+
+			assert.Nil(t, task)
+			var validationErr *errors.ValidationError
+			assert.ErrorAs(t, err, &validationErr)
+			assert.Equal(t, tt.expectedFields, validationErr.Fields)
+		})
+	}
 }
 
 func TestTaskService_GetTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, slog.Default())
 	ctx := context.Background()
 
 	t.Run("successfully gets existing task", func(t *testing.T) {
@@ -98,6 +199,7 @@ func TestTaskService_GetTask(t *testing.T) {
 
 	t.Run("returns error for non-existent task", func(t *testing.T) {
 		mockRepo.On("GetByID", ctx, "non-existent").Return(nil, errors.NewNotFoundError("task not found"))
+		mockRepo.On("GetArchivedByID", ctx, "non-existent").Return(nil, errors.NewNotFoundError("task not found"))
 
 		task, err := service.GetTask(ctx, "non-existent")
 
@@ -105,11 +207,22 @@ func TestTaskService_GetTask(t *testing.T) {
 		assert.Nil(t, task)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("falls back to archived tasks", func(t *testing.T) {
+		archivedAt := time.Now()
+		mockRepo.On("GetByID", ctx, "archived-id").Return(nil, errors.NewNotFoundError("task not found"))
+		mockRepo.On("GetArchivedByID", ctx, "archived-id").Return(&domain.Task{ID: "archived-id", ArchivedAt: &archivedAt}, nil)
+
+		task, err := service.GetTask(ctx, "archived-id")
+
+		assert.NoError(t, err)
+		assert.Equal(t, &archivedAt, task.ArchivedAt)
+	})
 }
 
 func TestTaskService_UpdateTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, slog.Default())
 	ctx := context.Background()
 
 	t.Run("successfully updates task", func(t *testing.T) {
@@ -149,6 +262,7 @@ func TestTaskService_UpdateTask(t *testing.T) {
 
 		invalidTask := &domain.Task{
 			ID:     "test-id",
+			Title:  "Test Task",
 			Status: "invalid-status",
 		}
 
@@ -158,36 +272,185 @@ func TestTaskService_UpdateTask(t *testing.T) {
 
 		assert.Error(t, err)
 		assert.Nil(t, result)
-		assert.IsType(t, &InvalidStatusError{}, err)
+		var validationErr *errors.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Equal(t, []errors.FieldError{{Field: "status", Message: "must be one of pending, in_progress, completed"}}, validationErr.Fields)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("fails when the expected version is stale", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, "versioned-id").Return(&domain.Task{ID: "versioned-id", Status: domain.StatusPending, Version: 2}, nil)
+
+		result, err := service.UpdateTask(ctx, &domain.Task{ID: "versioned-id", Title: "Stale", Status: domain.StatusPending, Version: 1})
+
+		assert.Nil(t, result)
+		assert.ErrorIs(t, err, errors.ErrTaskVersionMismatch)
+	})
+
+	t.Run("reports every invalid field at once", func(t *testing.T) {
+		result, err := service.UpdateTask(ctx, &domain.Task{ID: "test-id", Status: "archived"})
+
+		assert.Nil(t, result)
+		var validationErr *errors.ValidationError
+		assert.ErrorAs(t, err, &validationErr)
+		assert.Len(t, validationErr.Fields, 2)
+	})
 }
 
 func TestTaskService_DeleteTask(t *testing.T) {
 	mockRepo := new(MockTaskRepository)
-	service := NewTaskService(mockRepo)
+	service := NewTaskService(mockRepo, slog.Default())
 	ctx := context.Background()
 
 	t.Run("successfully deletes existing task", func(t *testing.T) {
 		existingTask := &domain.Task{
-			ID: "test-id",
+			ID:      "test-id",
+			Version: 3,
 		}
 
 		mockRepo.On("GetByID", ctx, "test-id").Return(existingTask, nil)
-		mockRepo.On("Delete", ctx, "test-id").Return(nil)
+		mockRepo.On("Delete", ctx, "test-id", int64(3)).Return(nil)
 
-		err := service.DeleteTask(ctx, "test-id")
+		err := service.DeleteTask(ctx, "test-id", 0)
 
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
 	})
 
+	t.Run("fails when the expected version is stale", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, "versioned-id").Return(&domain.Task{ID: "versioned-id", Version: 2}, nil)
+
+		err := service.DeleteTask(ctx, "versioned-id", 1)
+
+		assert.ErrorIs(t, err, errors.ErrTaskVersionMismatch)
+		mockRepo.AssertNotCalled(t, "Delete", ctx, "versioned-id", mock.Anything)
+	})
+
 	t.Run("fails to delete non-existent task", func(t *testing.T) {
 		mockRepo.On("GetByID", ctx, "non-existent").Return(nil, errors.NewNotFoundError("task not found"))
+		mockRepo.On("GetArchivedByID", ctx, "non-existent").Return(nil, errors.NewNotFoundError("task not found"))
 
-		err := service.DeleteTask(ctx, "non-existent")
+		err := service.DeleteTask(ctx, "non-existent", 0)
 
-		assert.Error(t, err)
+		assert.True(t, errors.IsNotFoundError(err))
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("refuses to delete an archived task", func(t *testing.T) {
+		mockRepo.On("GetByID", ctx, "archived-id").Return(nil, errors.NewNotFoundError("task not found"))
+		mockRepo.On("GetArchivedByID", ctx, "archived-id").Return(&domain.Task{ID: "archived-id"}, nil)
+
+		err := service.DeleteTask(ctx, "archived-id", 0)
+
+		assert.ErrorIs(t, err, errors.ErrTaskArchived)
+	})
+}
+
+type txContextKey struct{}
+
+// transactionalRepository adds transactions to the mock repository, marking
+// the context of every call made within one
+type transactionalRepository struct {
+	*MockTaskRepository
+	transactions int
+}
+
+func (r *transactionalRepository) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	r.transactions++
+	return fn(context.WithValue(ctx, txContextKey{}, true))
+}
+
+func TestTaskService_ReadModifyWriteRunsInTransaction(t *testing.T) {
+	inTx := mock.MatchedBy(func(ctx context.Context) bool {
+		return ctx.Value(txContextKey{}) == true
+	})
+
+	t.Run("update", func(t *testing.T) {
+		repo := &transactionalRepository{MockTaskRepository: new(MockTaskRepository)}
+		service := NewTaskService(repo, slog.Default())
+
+		repo.On("GetByID", inTx, "test-id").Return(&domain.Task{ID: "test-id", Status: domain.StatusPending, Version: 1}, nil)
+		repo.On("Update", inTx, mock.AnythingOfType("*domain.Task")).Return(nil)
+
+		_, err := service.UpdateTask(context.Background(), &domain.Task{ID: "test-id", Title: "Updated", Status: domain.StatusInProgress})
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, repo.transactions)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("delete", func(t *testing.T) {
+		repo := &transactionalRepository{MockTaskRepository: new(MockTaskRepository)}
+		service := NewTaskService(repo, slog.Default())
+
+		repo.On("GetByID", inTx, "test-id").Return(&domain.Task{ID: "test-id", Version: 1}, nil)
+		repo.On("Delete", inTx, "test-id", int64(1)).Return(nil)
+
+		err := service.DeleteTask(context.Background(), "test-id", 0)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 1, repo.transactions)
+		repo.AssertExpectations(t)
+	})
+}
+
+func TestTaskService_LogsChanges(t *testing.T) {
+	var buf bytes.Buffer
+	service := NewTaskService(memory.NewTaskRepository(), slog.New(slog.NewJSONHandler(&buf, nil)))
+	ctx := context.Background()
+
+	task, err := service.CreateTask(ctx, domain.CreateTaskInput{Title: "Logged", DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+	task.Status = domain.StatusInProgress
+	_, err = service.UpdateTask(ctx, task)
+	require.NoError(t, err)
+	task.Title = "Renamed"
+	_, err = service.UpdateTask(ctx, task)
+	require.NoError(t, err)
+	require.NoError(t, service.DeleteTask(ctx, task.ID, 0))
+
+	var messages []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var record struct {
+			Msg    string `json:"msg"`
+			TaskID string `json:"task_id"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(line), &record))
+		assert.Equal(t, task.ID, record.TaskID)
+		messages = append(messages, record.Msg)
+	}
+	// Only changes of status are logged among updates
+	assert.Equal(t, []string{"task created", "task status changed", "task moved to trash"}, messages)
+}
+
+func TestTrashPurger_PurgeExpired(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	purger := NewTrashPurger(mockRepo, 24*time.Hour, slog.Default())
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	purger.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	mockRepo.On("PurgeDeletedBefore", ctx, now.Add(-24*time.Hour)).Return(int64(2), nil)
+
+	purged, err := purger.PurgeExpired(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), purged)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestTaskArchiver_ArchiveCompleted(t *testing.T) {
+	mockRepo := new(MockTaskRepository)
+	archiver := NewTaskArchiver(mockRepo, 90*24*time.Hour, slog.Default())
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	archiver.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	mockRepo.On("ArchiveCompletedBefore", ctx, now.Add(-90*24*time.Hour)).Return(int64(5), nil)
+
+	archived, err := archiver.ArchiveCompleted(ctx)
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(5), archived)
+	mockRepo.AssertExpectations(t)
 }
@@ -0,0 +1,56 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/pkg/errors"
+)
+
+// validateNewTask checks the fields of a task about to be created. Every
+// violation is collected so callers can fix them all in one go.
+func validateNewTask(input domain.CreateTaskInput, now time.Time) error {
+	fields := validateTitle(nil, input.Title)
+	switch {
+	case input.DueDate.IsZero():
+		fields = append(fields, errors.FieldError{Field: "due_date", Message: "is required"})
+	case input.DueDate.Before(now):
+		fields = append(fields, errors.FieldError{Field: "due_date", Message: "must not be in the past"})
+	}
+	return invalidTask(fields)
+}
+
+// validateTask checks the caller-editable fields of an existing task
+func validateTask(task *domain.Task) error {
+	fields := validateTitle(nil, task.Title)
+	if !task.Status.IsValid() {
+		fields = append(fields, errors.FieldError{
+			Field:   "status",
+			Message: fmt.Sprintf("must be one of %s, %s, %s", domain.StatusPending, domain.StatusInProgress, domain.StatusCompleted),
+		})
+	}
+	return invalidTask(fields)
+}
+
+func validateTitle(fields []errors.FieldError, title string) []errors.FieldError {
+	switch {
+	case strings.TrimSpace(title) == "":
+		return append(fields, errors.FieldError{Field: "title", Message: "is required"})
+	case utf8.RuneCountInString(title) > domain.MaxTitleLength:
+		return append(fields, errors.FieldError{
+			Field:   "title",
+			Message: fmt.Sprintf("must be at most %d characters", domain.MaxTitleLength),
+		})
+	}
+	return fields
+}
+
+func invalidTask(fields []errors.FieldError) error {
+	if len(fields) == 0 {
+		return nil
+	}
+	return errors.NewValidationError("invalid task", fields...)
+}
@@ -0,0 +1,253 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+	"time"
+)
+
+// TaskScope narrows a filter to the tasks the caller in ctx may read. ok
+// is false when the caller may read none of them.
+type TaskScope func(ctx context.Context, filter domain.TaskFilter) (scoped domain.TaskFilter, ok bool, err error)
+
+// TaskWatcher reports changes to tasks as they happen. It polls the task
+// service and compares versions, so it sees changes made by every replica
+// and by background jobs. Watches of the same tenant share one poll, whose
+// tasks each watch narrows to what its caller may read.
+type TaskWatcher struct {
+	tasks    ports.TaskService
+	scope    TaskScope
+	interval time.Duration
+
+	mutex   sync.Mutex
+	pollers map[pollerKey]*taskPoller
+}
+
+// pollerKey identifies the watches that can share a poll
+type pollerKey struct {
+	tenantID        string
+	includeArchived bool
+}
+
+// taskPoller lists a tenant's tasks every interval for its subscribers
+type taskPoller struct {
+	subscribers map[chan taskSnapshot]struct{}
+	stop        context.CancelFunc
+}
+
+// taskSnapshot is the outcome of one poll
+type taskSnapshot struct {
+	tasks []*domain.Task
+	err   error
+}
+
+// NewTaskWatcher creates a watcher that looks for changes every interval.
+// tasks is polled for all of a tenant's tasks, so it must not apply
+// per-caller restrictions itself; scope applies them instead. A nil scope
+// shows every caller all the tasks of their tenant.
+func NewTaskWatcher(tasks ports.TaskService, scope TaskScope, interval time.Duration) *TaskWatcher {
+	if scope == nil {
+		scope = func(_ context.Context, filter domain.TaskFilter) (domain.TaskFilter, bool, error) {
+			return filter, true, nil
+		}
+	}
+	return &TaskWatcher{
+		tasks:    tasks,
+		scope:    scope,
+		interval: interval,
+		pollers:  make(map[pollerKey]*taskPoller),
+	}
+}
+
+// Watch calls emit with each change to the tasks visible to ctx until ctx
+// is cancelled, emit fails or the tasks cannot be listed. Changes made
+// between two polls are reported once, with the latest state of the task.
+func (w *TaskWatcher) Watch(ctx context.Context, opts domain.TaskWatchOptions, emit func(domain.TaskEvent) error) error {
+	filter, visible, err := w.scope(ctx, domain.TaskFilter{IncludeArchived: opts.IncludeArchived})
+	if err != nil {
+		return err
+	}
+	// Only what the caller may read, from the tasks of a poll
+	readable := func(tasks []*domain.Task) []*domain.Task {
+		if !visible {
+			return nil
+		}
+		return slices.DeleteFunc(slices.Clone(tasks), func(task *domain.Task) bool {
+			return !filter.Matches(task)
+		})
+	}
+
+	snapshots, unsubscribe := w.subscribe(pollerKey{
+		tenantID:        domain.TenantFromContext(ctx),
+		includeArchived: opts.IncludeArchived,
+	})
+	defer unsubscribe()
+
+	var current []*domain.Task
+	if visible {
+		// The first listing is the watch's own, so that it starts from the
+		// tasks as they are now rather than as of the last poll
+		if current, err = w.tasks.ListTasks(ctx, filter); err != nil {
+			return err
+		}
+	}
+
+	known := make(map[string]*domain.Task, len(current))
+	if opts.IncludeExisting {
+		if err := emitAll(taskChanges(known, current), emit); err != nil {
+			return err
+		}
+	}
+	for _, task := range current {
+		known[task.ID] = task
+	}
+
+	for {
+		var snapshot taskSnapshot
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case snapshot = <-snapshots:
+		}
+		if snapshot.err != nil {
+			return snapshot.err
+		}
+
+		events := taskChanges(known, readable(snapshot.tasks))
+		for _, event := range events {
+			if event.Type == domain.TaskRemoved {
+				delete(known, event.Task.ID)
+			} else {
+				known[event.Task.ID] = event.Task
+			}
+		}
+		if err := emitAll(events, emit); err != nil {
+			return err
+		}
+	}
+}
+
+// subscribe returns a channel receiving the latest poll of the tasks key
+// describes, starting a poller for them if there is none. A subscriber that
+// falls behind only misses polls superseded by a later one.
+func (w *TaskWatcher) subscribe(key pollerKey) (<-chan taskSnapshot, func()) {
+	w.mutex.Lock()
+	defer w.mutex.Unlock()
+
+	poller, ok := w.pollers[key]
+	if !ok {
+		ctx, stop := context.WithCancel(context.Background())
+		poller = &taskPoller{subscribers: make(map[chan taskSnapshot]struct{}), stop: stop}
+		w.pollers[key] = poller
+		go w.poll(ctx, key, poller)
+	}
+	snapshots := make(chan taskSnapshot, 1)
+	poller.subscribers[snapshots] = struct{}{}
+
+	return snapshots, func() {
+		w.mutex.Lock()
+		defer w.mutex.Unlock()
+
+		delete(poller.subscribers, snapshots)
+		if len(poller.subscribers) == 0 && w.pollers[key] == poller {
+			poller.stop()
+			delete(w.pollers, key)
+		}
+	}
+}
+
+// poll lists the tasks key describes every interval and hands them to the
+// subscribers of poller, until ctx is cancelled or listing fails
+func (w *TaskWatcher) poll(ctx context.Context, key pollerKey, poller *taskPoller) {
+	listCtx := domain.ContextWithTenant(ctx, key.tenantID)
+	filter := domain.TaskFilter{IncludeArchived: key.includeArchived}
+
+	ticker := time.NewTicker(w.interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		tasks, err := w.tasks.ListTasks(listCtx, filter)
+		if ctx.Err() != nil {
+			return
+		}
+
+		w.mutex.Lock()
+		for snapshots := range poller.subscribers {
+			// Replace a poll the subscriber has not read yet. Only this
+			// goroutine sends, so the send cannot block.
+			select {
+			case <-snapshots:
+			default:
+			}
+			snapshots <- taskSnapshot{tasks: tasks, err: err}
+		}
+		if err != nil && w.pollers[key] == poller {
+			// Every subscriber stops on the error; later watches start afresh
+			delete(w.pollers, key)
+		}
+		w.mutex.Unlock()
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+// taskChanges compares the tasks seen last time with the current ones. It
+// returns creations and updates in the order they happened, followed by
+// removals.
+func taskChanges(known map[string]*domain.Task, current []*domain.Task) []domain.TaskEvent {
+	var changed, removed []domain.TaskEvent
+	seen := make(map[string]bool, len(current))
+	for _, task := range current {
+		seen[task.ID] = true
+		previous, ok := known[task.ID]
+		switch {
+		case !ok:
+			changed = append(changed, domain.TaskEvent{Type: domain.TaskCreated, Task: task})
+		case previous.Version != task.Version || !sameTime(previous.ArchivedAt, task.ArchivedAt):
+			changed = append(changed, domain.TaskEvent{Type: domain.TaskUpdated, Task: task})
+		}
+	}
+	for id, task := range known {
+		if !seen[id] {
+			removed = append(removed, domain.TaskEvent{Type: domain.TaskRemoved, Task: task})
+		}
+	}
+
+	slices.SortFunc(changed, func(a, b domain.TaskEvent) int {
+		if c := a.Task.UpdatedAt.Compare(b.Task.UpdatedAt); c != 0 {
+			return c
+		}
+		return strings.Compare(a.Task.ID, b.Task.ID)
+	})
+	slices.SortFunc(removed, func(a, b domain.TaskEvent) int {
+		return strings.Compare(a.Task.ID, b.Task.ID)
+	})
+	return append(changed, removed...)
+}
+
+func sameTime(a, b *time.Time) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+	return a.Equal(*b)
+}
+
+func emitAll(events []domain.TaskEvent, emit func(domain.TaskEvent) error) error {
+	for _, event := range events {
+		if err := emit(event); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTaskChanges(t *testing.T) {
+	at := func(minute int) time.Time { return time.Date(2024, 6, 1, 12, minute, 0, 0, time.UTC) }
+	archivedAt := at(9)
+	known := map[string]*domain.Task{
+		"a": {ID: "a", Version: 1, UpdatedAt: at(0)},
+		"b": {ID: "b", Version: 1, UpdatedAt: at(0)},
+		"c": {ID: "c", Version: 2, UpdatedAt: at(0)},
+		"d": {ID: "d", Version: 1, UpdatedAt: at(0)},
+	}
+	current := []*domain.Task{
+		{ID: "e", Version: 1, UpdatedAt: at(5)},
+		{ID: "c", Version: 3, UpdatedAt: at(3)},
+		{ID: "b", Version: 1, UpdatedAt: at(0)},
+		{ID: "a", Version: 1, UpdatedAt: at(0), ArchivedAt: &archivedAt},
+	}
+
+	events := taskChanges(known, current)
+
+	type change struct {
+		Type domain.TaskEventType
+		ID   string
+	}
+	var got []change
+	for _, event := range events {
+		got = append(got, change{event.Type, event.Task.ID})
+	}
+	assert.Equal(t, []change{
+		{domain.TaskUpdated, "a"},
+		{domain.TaskUpdated, "c"},
+		{domain.TaskCreated, "e"},
+		{domain.TaskRemoved, "d"},
+	}, got)
+}
+
+func TestTaskWatcher_Watch(t *testing.T) {
+	tasks := NewTaskService(memory.NewTaskRepository(), slog.Default())
+	watcher := NewTaskWatcher(tasks, nil, 5*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	existing, err := tasks.CreateTask(ctx, domain.CreateTaskInput{Title: "Existing", DueDate: time.Now().Add(time.Hour)})
+	require.NoError(t, err)
+
+	events := make(chan domain.TaskEvent)
+	done := make(chan error, 1)
+	watchCtx, stopWatching := context.WithCancel(ctx)
+	go func() {
+		done <- watcher.Watch(watchCtx, domain.TaskWatchOptions{IncludeExisting: true}, func(event domain.TaskEvent) error {
+			select {
+			case events <- event:
+				return nil
+			case <-watchCtx.Done():
+				return watchCtx.Err()
+			}
+		})
+	}()
+	next := func(t *testing.T) domain.TaskEvent {
+		t.Helper()
+		select {
+		case event := <-events:
+			return event
+		case <-ctx.Done():
+			t.Fatal("no event")
+			return domain.TaskEvent{}
+		}
+	}
+
+	t.Run("reports existing tasks first", func(t *testing.T) {
+		event := next(t)
+		assert.Equal(t, domain.TaskCreated, event.Type)
+		assert.Equal(t, existing.ID, event.Task.ID)
+	})
+
+	t.Run("reports creations, updates and removals", func(t *testing.T) {
+		created, err := tasks.CreateTask(ctx, domain.CreateTaskInput{Title: "New", DueDate: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		event := next(t)
+		assert.Equal(t, domain.TaskCreated, event.Type)
+		assert.Equal(t, created.ID, event.Task.ID)
+
+		created.Status = domain.StatusInProgress
+		_, err = tasks.UpdateTask(ctx, created)
+		require.NoError(t, err)
+		event = next(t)
+		assert.Equal(t, domain.TaskUpdated, event.Type)
+		assert.Equal(t, domain.StatusInProgress, event.Task.Status)
+
+		require.NoError(t, tasks.DeleteTask(ctx, created.ID, 0))
+		event = next(t)
+		assert.Equal(t, domain.TaskRemoved, event.Type)
+		assert.Equal(t, created.ID, event.Task.ID)
+	})
+
+	t.Run("stops when the context is cancelled", func(t *testing.T) {
+		stopWatching()
+		assert.True(t, errors.Is(<-done, context.Canceled))
+	})
+}
+
+func TestTaskWatcher_SharesPollsPerTenant(t *testing.T) {
+	tasks := NewTaskService(memory.NewTaskRepository(), slog.Default())
+	// Callers named in the context may only read project "mine"
+	scope := func(ctx context.Context, filter domain.TaskFilter) (domain.TaskFilter, bool, error) {
+		if ctx.Value(scopedCaller{}) != nil {
+			filter.ProjectIDs = []string{"mine"}
+		}
+		return filter, true, nil
+	}
+	watcher := NewTaskWatcher(tasks, scope, 5*time.Millisecond)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	type watch struct {
+		events chan domain.TaskEvent
+		done   chan error
+		stop   context.CancelFunc
+	}
+	start := func(ctx context.Context) watch {
+		ctx, stop := context.WithCancel(ctx)
+		w := watch{events: make(chan domain.TaskEvent, 10), done: make(chan error, 1), stop: stop}
+		go func() {
+			w.done <- watcher.Watch(ctx, domain.TaskWatchOptions{}, func(event domain.TaskEvent) error {
+				w.events <- event
+				return nil
+			})
+		}()
+		return w
+	}
+	pollers := func() int {
+		watcher.mutex.Lock()
+		defer watcher.mutex.Unlock()
+		return len(watcher.pollers)
+	}
+
+	all := start(ctx)
+	scoped := start(context.WithValue(ctx, scopedCaller{}, true))
+	other := start(domain.ContextWithTenant(ctx, "acme"))
+	require.Eventually(t, func() bool { return pollers() == 2 }, time.Second, time.Millisecond)
+
+	t.Run("each watch sees what its caller may read", func(t *testing.T) {
+		_, err := tasks.CreateTask(ctx, domain.CreateTaskInput{ProjectID: "theirs", Title: "Theirs", DueDate: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		mine, err := tasks.CreateTask(ctx, domain.CreateTaskInput{ProjectID: "mine", Title: "Mine", DueDate: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+
+		require.Eventually(t, func() bool { return len(all.events) == 2 }, time.Second, time.Millisecond)
+		event := <-scoped.events
+		assert.Equal(t, mine.ID, event.Task.ID)
+		assert.Empty(t, scoped.events)
+		assert.Empty(t, other.events)
+	})
+
+	t.Run("the last watch of a tenant stops its poller", func(t *testing.T) {
+		all.stop()
+		<-all.done
+		assert.Equal(t, 2, pollers())
+
+		scoped.stop()
+		<-scoped.done
+		other.stop()
+		<-other.done
+		assert.Equal(t, 0, pollers())
+	})
+}
+
+type scopedCaller struct{}
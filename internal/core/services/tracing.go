@@ -0,0 +1,111 @@
+package services
+
+import (
+	"context"
+
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/ports"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// tracerName identifies the spans started by this package
+const tracerName = "task-tracking-service/internal/core/services"
+
+// TracedTaskService records a span around each call to another
+// TaskService, using the global tracer provider
+type TracedTaskService struct {
+	next   ports.TaskService
+	tracer trace.Tracer
+}
+
+var _ ports.TaskService = (*TracedTaskService)(nil)
+
+func NewTracedTaskService(next ports.TaskService) *TracedTaskService {
+	return &TracedTaskService{
+		next:   next,
+		tracer: otel.Tracer(tracerName),
+	}
+}
+
+func (s *TracedTaskService) CreateTask(ctx context.Context, input domain.CreateTaskInput) (task *domain.Task, err error) {
+	ctx, span := s.start(ctx, "CreateTask", attribute.String("task.project_id", input.ProjectID))
+	defer endSpan(span, &err)
+	return s.next.CreateTask(ctx, input)
+}
+
+func (s *TracedTaskService) GetTask(ctx context.Context, id string) (task *domain.Task, err error) {
+	ctx, span := s.start(ctx, "GetTask", taskID(id))
+	defer endSpan(span, &err)
+	return s.next.GetTask(ctx, id)
+}
+
+func (s *TracedTaskService) ListTasks(ctx context.Context, filter domain.TaskFilter) (tasks []*domain.Task, err error) {
+	ctx, span := s.start(ctx, "ListTasks", attribute.Bool("task.include_archived", filter.IncludeArchived))
+	defer endSpan(span, &err)
+	return s.next.ListTasks(ctx, filter)
+}
+
+func (s *TracedTaskService) UpdateTask(ctx context.Context, task *domain.Task) (updated *domain.Task, err error) {
+	ctx, span := s.start(ctx, "UpdateTask", taskID(task.ID))
+	defer endSpan(span, &err)
+	return s.next.UpdateTask(ctx, task)
+}
+
+func (s *TracedTaskService) DeleteTask(ctx context.Context, id string, version int64) (err error) {
+	ctx, span := s.start(ctx, "DeleteTask", taskID(id))
+	defer endSpan(span, &err)
+	return s.next.DeleteTask(ctx, id, version)
+}
+
+func (s *TracedTaskService) GetDeletedTask(ctx context.Context, id string) (task *domain.Task, err error) {
+	ctx, span := s.start(ctx, "GetDeletedTask", taskID(id))
+	defer endSpan(span, &err)
+	return s.next.GetDeletedTask(ctx, id)
+}
+
+func (s *TracedTaskService) ListDeletedTasks(ctx context.Context) (tasks []*domain.Task, err error) {
+	ctx, span := s.start(ctx, "ListDeletedTasks")
+	defer endSpan(span, &err)
+	return s.next.ListDeletedTasks(ctx)
+}
+
+func (s *TracedTaskService) RestoreTask(ctx context.Context, id string) (task *domain.Task, err error) {
+	ctx, span := s.start(ctx, "RestoreTask", taskID(id))
+	defer endSpan(span, &err)
+	return s.next.RestoreTask(ctx, id)
+}
+
+func (s *TracedTaskService) PurgeTask(ctx context.Context, id string) (err error) {
+	ctx, span := s.start(ctx, "PurgeTask", taskID(id))
+	defer endSpan(span, &err)
+	return s.next.PurgeTask(ctx, id)
+}
+
+func (s *TracedTaskService) UnarchiveTask(ctx context.Context, id string) (task *domain.Task, err error) {
+	ctx, span := s.start(ctx, "UnarchiveTask", taskID(id))
+	defer endSpan(span, &err)
+	return s.next.UnarchiveTask(ctx, id)
+}
+
+// start begins a span named after the TaskService method
+func (s *TracedTaskService) start(ctx context.Context, method string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return s.tracer.Start(ctx, "TaskService."+method, trace.WithAttributes(attrs...))
+}
+
+// endSpan ends span, marking it failed if *err is set. It takes err by
+// reference so that it can be deferred.
+func endSpan(span trace.Span, err *error) {
+	if *err != nil {
+		span.RecordError(*err)
+		span.SetStatus(codes.Error, (*err).Error())
+	}
+	span.End()
+}
+
+func taskID(id string) attribute.KeyValue {
+	return attribute.String("task.id", id)
+}
@@ -0,0 +1,78 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"testing"
+	"time"
+
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+)
+
+// useInMemoryTracing routes spans from the global tracer provider to an
+// in-memory exporter for the rest of the test
+func useInMemoryTracing(t *testing.T) *tracetest.InMemoryExporter {
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previous := otel.GetTracerProvider()
+	otel.SetTracerProvider(provider)
+	t.Cleanup(func() { otel.SetTracerProvider(previous) })
+	return exporter
+}
+
+func TestTracedTaskService(t *testing.T) {
+	exporter := useInMemoryTracing(t)
+	service := NewTracedTaskService(NewTaskService(memory.NewTaskRepository(), slog.Default()))
+	ctx := context.Background()
+
+	t.Run("successful calls are recorded", func(t *testing.T) {
+		exporter.Reset()
+
+		task, err := service.CreateTask(ctx, domain.CreateTaskInput{Title: "Traced", DueDate: time.Now().Add(time.Hour)})
+		require.NoError(t, err)
+		_, err = service.GetTask(ctx, task.ID)
+		require.NoError(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, "TaskService.CreateTask", spans[0].Name)
+		assert.Equal(t, "TaskService.GetTask", spans[1].Name)
+		assert.Contains(t, spans[1].Attributes, attribute.String("task.id", task.ID))
+		assert.Equal(t, codes.Unset, spans[1].Status.Code)
+	})
+
+	t.Run("failed calls are marked as errors", func(t *testing.T) {
+		exporter.Reset()
+
+		_, err := service.GetTask(ctx, "missing")
+		require.Error(t, err)
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 1)
+		assert.Equal(t, codes.Error, spans[0].Status.Code)
+		assert.Len(t, spans[0].Events, 1)
+	})
+
+	t.Run("spans join the caller's trace", func(t *testing.T) {
+		exporter.Reset()
+
+		parentCtx, parent := otel.Tracer("test").Start(ctx, "request")
+		_, err := service.ListTasks(parentCtx, domain.TaskFilter{})
+		require.NoError(t, err)
+		parent.End()
+
+		spans := exporter.GetSpans()
+		require.Len(t, spans, 2)
+		assert.Equal(t, parent.SpanContext().SpanID(), spans[0].Parent.SpanID())
+		assert.Equal(t, parent.SpanContext().TraceID(), spans[0].SpanContext.TraceID())
+	})
+}
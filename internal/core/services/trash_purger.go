@@ -0,0 +1,37 @@
+package services
+
+import (
+	"context"
+	"log/slog"
+	"task-tracking-service/internal/core/ports"
+	"time"
+)
+
+// TrashPurger permanently removes tasks that have been in the trash for
+// longer than the retention period
+type TrashPurger struct {
+	repo      ports.TaskRepository
+	retention time.Duration
+	logger    *slog.Logger
+	now       func() time.Time
+}
+
+func NewTrashPurger(repo ports.TaskRepository, retention time.Duration, logger *slog.Logger) *TrashPurger {
+	return &TrashPurger{
+		repo:      repo,
+		retention: retention,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// PurgeExpired removes every task, of any tenant, deleted more than the
+// retention period ago and returns how many it removed
+func (p *TrashPurger) PurgeExpired(ctx context.Context) (int64, error) {
+	return p.repo.PurgeDeletedBefore(ctx, p.now().Add(-p.retention))
+}
+
+// Run purges expired tasks every interval until ctx is cancelled
+func (p *TrashPurger) Run(ctx context.Context, interval time.Duration) {
+	runEvery(ctx, p.logger, interval, "purge trash", p.PurgeExpired)
+}
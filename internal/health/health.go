@@ -0,0 +1,133 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Status is the health of a component, or of the service as a whole
+type Status string
+
+const (
+	StatusUp   Status = "up"
+	StatusDown Status = "down"
+)
+
+// Checker reports whether a dependency is usable. A nil error means healthy.
+type Checker interface {
+	Check(ctx context.Context) error
+}
+
+// CheckerFunc adapts a function to the Checker interface
+type CheckerFunc func(ctx context.Context) error
+
+func (f CheckerFunc) Check(ctx context.Context) error {
+	return f(ctx)
+}
+
+// ComponentReport is the outcome of checking one component
+type ComponentReport struct {
+	Status    Status  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
+
+// Report is the outcome of checking every registered component. The
+// service is up only if every component is.
+type Report struct {
+	Status     Status                     `json:"status"`
+	Components map[string]ComponentReport `json:"components"`
+}
+
+// Registry holds the checkers that decide whether the service is ready.
+// Dependencies register a checker under their own name when they are set up.
+type Registry struct {
+	mutex    sync.RWMutex
+	checkers map[string]Checker
+	timeout  time.Duration
+}
+
+// NewRegistry creates an empty registry. Each check is abandoned and
+// reported as down after timeout.
+func NewRegistry(timeout time.Duration) *Registry {
+	return &Registry{
+		checkers: make(map[string]Checker),
+		timeout:  timeout,
+	}
+}
+
+// Register adds a checker, replacing any registered under the same name
+func (r *Registry) Register(name string, checker Checker) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	r.checkers[name] = checker
+}
+
+// Check runs every checker concurrently and collects the results
+func (r *Registry) Check(ctx context.Context) Report {
+	r.mutex.RLock()
+	checkers := make(map[string]Checker, len(r.checkers))
+	for name, checker := range r.checkers {
+		checkers[name] = checker
+	}
+	r.mutex.RUnlock()
+
+	report := Report{
+		Status:     StatusUp,
+		Components: make(map[string]ComponentReport, len(checkers)),
+	}
+
+	var (
+		wg    sync.WaitGroup
+		mutex sync.Mutex
+	)
+	for name, checker := range checkers {
+		wg.Add(1)
+		go func(name string, checker Checker) {
+			defer wg.Done()
+			component := r.check(ctx, checker)
+
+			mutex.Lock()
+			defer mutex.Unlock()
+			report.Components[name] = component
+			if component.Status != StatusUp {
+				report.Status = StatusDown
+			}
+		}(name, checker)
+	}
+	wg.Wait()
+
+	return report
+}
+
+// check runs one checker, giving up once the timeout has passed
+func (r *Registry) check(ctx context.Context, checker Checker) ComponentReport {
+	ctx, cancel := context.WithTimeout(ctx, r.timeout)
+	defer cancel()
+
+	start := time.Now()
+	result := make(chan error, 1)
+	go func() {
+		result <- checker.Check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = fmt.Errorf("check timed out after %s", r.timeout)
+	}
+
+	component := ComponentReport{
+		Status:    StatusUp,
+		LatencyMS: float64(time.Since(start).Microseconds()) / 1000,
+	}
+	if err != nil {
+		component.Status = StatusDown
+		component.Error = err.Error()
+	}
+	return component
+}
@@ -0,0 +1,71 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRegistry_Check(t *testing.T) {
+	healthy := CheckerFunc(func(ctx context.Context) error { return nil })
+	failing := CheckerFunc(func(ctx context.Context) error { return errors.New("connection refused") })
+	// hanging ignores cancellation, like a check stuck on a dead connection
+	hanging := CheckerFunc(func(ctx context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+
+	tests := []struct {
+		name       string
+		checkers   map[string]Checker
+		wantStatus Status
+		wantErrors map[string]string
+	}{
+		{
+			name:       "no checkers is up",
+			wantStatus: StatusUp,
+		},
+		{
+			name:       "all healthy is up",
+			checkers:   map[string]Checker{"database": healthy, "cache": healthy},
+			wantStatus: StatusUp,
+		},
+		{
+			name:       "one failure is down",
+			checkers:   map[string]Checker{"database": failing, "cache": healthy},
+			wantStatus: StatusDown,
+			wantErrors: map[string]string{"database": "connection refused"},
+		},
+		{
+			name:       "slow checks time out",
+			checkers:   map[string]Checker{"relay": hanging},
+			wantStatus: StatusDown,
+			wantErrors: map[string]string{"relay": "check timed out after 10ms"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			registry := NewRegistry(10 * time.Millisecond)
+			for name, checker := range tt.checkers {
+				registry.Register(name, checker)
+			}
+
+			report := registry.Check(context.Background())
+
+			assert.Equal(t, tt.wantStatus, report.Status)
+			assert.Len(t, report.Components, len(tt.checkers))
+			for name, component := range report.Components {
+				if message, failed := tt.wantErrors[name]; failed {
+					assert.Equal(t, StatusDown, component.Status)
+					assert.Equal(t, message, component.Error)
+				} else {
+					assert.Equal(t, StatusUp, component.Status)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,81 @@
+package logging
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNew(t *testing.T) {
+	tests := []struct {
+		name    string
+		cfg     config.LogConfig
+		want    string
+		wantErr bool
+	}{
+		{name: "text", cfg: config.LogConfig{Level: "info", Format: "text"}, want: "level=INFO msg=hello"},
+		{name: "json", cfg: config.LogConfig{Level: "info", Format: "json"}, want: `"msg":"hello"`},
+		{name: "unknown level", cfg: config.LogConfig{Level: "loud", Format: "text"}, wantErr: true},
+		{name: "unknown format", cfg: config.LogConfig{Level: "info", Format: "xml"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var buf bytes.Buffer
+			logger, err := New(tt.cfg, &buf)
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+
+			logger.Info("hello")
+
+			assert.Contains(t, buf.String(), tt.want)
+		})
+	}
+}
+
+func TestNewHonoursLevel(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(config.LogConfig{Level: "warn", Format: "text"}, &buf)
+	require.NoError(t, err)
+
+	logger.Info("dropped")
+	logger.Warn("kept")
+
+	assert.NotContains(t, buf.String(), "dropped")
+	assert.Contains(t, buf.String(), "kept")
+}
+
+func TestContextAttributes(t *testing.T) {
+	var buf bytes.Buffer
+	logger, err := New(config.LogConfig{Level: "info", Format: "json"}, &buf)
+	require.NoError(t, err)
+
+	traceID, _ := trace.TraceIDFromHex("4bf92f3577b34da6a3ce929d0e0e4736")
+	spanID, _ := trace.SpanIDFromHex("00f067aa0ba902b7")
+	ctx := trace.ContextWithSpanContext(context.Background(), trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID: traceID,
+		SpanID:  spanID,
+	}))
+	ctx = domain.ContextWithRequestID(ctx, "req-1")
+
+	logger.With("component", "test").InfoContext(ctx, "hello", "secret", config.SensitiveValue("hunter2"))
+
+	var record map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &record))
+	assert.Equal(t, "req-1", record["request_id"])
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", record["trace_id"])
+	assert.Equal(t, "00f067aa0ba902b7", record["span_id"])
+	assert.Equal(t, "test", record["component"])
+	assert.Equal(t, "[REDACTED]", record["secret"])
+}
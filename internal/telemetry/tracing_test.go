@@ -0,0 +1,61 @@
+package telemetry
+
+import (
+	"context"
+	"net/http"
+	"testing"
+
+	"task-tracking-service/internal/config"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func TestNewExporter(t *testing.T) {
+	tests := []struct {
+		name         string
+		exporter     string
+		wantExporter bool
+		wantErr      bool
+	}{
+		{name: "none drops spans", exporter: "none"},
+		{name: "stdout", exporter: "stdout", wantExporter: true},
+		{name: "otlp", exporter: "otlp", wantExporter: true},
+		{name: "unknown", exporter: "jaeger", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			exporter, err := newExporter(context.Background(), config.TracingConfig{
+				Exporter:     tt.exporter,
+				OTLPEndpoint: "http://localhost:4318",
+			})
+
+			if tt.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.wantExporter, exporter != nil)
+		})
+	}
+}
+
+func TestSetupTracingPropagatesTraceContext(t *testing.T) {
+	shutdown, err := SetupTracing(context.Background(), config.TracingConfig{Exporter: "none", ServiceName: "test"})
+	require.NoError(t, err)
+	defer shutdown(context.Background())
+
+	const traceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+	incoming := http.Header{"Traceparent": []string{traceparent}}
+	ctx := otel.GetTextMapPropagator().Extract(context.Background(), propagation.HeaderCarrier(incoming))
+
+	assert.Equal(t, "4bf92f3577b34da6a3ce929d0e0e4736", trace.SpanContextFromContext(ctx).TraceID().String())
+
+	outgoing := http.Header{}
+	otel.GetTextMapPropagator().Inject(ctx, propagation.HeaderCarrier(outgoing))
+	assert.Equal(t, traceparent, outgoing.Get("Traceparent"))
+}
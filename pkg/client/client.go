@@ -0,0 +1,278 @@
+// Package client is a typed Go client for the task tracking service API
+// described by api/openapi.yaml.
+//
+// Failed requests that are safe to repeat are retried with exponential
+// backoff, and every error response is returned as an *Error.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	headerAPIKey         = "X-API-Key"
+	headerTenantID       = "X-Tenant-ID"
+	headerIdempotencyKey = "Idempotency-Key"
+	headerIfMatch        = "If-Match"
+	headerNextPageToken  = "Next-Page-Token"
+	headerRetryAfter     = "Retry-After"
+
+	mimeJSON       = "application/json"
+	mimeMergePatch = "application/merge-patch+json"
+	mimeJSONPatch  = "application/json-patch+json"
+)
+
+// RetryPolicy decides how often, and how far apart, failed requests are
+// retried. Requests rejected with 429 are always retried. Server errors
+// and network failures are only retried for requests that are safe to
+// repeat: reads, full updates, deletes and task creation, which is made
+// idempotent with an Idempotency-Key.
+type RetryPolicy struct {
+	// MaxRetries is how many times a request is retried; zero disables retries
+	MaxRetries int
+	// MinBackoff is the delay before the first retry. It doubles with each
+	// retry, up to MaxBackoff, and is jittered.
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+}
+
+// DefaultRetryPolicy is used unless WithRetryPolicy is given
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries: 3,
+	MinBackoff: 100 * time.Millisecond,
+	MaxBackoff: 5 * time.Second,
+}
+
+// Client calls the task API. It is safe for concurrent use.
+type Client struct {
+	baseURL    *url.URL
+	httpClient *http.Client
+	header     http.Header
+	retry      RetryPolicy
+}
+
+// Option customises a Client
+type Option func(*Client)
+
+// WithAPIKey authenticates every request with an API key
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.header.Set(headerAPIKey, key)
+	}
+}
+
+// WithBearerToken authenticates every request with a bearer token, such
+// as a JWT from the identity provider
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.header.Set("Authorization", "Bearer "+token)
+	}
+}
+
+// WithTenant names the tenant to act within. The server rejects requests
+// naming a tenant other than the one the credentials are bound to.
+func WithTenant(tenantID string) Option {
+	return func(c *Client) {
+		c.header.Set(headerTenantID, tenantID)
+	}
+}
+
+// WithHTTPClient sends requests through httpClient instead of
+// http.DefaultClient
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithRetryPolicy replaces DefaultRetryPolicy
+func WithRetryPolicy(policy RetryPolicy) Option {
+	return func(c *Client) {
+		c.retry = policy
+	}
+}
+
+// New creates a client for the service at baseURL, such as
+// http://localhost:8080
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil {
+		return nil, fmt.Errorf("invalid base URL: %w", err)
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: scheme and host are required", baseURL)
+	}
+	u.Path = strings.TrimSuffix(u.Path, "/")
+
+	c := &Client{
+		baseURL:    u,
+		httpClient: http.DefaultClient,
+		header:     make(http.Header),
+		retry:      DefaultRetryPolicy,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+// request describes one API call
+type request struct {
+	method      string
+	path        string
+	query       url.Values
+	header      http.Header
+	body        any
+	contentType string
+	// idempotent marks requests that may be repeated after a server error
+	idempotent bool
+	// accept lists error statuses whose body is decoded like a success
+	accept []int
+}
+
+// do sends r, retrying as the policy allows, and decodes the response
+// body into out unless out is nil
+func (c *Client) do(ctx context.Context, r request, out any) (*http.Response, error) {
+	var body []byte
+	if r.body != nil {
+		var err error
+		if body, err = json.Marshal(r.body); err != nil {
+			return nil, fmt.Errorf("encoding request body: %w", err)
+		}
+	}
+
+	for attempt := 0; ; attempt++ {
+		resp, err := c.send(ctx, r, body)
+		canRetry := attempt < c.retry.MaxRetries
+		if err != nil {
+			if !canRetry || !r.idempotent || ctx.Err() != nil {
+				return nil, err
+			}
+			if err := sleep(ctx, c.backoff(attempt)); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if canRetry && (resp.StatusCode == http.StatusTooManyRequests ||
+			(resp.StatusCode >= http.StatusInternalServerError && r.idempotent && !r.accepts(resp.StatusCode))) {
+			delay := c.backoff(attempt)
+			if retryAfter, ok := parseRetryAfter(resp.Header.Get(headerRetryAfter)); ok {
+				delay = retryAfter
+			}
+			drain(resp)
+			if err := sleep(ctx, delay); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		defer resp.Body.Close()
+		if resp.StatusCode >= http.StatusBadRequest && !r.accepts(resp.StatusCode) {
+			return resp, decodeError(resp)
+		}
+		if out != nil && resp.StatusCode != http.StatusNoContent {
+			if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+				return resp, fmt.Errorf("decoding %s %s response: %w", r.method, r.path, err)
+			}
+		}
+		return resp, nil
+	}
+}
+
+func (c *Client) send(ctx context.Context, r request, body []byte) (*http.Response, error) {
+	u := *c.baseURL
+	u.Path += r.path
+	u.RawQuery = r.query.Encode()
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, r.method, u.String(), reader)
+	if err != nil {
+		return nil, err
+	}
+	for name, values := range c.header {
+		req.Header[name] = values
+	}
+	for name, values := range r.header {
+		req.Header[name] = values
+	}
+	req.Header.Set("Accept", mimeJSON)
+	if body != nil {
+		contentType := r.contentType
+		if contentType == "" {
+			contentType = mimeJSON
+		}
+		req.Header.Set("Content-Type", contentType)
+	}
+	return c.httpClient.Do(req)
+}
+
+func (r request) accepts(status int) bool {
+	for _, accepted := range r.accept {
+		if status == accepted {
+			return true
+		}
+	}
+	return false
+}
+
+// backoff returns the delay before retry number attempt+1: exponential,
+// capped, and jittered so that clients do not retry in lockstep
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.retry.MinBackoff << attempt
+	if delay <= 0 || (c.retry.MaxBackoff > 0 && delay > c.retry.MaxBackoff) {
+		delay = c.retry.MaxBackoff
+	}
+	if delay <= 0 {
+		return 0
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// parseRetryAfter reads a Retry-After header given in seconds
+func parseRetryAfter(value string) (time.Duration, bool) {
+	seconds, err := strconv.Atoi(value)
+	if err != nil || seconds < 0 {
+		return 0, false
+	}
+	return time.Duration(seconds) * time.Second, true
+}
+
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// drain reads the rest of a response that will not be used, so that its
+// connection can be reused
+func drain(resp *http.Response) {
+	_, _ = io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+}
+
+// ifMatch returns the If-Match header for version, or no header for zero
+func ifMatch(version int64) http.Header {
+	if version == 0 {
+		return nil
+	}
+	return http.Header{headerIfMatch: []string{strconv.Quote(strconv.FormatInt(version, 10))}}
+}
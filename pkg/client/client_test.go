@@ -0,0 +1,439 @@
+package client
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"task-tracking-service/api"
+	httpadapter "task-tracking-service/internal/adapters/http"
+	"task-tracking-service/internal/adapters/storage/memory"
+	"task-tracking-service/internal/auth"
+	"task-tracking-service/internal/config"
+	"task-tracking-service/internal/core/domain"
+	"task-tracking-service/internal/core/services"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/yaml.v3"
+)
+
+const testAPIKey = "client-test-key-at-least-32-characters"
+
+var testRetryPolicy = RetryPolicy{MaxRetries: 3, MinBackoff: time.Millisecond, MaxBackoff: 5 * time.Millisecond}
+
+var testLogger = slog.New(slog.NewTextHandler(io.Discard, nil))
+
+// testService runs the real router, with contract validation on, so that
+// the client is checked against api/openapi.yaml as well as the handlers
+type testService struct {
+	server *httptest.Server
+	repo   *memory.TaskRepository
+	// requests counts the requests that reached the router
+	requests atomic.Int64
+}
+
+// newTestService starts the service. intercept, if set, may answer a
+// request itself instead of the router, or after it.
+func newTestService(t *testing.T, intercept func(w http.ResponseWriter, r *http.Request, next http.Handler)) *testService {
+	t.Helper()
+	validator, err := httpadapter.NewContractValidator(api.Spec, testLogger)
+	require.NoError(t, err)
+
+	policy := services.NewPolicy([]domain.RoleBinding{{Subject: "apikey:primary", Role: domain.RoleAdmin}}, "")
+	repo := memory.NewTaskRepository()
+	taskService := services.NewAuthorizedTaskService(services.NewTaskService(repo, slog.Default()), policy, testLogger)
+	router := httpadapter.NewRouter(httpadapter.NewTaskHandler(taskService, slog.Default()),
+		httpadapter.WithLogger(testLogger),
+		httpadapter.WithContractValidation(validator),
+		httpadapter.WithAuthenticator(auth.NewAPIKeyAuthenticator([]config.APIKey{{Name: "primary", Key: testAPIKey}})),
+		httpadapter.WithAuthorizationHandler(httpadapter.NewAuthorizationHandler(policy)),
+		httpadapter.WithBatchHandler(httpadapter.NewBatchHandler(services.NewBatchService(taskService, repo, 10), testLogger)),
+		httpadapter.WithIdempotency(memory.NewIdempotencyStore(), time.Hour),
+	)
+
+	s := &testService{repo: repo}
+	var handler http.Handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.requests.Add(1)
+		router.ServeHTTP(w, r)
+	})
+	if intercept != nil {
+		next := handler
+		handler = http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			intercept(w, r, next)
+		})
+	}
+	s.server = httptest.NewServer(handler)
+	t.Cleanup(s.server.Close)
+	return s
+}
+
+func (s *testService) client(t *testing.T, opts ...Option) *Client {
+	t.Helper()
+	c, err := New(s.server.URL, append([]Option{WithAPIKey(testAPIKey), WithRetryPolicy(testRetryPolicy)}, opts...)...)
+	require.NoError(t, err)
+	return c
+}
+
+func newTask(title string) CreateTaskRequest {
+	return CreateTaskRequest{Title: title, Description: "From the client", DueDate: time.Now().Add(24 * time.Hour)}
+}
+
+func TestClient_TaskLifecycle(t *testing.T) {
+	s := newTestService(t, nil)
+	c := s.client(t)
+	ctx := context.Background()
+
+	created, err := c.CreateTask(ctx, newTask("Write docs"))
+	require.NoError(t, err)
+	assert.Equal(t, StatusPending, created.Status)
+	assert.Equal(t, int64(1), created.Version)
+
+	fetched, err := c.GetTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Equal(t, created.Title, fetched.Title)
+
+	updated, err := c.UpdateTask(ctx, created.ID, UpdateTaskRequest{
+		Title:   "Write the docs",
+		Status:  StatusInProgress,
+		DueDate: created.DueDate,
+	}, created.Version)
+	require.NoError(t, err)
+	assert.Equal(t, StatusInProgress, updated.Status)
+
+	_, err = c.UpdateTask(ctx, created.ID, UpdateTaskRequest{Title: "Stale", Status: StatusInProgress}, created.Version)
+	assert.True(t, IsPreconditionFailed(err), err)
+
+	patched, err := c.PatchTask(ctx, created.ID, map[string]any{"description": "Reference and guides"}, updated.Version)
+	require.NoError(t, err)
+	assert.Equal(t, "Reference and guides", patched.Description)
+
+	_, err = c.ApplyJSONPatch(ctx, created.ID, []PatchOperation{{Op: "test", Path: "/status", Value: "pending"}}, 0)
+	assert.True(t, IsConflict(err), err)
+
+	require.NoError(t, c.DeleteTask(ctx, created.ID, patched.Version))
+	_, err = c.GetTask(ctx, created.ID)
+	assert.True(t, IsNotFound(err), err)
+
+	deleted, err := c.ListDeletedTasks(ctx)
+	require.NoError(t, err)
+	require.Len(t, deleted, 1)
+	assert.NotNil(t, deleted[0].DeletedAt)
+	_, err = c.GetDeletedTask(ctx, created.ID)
+	require.NoError(t, err)
+
+	restored, err := c.RestoreTask(ctx, created.ID)
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+
+	require.NoError(t, c.DeleteTask(ctx, created.ID, 0))
+	require.NoError(t, c.PurgeTask(ctx, created.ID))
+	_, err = c.GetDeletedTask(ctx, created.ID)
+	assert.True(t, IsNotFound(err), err)
+}
+
+func TestClient_Archive(t *testing.T) {
+	s := newTestService(t, nil)
+	c := s.client(t)
+	ctx := context.Background()
+
+	task, err := c.CreateTask(ctx, newTask("Ship it"))
+	require.NoError(t, err)
+	_, err = c.PatchTask(ctx, task.ID, map[string]any{"status": "completed"}, 0)
+	require.NoError(t, err)
+	_, err = services.NewTaskArchiver(s.repo, 0, testLogger).ArchiveCompleted(ctx)
+	require.NoError(t, err)
+
+	archived, err := c.GetTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, archived.ArchivedAt)
+	assert.True(t, IsConflict(c.DeleteTask(ctx, task.ID, 0)))
+
+	unarchived, err := c.UnarchiveTask(ctx, task.ID)
+	require.NoError(t, err)
+	assert.Nil(t, unarchived.ArchivedAt)
+}
+
+func TestClient_ListTasks(t *testing.T) {
+	s := newTestService(t, nil)
+	c := s.client(t)
+	ctx := context.Background()
+
+	var created []string
+	for _, title := range []string{"One", "Two", "Three", "Four", "Five"} {
+		task, err := c.CreateTask(ctx, newTask(title))
+		require.NoError(t, err)
+		created = append([]string{task.ID}, created...)
+	}
+
+	t.Run("iterates over every page, newest first", func(t *testing.T) {
+		before := s.requests.Load()
+		var listed []string
+		for task, err := range c.ListTasks(ctx, ListTasksOptions{PageSize: 2}) {
+			require.NoError(t, err)
+			listed = append(listed, task.ID)
+		}
+
+		assert.Equal(t, created, listed)
+		assert.Equal(t, int64(3), s.requests.Load()-before)
+	})
+
+	t.Run("stops fetching when the caller stops", func(t *testing.T) {
+		before := s.requests.Load()
+		for range c.ListTasks(ctx, ListTasksOptions{PageSize: 2}) {
+			break
+		}
+
+		assert.Equal(t, int64(1), s.requests.Load()-before)
+	})
+
+	t.Run("filters are applied by the server", func(t *testing.T) {
+		before := s.requests.Load()
+		var listed []string
+		for task, err := range c.ListTasks(ctx, ListTasksOptions{Statuses: []TaskStatus{StatusPending}, DueAfter: time.Now(), PageSize: 1}) {
+			require.NoError(t, err)
+			listed = append(listed, task.ID)
+		}
+		assert.Equal(t, created, listed)
+		assert.Equal(t, int64(len(created)), s.requests.Load()-before)
+
+		before = s.requests.Load()
+		for range c.ListTasks(ctx, ListTasksOptions{Statuses: []TaskStatus{StatusCompleted}, PageSize: 1}) {
+			t.Fatal("no task is completed")
+		}
+		assert.Equal(t, int64(1), s.requests.Load()-before)
+	})
+
+	t.Run("pages can be fetched one at a time", func(t *testing.T) {
+		page, err := c.ListTasksPage(ctx, ListTasksOptions{PageSize: 4}, "")
+		require.NoError(t, err)
+		assert.Len(t, page.Tasks, 4)
+		require.NotEmpty(t, page.NextPageToken)
+
+		page, err = c.ListTasksPage(ctx, ListTasksOptions{PageSize: 4}, page.NextPageToken)
+		require.NoError(t, err)
+		assert.Len(t, page.Tasks, 1)
+		assert.Empty(t, page.NextPageToken)
+	})
+
+	t.Run("errors end the iteration", func(t *testing.T) {
+		var errs []error
+		for _, err := range s.client(t, WithAPIKey("wrong")).ListTasks(ctx, ListTasksOptions{}) {
+			errs = append(errs, err)
+		}
+
+		require.Len(t, errs, 1)
+		var apiErr *Error
+		require.ErrorAs(t, errs[0], &apiErr)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	})
+}
+
+func TestClient_BatchPermissionsAndHealth(t *testing.T) {
+	s := newTestService(t, nil)
+	c := s.client(t)
+	ctx := context.Background()
+
+	batch, err := c.ExecuteBatch(ctx, BatchRequest{
+		Mode: BatchBestEffort,
+		Operations: []BatchOperation{
+			{Op: BatchCreate, Task: &CreateTaskRequest{Title: "Batched", DueDate: time.Now().Add(time.Hour)}},
+			{Op: BatchDelete, ID: "00000000-0000-0000-0000-000000000000", Version: 1},
+		},
+	})
+	require.NoError(t, err)
+	require.Len(t, batch.Results, 2)
+	assert.Equal(t, http.StatusCreated, batch.Results[0].Status)
+	assert.Equal(t, "Batched", batch.Results[0].Task.Title)
+	assert.Equal(t, http.StatusNotFound, batch.Results[1].Status)
+	assert.Equal(t, http.StatusNotFound, batch.Results[1].Error.StatusCode)
+
+	permissions, err := c.GetPermissions(ctx, "platform")
+	require.NoError(t, err)
+	assert.Equal(t, "apikey:primary", permissions.Subject)
+	assert.Contains(t, permissions.Roles, "admin")
+
+	for _, probe := range []func(context.Context) (*HealthReport, error){c.Liveness, c.Readiness} {
+		report, err := probe(ctx)
+		require.NoError(t, err)
+		assert.Equal(t, "up", report.Status)
+	}
+}
+
+func TestClient_Errors(t *testing.T) {
+	s := newTestService(t, nil)
+	ctx := context.Background()
+
+	t.Run("validation errors list each field", func(t *testing.T) {
+		_, err := s.client(t).CreateTask(ctx, CreateTaskRequest{DueDate: time.Now().Add(time.Hour)})
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusBadRequest, apiErr.StatusCode)
+		require.NotEmpty(t, apiErr.Details)
+		assert.Equal(t, "title", apiErr.Details[0].Field)
+		assert.NotEmpty(t, apiErr.RequestID)
+	})
+
+	t.Run("missing credentials", func(t *testing.T) {
+		c, err := New(s.server.URL)
+		require.NoError(t, err)
+
+		_, err = c.GetTask(ctx, "00000000-0000-0000-0000-000000000000")
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusUnauthorized, apiErr.StatusCode)
+	})
+
+	t.Run("bodies outside the Error schema", func(t *testing.T) {
+		proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			http.Error(w, "upstream unavailable", http.StatusBadGateway)
+		}))
+		defer proxy.Close()
+		c, err := New(proxy.URL, WithRetryPolicy(RetryPolicy{}))
+		require.NoError(t, err)
+
+		_, err = c.GetTask(ctx, "x")
+
+		assert.EqualError(t, err, "task API: 502 Bad Gateway")
+	})
+
+	t.Run("invalid base URLs", func(t *testing.T) {
+		_, err := New("localhost:8080")
+		assert.Error(t, err)
+	})
+}
+
+func TestClient_Retries(t *testing.T) {
+	ctx := context.Background()
+
+	// failing answers the first failures requests with status, after
+	// letting the router handle them if forward is set
+	failing := func(failures int, status int, forward bool) func(http.ResponseWriter, *http.Request, http.Handler) {
+		var seen atomic.Int64
+		return func(w http.ResponseWriter, r *http.Request, next http.Handler) {
+			if seen.Add(1) > int64(failures) {
+				next.ServeHTTP(w, r)
+				return
+			}
+			if forward {
+				next.ServeHTTP(httptest.NewRecorder(), r)
+			}
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(status)
+		}
+	}
+
+	t.Run("server errors are retried with backoff", func(t *testing.T) {
+		s := newTestService(t, failing(2, http.StatusServiceUnavailable, false))
+
+		_, err := s.client(t).ListTasksPage(ctx, ListTasksOptions{}, "")
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("rate limited requests are retried", func(t *testing.T) {
+		s := newTestService(t, failing(2, http.StatusTooManyRequests, false))
+
+		_, err := s.client(t).ExecuteBatch(ctx, BatchRequest{Operations: []BatchOperation{{Op: BatchCreate, Task: &CreateTaskRequest{Title: "x", DueDate: time.Now().Add(time.Hour)}}}})
+
+		assert.NoError(t, err)
+	})
+
+	t.Run("retried creates are not duplicated", func(t *testing.T) {
+		s := newTestService(t, failing(1, http.StatusBadGateway, true))
+		c := s.client(t)
+
+		_, err := c.CreateTask(ctx, newTask("Once"))
+		require.NoError(t, err)
+
+		page, err := c.ListTasksPage(ctx, ListTasksOptions{}, "")
+		require.NoError(t, err)
+		assert.Len(t, page.Tasks, 1)
+		assert.Equal(t, int64(3), s.requests.Load())
+	})
+
+	t.Run("unsafe requests are not retried after server errors", func(t *testing.T) {
+		s := newTestService(t, failing(1, http.StatusInternalServerError, false))
+
+		_, err := s.client(t).RestoreTask(ctx, "00000000-0000-0000-0000-000000000000")
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	})
+
+	t.Run("retries give up after MaxRetries", func(t *testing.T) {
+		s := newTestService(t, failing(10, http.StatusServiceUnavailable, false))
+
+		_, err := s.client(t).GetTask(ctx, "00000000-0000-0000-0000-000000000000")
+
+		var apiErr *Error
+		require.ErrorAs(t, err, &apiErr)
+		assert.Equal(t, http.StatusServiceUnavailable, apiErr.StatusCode)
+	})
+}
+
+// TestClient_CoversSpec fails when an operation is added to
+// api/openapi.yaml without a client method
+func TestClient_CoversSpec(t *testing.T) {
+	methods := map[string]string{
+		"createTask":       "CreateTask",
+		"listTasks":        "ListTasks",
+		"getTask":          "GetTask",
+		"updateTask":       "UpdateTask",
+		"patchTask":        "PatchTask",
+		"deleteTask":       "DeleteTask",
+		"restoreTask":      "RestoreTask",
+		"unarchiveTask":    "UnarchiveTask",
+		"listDeletedTasks": "ListDeletedTasks",
+		"getDeletedTask":   "GetDeletedTask",
+		"purgeTask":        "PurgeTask",
+		"batchTasks":       "ExecuteBatch",
+		"getLiveness":      "Liveness",
+		"getReadiness":     "Readiness",
+		"getPermissions":   "GetPermissions",
+	}
+	// Operations for operators and browsers rather than API clients
+	skipped := map[string]bool{
+		"getMetrics":     true,
+		"getOpenAPIYAML": true,
+		"getOpenAPIJSON": true,
+		"getDocs":        true,
+		"getDocsAsset":   true,
+		// GraphQL callers bring a GraphQL client of their own
+		"getGraphQL":  true,
+		"postGraphQL": true,
+	}
+
+	var spec struct {
+		// Path items also hold parameters and servers, hence the nodes
+		Paths map[string]map[string]yaml.Node `yaml:"paths"`
+	}
+	require.NoError(t, yaml.Unmarshal(api.Spec, &spec))
+
+	clientType := reflect.TypeOf(&Client{})
+	for path, item := range spec.Paths {
+		for method, node := range item {
+			var operation struct {
+				OperationID string `yaml:"operationId"`
+			}
+			if node.Kind != yaml.MappingNode || node.Decode(&operation) != nil ||
+				operation.OperationID == "" || skipped[operation.OperationID] {
+				continue
+			}
+			name, ok := methods[operation.OperationID]
+			if assert.True(t, ok, "%s %s (%s) has no client method", method, path, operation.OperationID) {
+				_, ok := clientType.MethodByName(name)
+				assert.True(t, ok, "Client.%s is missing", name)
+			}
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package client
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// Error is returned for every response with a 4xx or 5xx status. It holds
+// the Error schema of the response body.
+type Error struct {
+	// StatusCode is the HTTP status of the response
+	StatusCode int          `json:"code"`
+	Message    string       `json:"message"`
+	Details    []FieldError `json:"details,omitempty"`
+	// RequestID identifies the request in the service's logs
+	RequestID string `json:"request_id,omitempty"`
+}
+
+// FieldError describes why a single input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e *Error) Error() string {
+	msg := fmt.Sprintf("task API: %d %s", e.StatusCode, e.Message)
+	for _, detail := range e.Details {
+		msg += fmt.Sprintf("; %s %s", detail.Field, detail.Message)
+	}
+	if e.RequestID != "" {
+		msg += fmt.Sprintf(" (request ID %s)", e.RequestID)
+	}
+	return msg
+}
+
+// IsNotFound reports whether err is, or wraps, a 404 Error
+func IsNotFound(err error) bool {
+	return hasStatus(err, http.StatusNotFound)
+}
+
+// IsConflict reports whether err is, or wraps, a 409 Error: the task
+// changed concurrently, or is archived
+func IsConflict(err error) bool {
+	return hasStatus(err, http.StatusConflict)
+}
+
+// IsPreconditionFailed reports whether err is, or wraps, a 412 Error: the
+// task no longer has the version the change was made against
+func IsPreconditionFailed(err error) bool {
+	return hasStatus(err, http.StatusPreconditionFailed)
+}
+
+func hasStatus(err error, status int) bool {
+	var apiErr *Error
+	return errors.As(err, &apiErr) && apiErr.StatusCode == status
+}
+
+// decodeError reads the Error body of resp. Bodies that are not in the
+// Error schema, such as those of proxies, are described by the status.
+func decodeError(resp *http.Response) error {
+	apiErr := &Error{}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil || json.Unmarshal(body, apiErr) != nil || apiErr.Message == "" {
+		apiErr = &Error{Message: http.StatusText(resp.StatusCode)}
+	}
+	apiErr.StatusCode = resp.StatusCode
+	return apiErr
+}
@@ -0,0 +1,54 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+)
+
+// GetPermissions reports the caller's effective roles and permissions,
+// within projectID if it is not empty
+func (c *Client) GetPermissions(ctx context.Context, projectID string) (*EffectivePermissions, error) {
+	var query url.Values
+	if projectID != "" {
+		query = url.Values{"project_id": []string{projectID}}
+	}
+
+	var permissions EffectivePermissions
+	_, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       "/api/v1/permissions",
+		query:      query,
+		idempotent: true,
+	}, &permissions)
+	if err != nil {
+		return nil, err
+	}
+	return &permissions, nil
+}
+
+// Liveness reports whether the service is serving requests
+func (c *Client) Liveness(ctx context.Context) (*HealthReport, error) {
+	return c.health(ctx, "/healthz")
+}
+
+// Readiness reports whether the service and its dependencies are up. A
+// service that is not ready is reported through the Status of the report,
+// not as an error.
+func (c *Client) Readiness(ctx context.Context) (*HealthReport, error) {
+	return c.health(ctx, "/readyz")
+}
+
+func (c *Client) health(ctx context.Context, path string) (*HealthReport, error) {
+	var report HealthReport
+	_, err := c.do(ctx, request{
+		method: http.MethodGet,
+		path:   path,
+		// A probe asks about now; retrying would only delay the answer
+		accept: []int{http.StatusServiceUnavailable},
+	}, &report)
+	if err != nil {
+		return nil, err
+	}
+	return &report, nil
+}
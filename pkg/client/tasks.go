@@ -0,0 +1,243 @@
+package client
+
+import (
+	"context"
+	"iter"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	taskPath = "/api/v1/task"
+
+	// defaultPageSize is the page size of listings that do not choose one
+	defaultPageSize = 100
+)
+
+// CreateTask creates a pending task. The request carries a fresh
+// Idempotency-Key, so retrying it never creates a duplicate.
+func (c *Client) CreateTask(ctx context.Context, req CreateTaskRequest) (*Task, error) {
+	var task Task
+	_, err := c.do(ctx, request{
+		method:     http.MethodPost,
+		path:       taskPath,
+		header:     http.Header{headerIdempotencyKey: []string{uuid.NewString()}},
+		body:       req,
+		idempotent: true,
+	}, &task)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// GetTask fetches a live or archived task
+func (c *Client) GetTask(ctx context.Context, id string) (*Task, error) {
+	return c.getTask(ctx, taskPath+"/"+url.PathEscape(id))
+}
+
+// ListTasks iterates over the tasks, newest first, fetching them a page at
+// a time. Iteration stops after the first error.
+func (c *Client) ListTasks(ctx context.Context, opts ListTasksOptions) iter.Seq2[*Task, error] {
+	return func(yield func(*Task, error) bool) {
+		pageToken := ""
+		for {
+			page, err := c.ListTasksPage(ctx, opts, pageToken)
+			if err != nil {
+				yield(nil, err)
+				return
+			}
+			for _, task := range page.Tasks {
+				if !yield(task, nil) {
+					return
+				}
+			}
+			if page.NextPageToken == "" {
+				return
+			}
+			pageToken = page.NextPageToken
+		}
+	}
+}
+
+// ListTasksPage fetches one page of tasks. Pass an empty pageToken for the
+// first page and the NextPageToken of each page for the one after it.
+func (c *Client) ListTasksPage(ctx context.Context, opts ListTasksOptions, pageToken string) (*TaskPage, error) {
+	pageSize := opts.PageSize
+	if pageSize <= 0 {
+		pageSize = defaultPageSize
+	}
+	query := url.Values{"limit": []string{strconv.Itoa(pageSize)}}
+	if opts.IncludeArchived {
+		query.Set("include_archived", "true")
+	}
+	for _, status := range opts.Statuses {
+		query.Add("status", string(status))
+	}
+	if opts.ProjectID != "" {
+		query.Set("project_id", opts.ProjectID)
+	}
+	if !opts.DueBefore.IsZero() {
+		query.Set("due_before", opts.DueBefore.Format(time.RFC3339Nano))
+	}
+	if !opts.DueAfter.IsZero() {
+		query.Set("due_after", opts.DueAfter.Format(time.RFC3339Nano))
+	}
+	if pageToken != "" {
+		query.Set("page_token", pageToken)
+	}
+
+	page := &TaskPage{}
+	resp, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       taskPath,
+		query:      query,
+		idempotent: true,
+	}, &page.Tasks)
+	if err != nil {
+		return nil, err
+	}
+	page.NextPageToken = resp.Header.Get(headerNextPageToken)
+	return page, nil
+}
+
+// UpdateTask replaces the editable fields of a task. A non-zero version
+// makes the update fail with a 412 if the task has changed since then.
+func (c *Client) UpdateTask(ctx context.Context, id string, req UpdateTaskRequest, version int64) (*Task, error) {
+	var task Task
+	_, err := c.do(ctx, request{
+		method:     http.MethodPut,
+		path:       taskPath + "/" + url.PathEscape(id),
+		header:     ifMatch(version),
+		body:       req,
+		idempotent: true,
+	}, &task)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// PatchTask changes only the fields named in patch, a JSON Merge Patch
+// (RFC 7396) such as {"status": "completed"}. A non-zero version makes the
+// patch fail with a 412 if the task has changed since then.
+func (c *Client) PatchTask(ctx context.Context, id string, patch map[string]any, version int64) (*Task, error) {
+	return c.patchTask(ctx, id, patch, mimeMergePatch, version)
+}
+
+// ApplyJSONPatch applies a JSON Patch (RFC 6902) to a task. A failed test
+// operation is reported as a 409. A non-zero version makes the patch fail
+// with a 412 if the task has changed since then.
+func (c *Client) ApplyJSONPatch(ctx context.Context, id string, ops []PatchOperation, version int64) (*Task, error) {
+	return c.patchTask(ctx, id, ops, mimeJSONPatch, version)
+}
+
+func (c *Client) patchTask(ctx context.Context, id string, patch any, contentType string, version int64) (*Task, error) {
+	var task Task
+	_, err := c.do(ctx, request{
+		method:      http.MethodPatch,
+		path:        taskPath + "/" + url.PathEscape(id),
+		header:      ifMatch(version),
+		body:        patch,
+		contentType: contentType,
+	}, &task)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// DeleteTask moves a task to the trash. A non-zero version makes the
+// delete fail with a 412 if the task has changed since then.
+func (c *Client) DeleteTask(ctx context.Context, id string, version int64) error {
+	_, err := c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       taskPath + "/" + url.PathEscape(id),
+		header:     ifMatch(version),
+		idempotent: true,
+	}, nil)
+	return err
+}
+
+// ListDeletedTasks lists the tasks in the trash, most recently deleted first
+func (c *Client) ListDeletedTasks(ctx context.Context) ([]*Task, error) {
+	var tasks []*Task
+	_, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       taskPath + "/trash",
+		idempotent: true,
+	}, &tasks)
+	return tasks, err
+}
+
+// GetDeletedTask fetches a task in the trash
+func (c *Client) GetDeletedTask(ctx context.Context, id string) (*Task, error) {
+	return c.getTask(ctx, taskPath+"/trash/"+url.PathEscape(id))
+}
+
+// RestoreTask takes a task out of the trash
+func (c *Client) RestoreTask(ctx context.Context, id string) (*Task, error) {
+	return c.postTask(ctx, taskPath+"/"+url.PathEscape(id)+"/restore")
+}
+
+// PurgeTask permanently removes a task from the trash
+func (c *Client) PurgeTask(ctx context.Context, id string) error {
+	_, err := c.do(ctx, request{
+		method:     http.MethodDelete,
+		path:       taskPath + "/trash/" + url.PathEscape(id),
+		idempotent: true,
+	}, nil)
+	return err
+}
+
+// UnarchiveTask moves an archived task back among the live ones
+func (c *Client) UnarchiveTask(ctx context.Context, id string) (*Task, error) {
+	return c.postTask(ctx, taskPath+"/"+url.PathEscape(id)+"/unarchive")
+}
+
+// ExecuteBatch runs several operations in one request. The outcome of each
+// is in its BatchItemResult; an error is only returned when the batch as
+// a whole was rejected.
+func (c *Client) ExecuteBatch(ctx context.Context, req BatchRequest) (*BatchResponse, error) {
+	var resp BatchResponse
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   taskPath + ":batch",
+		body:   req,
+	}, &resp)
+	if err != nil {
+		return nil, err
+	}
+	return &resp, nil
+}
+
+func (c *Client) getTask(ctx context.Context, path string) (*Task, error) {
+	var task Task
+	_, err := c.do(ctx, request{
+		method:     http.MethodGet,
+		path:       path,
+		idempotent: true,
+	}, &task)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
+
+// postTask sends a POST without a body that returns a task. It is not
+// retried after server errors, which may have left the change applied.
+func (c *Client) postTask(ctx context.Context, path string) (*Task, error) {
+	var task Task
+	_, err := c.do(ctx, request{
+		method: http.MethodPost,
+		path:   path,
+	}, &task)
+	if err != nil {
+		return nil, err
+	}
+	return &task, nil
+}
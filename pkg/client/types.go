@@ -0,0 +1,154 @@
+package client
+
+import "time"
+
+// TaskStatus is the progress of a task
+type TaskStatus string
+
+const (
+	StatusPending    TaskStatus = "pending"
+	StatusInProgress TaskStatus = "in_progress"
+	StatusCompleted  TaskStatus = "completed"
+)
+
+// Task mirrors the Task schema
+type Task struct {
+	ID          string     `json:"id"`
+	ProjectID   string     `json:"project_id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      TaskStatus `json:"status"`
+	CreatedAt   time.Time  `json:"created_at"`
+	UpdatedAt   time.Time  `json:"updated_at"`
+	DueDate     time.Time  `json:"due_date"`
+	// Version increases with every change. Pass it to UpdateTask, PatchTask
+	// or DeleteTask to only change the task if nobody else has.
+	Version int64 `json:"version"`
+	// DeletedAt is set while the task is in the trash
+	DeletedAt *time.Time `json:"deleted_at,omitempty"`
+	// ArchivedAt is set while the task is archived
+	ArchivedAt *time.Time `json:"archived_at,omitempty"`
+}
+
+// CreateTaskRequest mirrors the CreateTaskRequest schema. New tasks are
+// always pending.
+type CreateTaskRequest struct {
+	ProjectID   string    `json:"project_id,omitempty"`
+	Title       string    `json:"title"`
+	Description string    `json:"description,omitempty"`
+	DueDate     time.Time `json:"due_date"`
+}
+
+// UpdateTaskRequest mirrors the UpdateTaskRequest schema. It replaces
+// every editable field of the task.
+type UpdateTaskRequest struct {
+	ProjectID   string     `json:"project_id,omitempty"`
+	Title       string     `json:"title"`
+	Description string     `json:"description"`
+	Status      TaskStatus `json:"status"`
+	DueDate     time.Time  `json:"due_date"`
+}
+
+// PatchOperation is one operation of a JSON Patch (RFC 6902)
+type PatchOperation struct {
+	Op    string `json:"op"`
+	Path  string `json:"path"`
+	From  string `json:"from,omitempty"`
+	Value any    `json:"value,omitempty"`
+}
+
+// ListTasksOptions narrows a task listing
+type ListTasksOptions struct {
+	// IncludeArchived also lists archived tasks
+	IncludeArchived bool
+	// Statuses, when not empty, only lists tasks in one of these statuses
+	Statuses []TaskStatus
+	// ProjectID, when set, only lists tasks in this project
+	ProjectID string
+	// DueBefore and DueAfter, when not zero, only list tasks due strictly
+	// before or after them
+	DueBefore time.Time
+	DueAfter  time.Time
+	// PageSize is how many tasks are fetched per request; zero means 100
+	PageSize int
+}
+
+// TaskPage is one page of a task listing
+type TaskPage struct {
+	Tasks []*Task
+	// NextPageToken fetches the next page; it is empty on the last page
+	NextPageToken string
+}
+
+// BatchMode decides what happens to a batch when an operation fails
+type BatchMode string
+
+const (
+	// BatchAtomic applies every operation or none
+	BatchAtomic BatchMode = "atomic"
+	// BatchBestEffort applies each operation that succeeds
+	BatchBestEffort BatchMode = "best_effort"
+)
+
+// BatchAction is the kind of a batch operation
+type BatchAction string
+
+const (
+	BatchCreate BatchAction = "create"
+	BatchUpdate BatchAction = "update"
+	BatchDelete BatchAction = "delete"
+)
+
+// BatchRequest mirrors the BatchRequest schema
+type BatchRequest struct {
+	Mode       BatchMode        `json:"mode,omitempty"`
+	Operations []BatchOperation `json:"operations"`
+}
+
+// BatchOperation mirrors the BatchOperation schema. Creates need Task;
+// updates need ID and Patch, a JSON Merge Patch; deletes need ID.
+type BatchOperation struct {
+	Op      BatchAction        `json:"op"`
+	ID      string             `json:"id,omitempty"`
+	Version int64              `json:"version,omitempty"`
+	Task    *CreateTaskRequest `json:"task,omitempty"`
+	Patch   map[string]any     `json:"patch,omitempty"`
+}
+
+// BatchResponse mirrors the BatchResponse schema
+type BatchResponse struct {
+	Results []BatchItemResult `json:"results"`
+}
+
+// BatchItemResult is the outcome of one batch operation
+type BatchItemResult struct {
+	Index int    `json:"index"`
+	Op    string `json:"op"`
+	// Status is the status code the equivalent single request would have
+	// returned
+	Status int    `json:"status"`
+	Task   *Task  `json:"task,omitempty"`
+	Error  *Error `json:"error,omitempty"`
+}
+
+// EffectivePermissions mirrors the EffectivePermissions schema
+type EffectivePermissions struct {
+	Subject     string   `json:"subject"`
+	ProjectID   string   `json:"project_id,omitempty"`
+	Roles       []string `json:"roles"`
+	Permissions []string `json:"permissions"`
+}
+
+// HealthReport mirrors the HealthReport schema
+type HealthReport struct {
+	// Status is "up" or "down"
+	Status     string                     `json:"status"`
+	Components map[string]ComponentHealth `json:"components"`
+}
+
+// ComponentHealth mirrors the ComponentHealth schema
+type ComponentHealth struct {
+	Status    string  `json:"status"`
+	LatencyMS float64 `json:"latency_ms"`
+	Error     string  `json:"error,omitempty"`
+}
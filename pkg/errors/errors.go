@@ -1,5 +1,7 @@
 package errors
 
+import "errors"
+
 type NotFoundError struct {
 	message string
 }
@@ -12,10 +14,108 @@ func (e *NotFoundError) Error() string {
 	return e.message
 }
 
-// IsNotFoundError checks if an error is a NotFoundError
+// IsNotFoundError checks if an error is, or wraps, a NotFoundError
 func IsNotFoundError(err error) bool {
-	_, ok := err.(*NotFoundError)
-	return ok
+	var target *NotFoundError
+	return errors.As(err, &target)
 }
 
 var ErrTaskNotFound = NewNotFoundError("task not found")
+
+// FieldError describes why a single input field was rejected
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports input that breaks one or more rules. Fields holds
+// every violation found, not just the first.
+type ValidationError struct {
+	message string
+	Fields  []FieldError
+}
+
+func NewValidationError(message string, fields ...FieldError) *ValidationError {
+	return &ValidationError{message: message, Fields: fields}
+}
+
+func (e *ValidationError) Error() string {
+	return e.message
+}
+
+// IsValidationError checks if an error is, or wraps, a ValidationError
+func IsValidationError(err error) bool {
+	var target *ValidationError
+	return errors.As(err, &target)
+}
+
+// ConflictError reports a write that clashes with the current state
+type ConflictError struct {
+	message string
+}
+
+func NewConflictError(message string) *ConflictError {
+	return &ConflictError{message: message}
+}
+
+func (e *ConflictError) Error() string {
+	return e.message
+}
+
+// IsConflictError checks if an error is, or wraps, a ConflictError
+func IsConflictError(err error) bool {
+	var target *ConflictError
+	return errors.As(err, &target)
+}
+
+type ForbiddenError struct {
+	message string
+}
+
+func NewForbiddenError(message string) *ForbiddenError {
+	return &ForbiddenError{message: message}
+}
+
+func (e *ForbiddenError) Error() string {
+	return e.message
+}
+
+// IsForbiddenError checks if an error is, or wraps, a ForbiddenError
+func IsForbiddenError(err error) bool {
+	var target *ForbiddenError
+	return errors.As(err, &target)
+}
+
+// PreconditionFailedError reports that a condition supplied by the caller,
+// such as an expected version, no longer holds.
+type PreconditionFailedError struct {
+	message string
+}
+
+func NewPreconditionFailedError(message string) *PreconditionFailedError {
+	return &PreconditionFailedError{message: message}
+}
+
+func (e *PreconditionFailedError) Error() string {
+	return e.message
+}
+
+// IsPreconditionFailedError checks if an error is, or wraps, a PreconditionFailedError
+func IsPreconditionFailedError(err error) bool {
+	var target *PreconditionFailedError
+	return errors.As(err, &target)
+}
+
+var (
+	// ErrTaskVersionMismatch is returned when the caller expected a different
+	// version of the task than the one stored
+	ErrTaskVersionMismatch = NewPreconditionFailedError("task version does not match")
+
+	// ErrTaskVersionConflict is returned when a task changed between being
+	// read and being written
+	ErrTaskVersionConflict = NewConflictError("task was modified concurrently")
+
+	// ErrTaskArchived is returned when changing a task that must first be
+	// unarchived
+	ErrTaskArchived = NewConflictError("task is archived")
+)